@@ -0,0 +1,230 @@
+// Package batch reads the payment files customers upload to make many
+// transfers at once.
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Formats of payment files
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain.001"
+)
+
+// Formats are the supported payment file formats
+var Formats = []string{FormatCSV, FormatPain001}
+
+// MaxPayments is the most payments a file may hold
+const MaxPayments = 10000
+
+// ErrNoPayments is returned for a file without payments
+var ErrNoPayments = errors.New("file has no payments")
+
+// File is a parsed payment file. FromAccountID is the debtor account named
+// by a pain.001 file, CSV files leave the sender to the batch.
+type File struct {
+	FromAccountID string
+	Payments      []*Payment
+}
+
+// Payment is a payment of a file as written, it is validated when the batch
+// is created. Number is the position of the payment in the file from 1.
+type Payment struct {
+	Number      int
+	ToAccountID string
+	Amount      string
+	Currency    string
+	Description string
+	Reference   string
+}
+
+// DetectFormat returns the format of a file from its name, or from its first
+// bytes when the name does not tell
+func DetectFormat(fileName string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatPain001
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(head)), "<") {
+		return FormatPain001
+	}
+	return FormatCSV
+}
+
+// Parse reads a payment file in a format
+func Parse(format string, r io.Reader) (*File, error) {
+	var file *File
+	var err error
+	switch format {
+	case FormatCSV:
+		file, err = parseCSV(r)
+	case FormatPain001:
+		file, err = parsePain001(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Payments) == 0 {
+		return nil, ErrNoPayments
+	}
+	if len(file.Payments) > MaxPayments {
+		return nil, fmt.Errorf("file has %d payments, at most %d are allowed", len(file.Payments), MaxPayments)
+	}
+
+	return file, nil
+}
+
+// parseCSV reads a CSV file that starts with a header naming the columns
+// to_account_id, amount, currency and optionally description and reference
+func parseCSV(r io.Reader) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoPayments
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"to_account_id", "amount", "currency"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	// rows may leave out the optional columns at the end
+	reader.FieldsPerRecord = -1
+	value := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	file := &File{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		file.Payments = append(file.Payments, &Payment{
+			Number:      len(file.Payments) + 1,
+			ToAccountID: value(record, "to_account_id"),
+			Amount:      value(record, "amount"),
+			Currency:    value(record, "currency"),
+			Description: value(record, "description"),
+			Reference:   value(record, "reference"),
+		})
+	}
+
+	return file, nil
+}
+
+// painDocument is the part of an ISO 20022 CustomerCreditTransferInitiation
+// that makes up the payments. The namespace is not checked, so that every
+// pain.001 version is read.
+type painDocument struct {
+	XMLName xml.Name `xml:"Document"`
+	PmtInf  []struct {
+		DbtrAcct painAccount `xml:"DbtrAcct"`
+		Txs      []struct {
+			PmtID struct {
+				EndToEndID string `xml:"EndToEndId"`
+			} `xml:"PmtId"`
+			Amt struct {
+				InstdAmt struct {
+					Ccy   string `xml:"Ccy,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"InstdAmt"`
+			} `xml:"Amt"`
+			CdtrAcct painAccount `xml:"CdtrAcct"`
+			RmtInf   struct {
+				Ustrd []string `xml:"Ustrd"`
+			} `xml:"RmtInf"`
+		} `xml:"CdtTrfTxInf"`
+	} `xml:"CstmrCdtTrfInitn>PmtInf"`
+}
+
+// painAccount identifies an account by the id in Othr, the way statements
+// render the accounts of the bank
+type painAccount struct {
+	ID struct {
+		Othr struct {
+			ID string `xml:"Id"`
+		} `xml:"Othr"`
+	} `xml:"Id"`
+}
+
+// parsePain001 reads the credit transfers of a pain.001 file. All payment
+// information blocks must debit the same account.
+func parsePain001(r io.Reader) (*File, error) {
+	var document painDocument
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse pain.001: %w", err)
+	}
+
+	file := &File{}
+	for _, information := range document.PmtInf {
+		debtor := accountID(information.DbtrAcct.ID.Othr.ID)
+		if file.FromAccountID != "" && debtor != file.FromAccountID {
+			return nil, fmt.Errorf("payments debit different accounts %s and %s", file.FromAccountID, debtor)
+		}
+		file.FromAccountID = debtor
+
+		for _, tx := range information.Txs {
+			reference := strings.TrimSpace(tx.PmtID.EndToEndID)
+			if reference == "NOTPROVIDED" {
+				reference = ""
+			}
+
+			file.Payments = append(file.Payments, &Payment{
+				Number:      len(file.Payments) + 1,
+				ToAccountID: accountID(tx.CdtrAcct.ID.Othr.ID),
+				Amount:      strings.TrimSpace(tx.Amt.InstdAmt.Value),
+				Currency:    strings.TrimSpace(tx.Amt.InstdAmt.Ccy),
+				Description: strings.TrimSpace(strings.Join(tx.RmtInf.Ustrd, " ")),
+				Reference:   reference,
+			})
+		}
+	}
+
+	return file, nil
+}
+
+// compactUUID matches an account id written without its hyphens, as the
+// statements of the bank write them
+var compactUUID = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// accountID returns an account id of a file in its canonical form
+func accountID(id string) string {
+	id = strings.TrimSpace(id)
+	if !compactUUID.MatchString(id) {
+		return id
+	}
+	return strings.ToLower(id[0:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:])
+}
@@ -0,0 +1,118 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		path   string
+		from   string
+		want   []*Payment
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			path:   "testdata/payroll.csv",
+			want: []*Payment{
+				{
+					Number: 1, ToAccountID: "7c0e4a52-3b8d-4f0e-9a61-2d5c8b9e1f30", Amount: "2500.00",
+					Currency: "EUR", Description: "September salary", Reference: "PAY-0001",
+				},
+				{
+					Number: 2, ToAccountID: "0a9b8c7d-6e5f-4a3b-8c2d-1e0f9a8b7c6d", Amount: "1800.50",
+					Currency: "EUR", Description: "Salary, September",
+				},
+				{Number: 3, Amount: "abc", Currency: "EUR"},
+			},
+		},
+		{
+			name:   "pain.001",
+			format: FormatPain001,
+			path:   "testdata/payroll.xml",
+			from:   "5b1f0d1e-6a43-4c8e-9a57-1f2c3d4e5f60",
+			want: []*Payment{
+				{
+					Number: 1, ToAccountID: "7c0e4a52-3b8d-4f0e-9a61-2d5c8b9e1f30", Amount: "2500.00",
+					Currency: "EUR", Description: "September salary", Reference: "PAY-0001",
+				},
+				{
+					Number: 2, ToAccountID: "0a9b8c7d-6e5f-4a3b-8c2d-1e0f9a8b7c6d", Amount: "1800.50",
+					Currency: "EUR",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			file, err := Parse(tt.format, f)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if file.FromAccountID != tt.from {
+				t.Errorf("from account = %q, want %q", file.FromAccountID, tt.from)
+			}
+			if !reflect.DeepEqual(file.Payments, tt.want) {
+				for i, payment := range file.Payments {
+					t.Logf("payment %d = %+v", i+1, payment)
+				}
+				t.Errorf("Parse() returned other payments than expected")
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		body   string
+	}{
+		{"empty csv", FormatCSV, ""},
+		{"header only", FormatCSV, "to_account_id,amount,currency\n"},
+		{"missing column", FormatCSV, "to_account_id,amount\nabc,1\n"},
+		{"malformed xml", FormatPain001, "<Document><CstmrCdtTrfInitn>"},
+		{"unknown format", "mt101", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.format, strings.NewReader(tt.body)); err == nil {
+				t.Error("Parse() succeeded, want an error")
+			}
+		})
+	}
+
+	if _, err := Parse(FormatCSV, strings.NewReader("")); !errors.Is(err, ErrNoPayments) {
+		t.Errorf("Parse() of an empty file error = %v, want %v", err, ErrNoPayments)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		fileName string
+		head     string
+		want     string
+	}{
+		{"payroll.csv", "<", FormatCSV},
+		{"payroll.XML", "to_account_id", FormatPain001},
+		{"", "  <?xml version=\"1.0\"?>", FormatPain001},
+		{"upload", "to_account_id,amount", FormatCSV},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.fileName, []byte(tt.head)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %s, want %s", tt.fileName, tt.head, got, tt.want)
+		}
+	}
+}
@@ -23,21 +23,24 @@ import (
 
 // MongoLedgerEntry represents a single ledger entry in MongoDB
 type MongoLedgerEntry struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty"`
-	UUID          string             `bson:"uuid"`
-	AccountID     string             `bson:"account_id"`
-	TransactionID string             `bson:"transaction_id"`
-	EntryType     string             `bson:"entry_type"` // "debit" or "credit"
-	Amount        decimal.Decimal    `bson:"amount"`
-	Balance       decimal.Decimal    `bson:"balance"`
-	Currency      string             `bson:"currency"`
-	Description   string             `bson:"description,omitempty"`
-	CreatedAt     time.Time          `bson:"created_at"`
-	UpdatedAt     time.Time          `bson:"updated_at"`
-	DeletedAt     *time.Time         `bson:"deleted_at,omitempty"`
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	UUID           string             `bson:"uuid"`
+	AccountID      string             `bson:"account_id"`
+	TransactionID  string             `bson:"transaction_id"`
+	EntryType      string             `bson:"entry_type"` // "debit" or "credit"
+	Amount         decimal.Decimal    `bson:"amount"`
+	Balance        decimal.Decimal    `bson:"balance"`
+	RunningBalance decimal.Decimal    `bson:"running_balance"`
+	Currency       string             `bson:"currency"`
+	Description    string             `bson:"description,omitempty"`
+	ReversalOf     string             `bson:"reversal_of,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty"`
 }
 
-// NewMongoLedgerConsumer creates a handler for processing transaction events and storing them in MongoDB
+// NewMongoLedgerConsumer creates a handler for processing transaction events and storing them in MongoDB.
+// Postgres dbank_ledgers is the book of record, the MongoDB ledger is a read projection of it.
 func NewMongoLedgerConsumer(logger *slog.Logger, mongoClient *mongo.Client, dbName string) MessageHandler {
 	return func(ctx context.Context, delivery amqp.Delivery) error {
 		var event amqpx.TransactionEvent
@@ -53,43 +56,13 @@ func NewMongoLedgerConsumer(logger *slog.Logger, mongoClient *mongo.Client, dbNa
 			"currency", event.Currency,
 		)
 
-		// Parse amount from string to decimal.Decimal
-		amount, err := decimal.NewFromString(event.Amount)
+		entries, err := mongoLedgerEntries(&event, time.Now())
 		if err != nil {
-			return fmt.Errorf("invalid amount format: %w", err)
+			return err
 		}
 
 		// Get the ledgers collection
 		collection := mongoClient.Database(dbName).Collection("ledgers")
-		now := time.Now()
-
-		// Create debit entry for the sender account (decrease sender's balance)
-		debitEntry := MongoLedgerEntry{
-			UUID:          primitive.NewObjectID().Hex(),
-			AccountID:     event.FromAccountID,
-			TransactionID: event.TransactionID,
-			EntryType:     "debit",
-			Amount:        amount,
-			Balance:       amount.Neg(), // Negative amount (money going out)
-			Currency:      event.Currency,
-			Description:   event.Description,
-			CreatedAt:     now,
-			UpdatedAt:     now,
-		}
-
-		// Create credit entry for the receiver account (increase receiver's balance)
-		creditEntry := MongoLedgerEntry{
-			UUID:          primitive.NewObjectID().Hex(),
-			AccountID:     event.ToAccountID,
-			TransactionID: event.TransactionID,
-			EntryType:     "credit",
-			Amount:        amount,
-			Balance:       amount, // Positive amount (money coming in)
-			Currency:      event.Currency,
-			Description:   event.Description,
-			CreatedAt:     now,
-			UpdatedAt:     now,
-		}
 
 		// Start a session for transaction with retry logic
 		err = RetryMongoOperation(ctx, logger, "create_ledger_entries", 3, func() error {
@@ -105,14 +78,15 @@ func NewMongoLedgerConsumer(logger *slog.Logger, mongoClient *mongo.Client, dbNa
 			}
 
 			if err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
-				// Insert debit entry
-				if _, err = collection.InsertOne(sc, debitEntry); err != nil {
-					return fmt.Errorf("failed to insert debit entry: %w", err)
-				}
-
-				// Insert credit entry
-				if _, err = collection.InsertOne(sc, creditEntry); err != nil {
-					return fmt.Errorf("failed to insert credit entry: %w", err)
+				// Upsert by uuid so that redelivered events do not duplicate entries
+				for _, entry := range entries {
+					if _, err = collection.ReplaceOne(sc,
+						bson.M{"uuid": entry.UUID},
+						entry,
+						options.Replace().SetUpsert(true),
+					); err != nil {
+						return fmt.Errorf("failed to upsert %s entry: %w", entry.EntryType, err)
+					}
 				}
 
 				// Commit the transaction
@@ -149,6 +123,77 @@ func NewMongoLedgerConsumer(logger *slog.Logger, mongoClient *mongo.Client, dbNa
 	}
 }
 
+// mongoLedgerEntries projects the Postgres postings carried by the event into
+// ledger entries. Events published before postings were added to the payload
+// fall back to a debit and credit derived from the transfer itself.
+func mongoLedgerEntries(event *amqpx.TransactionEvent, now time.Time) ([]MongoLedgerEntry, error) {
+	if len(event.Postings) > 0 {
+		entries := make([]MongoLedgerEntry, 0, len(event.Postings))
+		for _, posting := range event.Postings {
+			amount, err := decimal.NewFromString(posting.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid posting amount format: %w", err)
+			}
+
+			balance, err := decimal.NewFromString(posting.Balance)
+			if err != nil {
+				return nil, fmt.Errorf("invalid posting balance format: %w", err)
+			}
+
+			entries = append(entries, MongoLedgerEntry{
+				UUID:           posting.ID,
+				AccountID:      posting.AccountID,
+				TransactionID:  event.TransactionID,
+				EntryType:      posting.EntryType,
+				Amount:         amount.Abs(),
+				Balance:        amount, // Signed amount, negative for debits
+				RunningBalance: balance,
+				Currency:       posting.Currency,
+				Description:    event.Description,
+				ReversalOf:     event.ReversalOf,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+		}
+		return entries, nil
+	}
+
+	// Parse amount from string to decimal.Decimal
+	amount, err := decimal.NewFromString(event.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount format: %w", err)
+	}
+
+	return []MongoLedgerEntry{
+		// Debit entry for the sender account (decrease sender's balance)
+		{
+			UUID:          primitive.NewObjectID().Hex(),
+			AccountID:     event.FromAccountID,
+			TransactionID: event.TransactionID,
+			EntryType:     "debit",
+			Amount:        amount,
+			Balance:       amount.Neg(), // Negative amount (money going out)
+			Currency:      event.Currency,
+			Description:   event.Description,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		// Credit entry for the receiver account (increase receiver's balance)
+		{
+			UUID:          primitive.NewObjectID().Hex(),
+			AccountID:     event.ToAccountID,
+			TransactionID: event.TransactionID,
+			EntryType:     "credit",
+			Amount:        amount,
+			Balance:       amount, // Positive amount (money coming in)
+			Currency:      event.Currency,
+			Description:   event.Description,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+	}, nil
+}
+
 // GetLedgerEntriesByAccount retrieves all ledger entries for a specific account
 func GetLedgerEntriesByAccount(
 	ctx context.Context,
@@ -256,6 +301,10 @@ func EnsureLedgerIndexes(ctx context.Context, mongoClient *mongo.Client, dbName
 
 	// Create indexes for commonly queried fields
 	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "uuid", Value: 1}},
+			Options: options.Index().SetName("idx_uuid").SetUnique(true),
+		},
 		{
 			Keys: bson.D{
 				{Key: "account_id", Value: 1},
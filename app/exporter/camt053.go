@@ -0,0 +1,296 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+	"github.com/amjadjibon/dbank/pkg/currency"
+)
+
+// Camt053Namespace is the ISO 20022 BankToCustomerStatement version we render
+const Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+const (
+	isoDateTime = "2006-01-02T15:04:05Z07:00"
+	isoDate     = "2006-01-02"
+)
+
+type camtDocument struct {
+	XMLName xml.Name          `xml:"Document"`
+	Xmlns   string            `xml:"xmlns,attr"`
+	Stmt    camtBkToCstmrStmt `xml:"BkToCstmrStmt"`
+}
+
+type camtBkToCstmrStmt struct {
+	GrpHdr camtGrpHdr `xml:"GrpHdr"`
+	Stmt   camtStmt   `xml:"Stmt"`
+}
+
+type camtGrpHdr struct {
+	MsgID    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	MsgPgntn struct {
+		PgNb      int  `xml:"PgNb"`
+		LastPgInd bool `xml:"LastPgInd"`
+	} `xml:"MsgPgntn"`
+}
+
+type camtStmt struct {
+	ID        string        `xml:"Id"`
+	CreDtTm   string        `xml:"CreDtTm"`
+	FrToDt    camtFrToDt    `xml:"FrToDt"`
+	Acct      camtAcct      `xml:"Acct"`
+	Bal       []camtBal     `xml:"Bal"`
+	TxsSummry camtTxsSummry `xml:"TxsSummry"`
+	Ntry      []camtNtry    `xml:"Ntry"`
+}
+
+type camtFrToDt struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type camtAcct struct {
+	ID  camtAcctID `xml:"Id"`
+	Ccy string     `xml:"Ccy"`
+	Nm  string     `xml:"Nm,omitempty"`
+}
+
+type camtAcctID struct {
+	Othr struct {
+		ID string `xml:"Id"`
+	} `xml:"Othr"`
+}
+
+type camtAmt struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtBal struct {
+	Tp struct {
+		CdOrPrtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amt       camtAmt `xml:"Amt"`
+	CdtDbtInd string  `xml:"CdtDbtInd"`
+	Dt        camtDt  `xml:"Dt"`
+}
+
+type camtDt struct {
+	Dt   string `xml:"Dt,omitempty"`
+	DtTm string `xml:"DtTm,omitempty"`
+}
+
+type camtNbAndSum struct {
+	NbOfNtries int    `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camtTxsSummry struct {
+	TtlNtries struct {
+		NbOfNtries    int    `xml:"NbOfNtries"`
+		Sum           string `xml:"Sum"`
+		TtlNetNtryAmt string `xml:"TtlNetNtryAmt"`
+		CdtDbtInd     string `xml:"CdtDbtInd"`
+	} `xml:"TtlNtries"`
+	TtlCdtNtries camtNbAndSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries camtNbAndSum `xml:"TtlDbtNtries"`
+}
+
+type camtNtry struct {
+	NtryRef     string       `xml:"NtryRef"`
+	Amt         camtAmt      `xml:"Amt"`
+	CdtDbtInd   string       `xml:"CdtDbtInd"`
+	Sts         string       `xml:"Sts"`
+	BookgDt     camtDt       `xml:"BookgDt"`
+	ValDt       camtDt       `xml:"ValDt"`
+	AcctSvcrRef string       `xml:"AcctSvcrRef"`
+	BkTxCd      camtBkTxCd   `xml:"BkTxCd"`
+	NtryDtls    camtNtryDtls `xml:"NtryDtls"`
+}
+
+type camtBkTxCd struct {
+	Prtry struct {
+		Cd   string `xml:"Cd"`
+		Issr string `xml:"Issr"`
+	} `xml:"Prtry"`
+}
+
+type camtNtryDtls struct {
+	TxDtls camtTxDtls `xml:"TxDtls"`
+}
+
+type camtTxDtls struct {
+	Refs struct {
+		AcctSvcrRef string `xml:"AcctSvcrRef"`
+		TxID        string `xml:"TxId"`
+	} `xml:"Refs"`
+	RltdPties  *camtRltdPties `xml:"RltdPties,omitempty"`
+	RmtInf     *camtRmtInf    `xml:"RmtInf,omitempty"`
+	AddtlTxInf string         `xml:"AddtlTxInf"`
+}
+
+type camtRltdPties struct {
+	DbtrAcct *camtAcctRef `xml:"DbtrAcct,omitempty"`
+	CdtrAcct *camtAcctRef `xml:"CdtrAcct,omitempty"`
+}
+
+type camtAcctRef struct {
+	ID camtAcctID `xml:"Id"`
+}
+
+type camtRmtInf struct {
+	Ustrd string `xml:"Ustrd"`
+}
+
+// WriteCamt053 renders a statement as an ISO 20022 camt.053 document.
+// createdAt is the creation time stamped on the message.
+func WriteCamt053(w io.Writer, statement *store.Statement, createdAt time.Time) error {
+	doc := camtDocument{Xmlns: Camt053Namespace}
+	doc.Stmt.GrpHdr = camtGrpHdr{
+		MsgID:   truncate("DBANK"+createdAt.UTC().Format("20060102150405")+statement.AccountNumber, 35),
+		CreDtTm: createdAt.UTC().Format(isoDateTime),
+	}
+	doc.Stmt.GrpHdr.MsgPgntn.PgNb = 1
+	doc.Stmt.GrpHdr.MsgPgntn.LastPgInd = true
+
+	stmt := &doc.Stmt.Stmt
+	stmt.ID = statementID(statement)
+	stmt.CreDtTm = createdAt.UTC().Format(isoDateTime)
+	stmt.FrToDt = camtFrToDt{
+		FrDtTm: statement.From.UTC().Format(isoDateTime),
+		ToDtTm: statement.To.UTC().Format(isoDateTime),
+	}
+	stmt.Acct = camtAcct{Ccy: statement.Currency, Nm: truncate(statement.AccountName, 70)}
+	stmt.Acct.ID.Othr.ID = statement.AccountNumber
+
+	stmt.Bal = []camtBal{
+		camtBalance("OPBD", statement.OpeningBalance, statement.Currency, statement.From),
+		camtBalance("CLBD", statement.ClosingBalance, statement.Currency, lastDay(statement.To)),
+	}
+
+	var credits, debits int
+	for _, entry := range statement.Entries {
+		if entry.Amount.IsNegative() {
+			debits++
+		} else {
+			credits++
+		}
+		stmt.Ntry = append(stmt.Ntry, camtEntry(entry))
+	}
+
+	net := statement.TotalCredits.Sub(statement.TotalDebits)
+	summary := &stmt.TxsSummry
+	summary.TtlNtries.NbOfNtries = len(statement.Entries)
+	currencyCode := statement.Currency
+	summary.TtlNtries.Sum = formatAmount(statement.TotalCredits.Add(statement.TotalDebits), currencyCode)
+	summary.TtlNtries.TtlNetNtryAmt = formatAmount(net.Abs(), currencyCode)
+	summary.TtlNtries.CdtDbtInd = creditDebitIndicator(net)
+	summary.TtlCdtNtries = camtNbAndSum{NbOfNtries: credits, Sum: formatAmount(statement.TotalCredits, currencyCode)}
+	summary.TtlDbtNtries = camtNbAndSum{NbOfNtries: debits, Sum: formatAmount(statement.TotalDebits, currencyCode)}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode camt.053 document: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func camtBalance(code string, amount decimal.Decimal, currencyCode string, date time.Time) camtBal {
+	var bal camtBal
+	bal.Tp.CdOrPrtry.Cd = code
+	bal.Amt = camtAmt{Ccy: currencyCode, Value: formatAmount(amount.Abs(), currencyCode)}
+	bal.CdtDbtInd = creditDebitIndicator(amount)
+	bal.Dt = camtDt{Dt: date.UTC().Format(isoDate)}
+	return bal
+}
+
+func camtEntry(entry *store.StatementEntry) camtNtry {
+	bookedAt := entry.CreatedAt.UTC().Format(isoDateTime)
+	ntry := camtNtry{
+		NtryRef:     compactUUID(entry.ID),
+		Amt:         camtAmt{Ccy: entry.Currency, Value: formatAmount(entry.Amount.Abs(), entry.Currency)},
+		CdtDbtInd:   creditDebitIndicator(entry.Amount),
+		Sts:         "BOOK",
+		BookgDt:     camtDt{DtTm: bookedAt},
+		ValDt:       camtDt{DtTm: bookedAt},
+		AcctSvcrRef: compactUUID(entry.TransactionID),
+	}
+	ntry.BkTxCd.Prtry.Cd = entry.TransactionType
+	ntry.BkTxCd.Prtry.Issr = "DBANK"
+
+	details := &ntry.NtryDtls.TxDtls
+	details.Refs.AcctSvcrRef = compactUUID(entry.TransactionID)
+	details.Refs.TxID = compactUUID(entry.TransactionID)
+	// the full transaction UUID, references above are limited to 35 characters
+	details.AddtlTxInf = entry.TransactionID
+
+	if entry.CounterpartyAccountID != "" {
+		counterparty := &camtAcctRef{}
+		counterparty.ID.Othr.ID = compactUUID(entry.CounterpartyAccountID)
+		if entry.Amount.IsNegative() {
+			details.RltdPties = &camtRltdPties{CdtrAcct: counterparty}
+		} else {
+			details.RltdPties = &camtRltdPties{DbtrAcct: counterparty}
+		}
+	}
+
+	if entry.Description != "" {
+		details.RmtInf = &camtRmtInf{Ustrd: truncate(entry.Description, 140)}
+	}
+
+	return ntry
+}
+
+// statementID identifies a statement by account and period
+func statementID(statement *store.Statement) string {
+	return truncate(fmt.Sprintf("%s-%s-%s",
+		statement.AccountNumber,
+		statement.From.UTC().Format("20060102"),
+		lastDay(statement.To).Format("20060102"),
+	), 35)
+}
+
+// lastDay returns the last instant of an exclusive period end
+func lastDay(to time.Time) time.Time {
+	return to.UTC().Add(-time.Nanosecond)
+}
+
+func creditDebitIndicator(amount decimal.Decimal) string {
+	if amount.IsNegative() {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// formatAmount renders an amount with the minor units of its currency
+func formatAmount(amount decimal.Decimal, currencyCode string) string {
+	return currency.Get(currencyCode).Format(amount)
+}
+
+// compactUUID strips the dashes so that a UUID fits Max35Text fields
+func compactUUID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
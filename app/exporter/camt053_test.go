@@ -0,0 +1,135 @@
+package exporter
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testStatement is a statement with a debit, a credit and a negative closing balance
+func testStatement() *store.Statement {
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	return &store.Statement{
+		AccountID:      "5b1f0d1e-6a43-4c8e-9a57-1f2c3d4e5f60",
+		AccountNumber:  "12345678",
+		AccountName:    "Acme Trading Ltd",
+		Currency:       "EUR",
+		From:           from,
+		To:             to,
+		OpeningBalance: decimal.RequireFromString("100"),
+		TotalDebits:    decimal.RequireFromString("150.5"),
+		TotalCredits:   decimal.RequireFromString("25.25"),
+		ClosingBalance: decimal.RequireFromString("-25.25"),
+		Entries: []*store.StatementEntry{
+			{
+				LedgerEntry: store.LedgerEntry{
+					ID:            "0f8e2a4c-1b3d-4e5f-8a9b-0c1d2e3f4a5b",
+					AccountID:     "5b1f0d1e-6a43-4c8e-9a57-1f2c3d4e5f60",
+					TransactionID: "9d7c6b5a-4e3f-4a2b-9c1d-0e9f8a7b6c5d",
+					EntryType:     store.EntryTypeDebit,
+					Amount:        decimal.RequireFromString("-150.5"),
+					Balance:       decimal.RequireFromString("-50.5"),
+					Currency:      "EUR",
+					Description:   "Invoice 2026-091 & fees",
+					CreatedAt:     time.Date(2026, 9, 3, 9, 30, 0, 0, time.UTC),
+				},
+				TransactionType:       "transfer",
+				CounterpartyAccountID: "7e6d5c4b-3a29-4180-b7f6-e5d4c3b2a190",
+			},
+			{
+				LedgerEntry: store.LedgerEntry{
+					ID:            "1a2b3c4d-5e6f-4a1b-8c2d-3e4f5a6b7c8d",
+					AccountID:     "5b1f0d1e-6a43-4c8e-9a57-1f2c3d4e5f60",
+					TransactionID: "2b3c4d5e-6f7a-4b1c-9d2e-4f5a6b7c8d9e",
+					EntryType:     store.EntryTypeCredit,
+					Amount:        decimal.RequireFromString("25.25"),
+					Balance:       decimal.RequireFromString("-25.25"),
+					Currency:      "EUR",
+					CreatedAt:     time.Date(2026, 9, 17, 14, 5, 12, 0, time.UTC),
+				},
+				TransactionType:       "transfer",
+				CounterpartyAccountID: "7e6d5c4b-3a29-4180-b7f6-e5d4c3b2a190",
+			},
+		},
+	}
+}
+
+func TestWriteCamt053(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	empty := testStatement()
+	empty.Entries = nil
+	empty.TotalDebits = decimal.Zero
+	empty.TotalCredits = decimal.Zero
+	empty.ClosingBalance = empty.OpeningBalance
+
+	tests := []struct {
+		name      string
+		statement *store.Statement
+		golden    string
+	}{
+		{name: "with entries", statement: testStatement(), golden: "camt053.xml"},
+		{name: "without entries", statement: empty, golden: "camt053_empty.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCamt053(&buf, tt.statement, createdAt); err != nil {
+				t.Fatalf("WriteCamt053() error = %v", err)
+			}
+
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount       string
+		currencyCode string
+		want         string
+	}{
+		{"150.5", "EUR", "150.50"},
+		{"1500", "JPY", "1500"},
+		{"1.5", "KWD", "1.500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currencyCode, func(t *testing.T) {
+			if got := formatAmount(decimal.RequireFromString(tt.amount), tt.currencyCode); got != tt.want {
+				t.Errorf("formatAmount(%s, %s) = %s, want %s", tt.amount, tt.currencyCode, got, tt.want)
+			}
+		})
+	}
+}
+
+// assertGolden compares got with testdata/name, rewriting the file with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
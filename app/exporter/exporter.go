@@ -0,0 +1,128 @@
+// Package exporter renders account statements in the file formats that
+// accounting tools and personal finance apps import.
+package exporter
+
+import (
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// Format is a statement export format
+type Format struct {
+	// Name is the name used by the CLI
+	Name string
+	// MediaTypes are the media types that select the format, the first one is its Content-Type
+	MediaTypes []string
+	// Extension is the file name extension of an exported statement
+	Extension string
+	// Write renders a statement, createdAt is the creation time of the document
+	Write func(w io.Writer, statement *store.Statement, createdAt time.Time) error
+}
+
+// ContentType returns the Content-Type of an exported statement
+func (f Format) ContentType() string {
+	return f.MediaTypes[0]
+}
+
+var (
+	Camt053 = Format{
+		Name:       "camt053",
+		MediaTypes: []string{"application/xml", "text/xml"},
+		Extension:  "camt053.xml",
+		Write:      WriteCamt053,
+	}
+	MT940 = Format{
+		Name:       "mt940",
+		MediaTypes: []string{"application/x-mt940"},
+		Extension:  "sta",
+		Write:      WriteMT940,
+	}
+	OFX = Format{
+		Name:       "ofx",
+		MediaTypes: []string{"application/x-ofx"},
+		Extension:  "ofx",
+		Write:      WriteOFX,
+	}
+)
+
+// Formats are the supported export formats
+var Formats = []Format{Camt053, MT940, OFX}
+
+// FormatByName returns the export format with the given name
+func FormatByName(name string) (Format, bool) {
+	for _, format := range Formats {
+		if format.Name == strings.ToLower(name) {
+			return format, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// Names returns the names of the supported export formats
+func Names() []string {
+	names := make([]string, 0, len(Formats))
+	for _, format := range Formats {
+		names = append(names, format.Name)
+	}
+
+	return names
+}
+
+// Negotiate picks the export format preferred by an Accept header.
+// It returns false when the client prefers JSON, accepts anything or asks
+// for nothing we export, so that the caller serves its default representation.
+func Negotiate(accept string) (Format, bool) {
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+		}
+	}
+
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, r := range ranges {
+		if r.mediaType == "application/json" || strings.HasSuffix(r.mediaType, "/*") {
+			return Format{}, false
+		}
+		for _, format := range Formats {
+			if slices.Contains(format.MediaTypes, r.mediaType) {
+				return format, true
+			}
+		}
+	}
+
+	return Format{}, false
+}
@@ -0,0 +1,32 @@
+package exporter
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{accept: "", ok: false},
+		{accept: "application/json", ok: false},
+		{accept: "*/*", ok: false},
+		{accept: "application/xml", want: "camt053", ok: true},
+		{accept: "text/xml; charset=utf-8", want: "camt053", ok: true},
+		{accept: "application/x-mt940", want: "mt940", ok: true},
+		{accept: "application/x-ofx, */*;q=0.1", want: "ofx", ok: true},
+		{accept: "application/json;q=0.5, application/x-ofx", want: "ofx", ok: true},
+		{accept: "application/x-ofx;q=0.5, application/json", ok: false},
+		{accept: "application/x-mt940;q=0, application/x-ofx;q=0.2", want: "ofx", ok: true},
+		{accept: "image/png", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			got, ok := Negotiate(tt.accept)
+			if ok != tt.ok || got.Name != tt.want {
+				t.Errorf("Negotiate(%q) = %q, %v, want %q, %v", tt.accept, got.Name, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+const (
+	// mt940LineLength is the maximum length of a line, tag included
+	mt940LineLength = 65
+	// mt940InfoLines is the maximum number of lines of a :86: field
+	mt940InfoLines = 6
+)
+
+// WriteMT940 renders a statement as a SWIFT MT940 customer statement message.
+// Every posting becomes a :61: statement line followed by a :86: field that
+// carries the dbank transaction UUID, the counterparty and the description.
+func WriteMT940(w io.Writer, statement *store.Statement, _ time.Time) error {
+	bw := bufio.NewWriter(w)
+
+	from := statement.From.UTC()
+	fields := []string{
+		":20:" + truncate("DBANK"+from.Format("060102"), 16),
+		":25:" + truncate(statement.AccountNumber, 35),
+		fmt.Sprintf(":28C:%s%03d/1", from.Format("06"), from.YearDay()),
+		":60F:" + mt940Balance(statement.OpeningBalance, statement.Currency, from),
+	}
+
+	for _, entry := range statement.Entries {
+		fields = append(fields, mt940StatementLine(entry))
+		fields = append(fields, mt940Info(entry)...)
+	}
+
+	fields = append(fields,
+		":62F:"+mt940Balance(statement.ClosingBalance, statement.Currency, lastDay(statement.To)),
+		"-",
+	)
+
+	for _, field := range fields {
+		if _, err := bw.WriteString(field + "\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// mt940Balance renders the D/C mark, date, currency and amount of a balance field
+func mt940Balance(amount decimal.Decimal, currencyCode string, date time.Time) string {
+	return mt940Mark(amount) + date.UTC().Format("060102") + currencyCode + mt940Amount(amount, currencyCode)
+}
+
+// mt940StatementLine renders the :61: field of a posting: value date, entry
+// date, D/C mark, amount, transaction type, customer and bank reference
+func mt940StatementLine(entry *store.StatementEntry) string {
+	bookedAt := entry.CreatedAt.UTC()
+	return fmt.Sprintf(":61:%s%s%s%sN%sNONREF//%s",
+		bookedAt.Format("060102"),
+		bookedAt.Format("0102"),
+		mt940Mark(entry.Amount),
+		mt940Amount(entry.Amount, entry.Currency),
+		mt940TypeCode(entry.TransactionType),
+		truncate(compactUUID(entry.TransactionID), 16),
+	)
+}
+
+// mt940Info renders the :86: field of a posting with structured subfields,
+// wrapped to the MT940 line length and limited to six lines
+func mt940Info(entry *store.StatementEntry) []string {
+	info := "/TRID/" + entry.TransactionID
+	if entry.CounterpartyAccountID != "" {
+		info += "/CPTY/" + entry.CounterpartyAccountID
+	}
+	if entry.Description != "" {
+		info += "/REMI/" + mt940Text(entry.Description)
+	}
+
+	return mt940Wrap(":86:", info, mt940InfoLines)
+}
+
+// mt940Wrap splits a field into lines of at most mt940LineLength characters.
+// Continuation lines never start with ':' or '-', which would read as a new
+// field or the end of the message. Text beyond maxLines is dropped.
+func mt940Wrap(tag, text string, maxLines int) []string {
+	var lines []string
+	line := tag
+	for len(text) > 0 && len(lines) < maxLines {
+		limit := min(mt940LineLength-len(line), len(text))
+		n := limit
+		for n > 1 && n < len(text) && mt940Separator(text[n]) {
+			n--
+		}
+		if n < len(text) && mt940Separator(text[n]) {
+			// a run of separators longer than a line, replace the one that would start the next line
+			n = limit
+			text = text[:n] + "." + text[n+1:]
+		}
+		lines = append(lines, line+text[:n])
+		text = text[n:]
+		line = ""
+	}
+
+	return lines
+}
+
+// mt940Separator reports whether a line starting with c would read as a new field or the end of the message
+func mt940Separator(c byte) bool {
+	return c == ':' || c == '-'
+}
+
+// mt940Text replaces the characters outside of the SWIFT x character set
+func mt940Text(s string) string {
+	const allowed = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/-?:().,'+ "
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(allowed, r) {
+			return r
+		}
+		return '.'
+	}, s)
+}
+
+func mt940Mark(amount decimal.Decimal) string {
+	if amount.IsNegative() {
+		return "D"
+	}
+	return "C"
+}
+
+// mt940Amount renders the absolute amount with a decimal comma. An amount of
+// a currency without minor units keeps the comma, as the field requires one.
+func mt940Amount(amount decimal.Decimal, currencyCode string) string {
+	formatted := formatAmount(amount.Abs(), currencyCode)
+	if !strings.Contains(formatted, ".") {
+		return formatted + ","
+	}
+	return strings.Replace(formatted, ".", ",", 1)
+}
+
+// mt940TypeCode maps a transaction type to a SWIFT transaction type identification code
+func mt940TypeCode(transactionType string) string {
+	switch transactionType {
+	case "transfer":
+		return "TRF"
+	default:
+		return "MSC"
+	}
+}
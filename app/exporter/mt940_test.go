@@ -0,0 +1,176 @@
+package exporter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+var (
+	mt940BalanceRe = regexp.MustCompile(`^([DC])(\d{6})([A-Z]{3})(\d+,\d{2})$`)
+	mt940LineRe    = regexp.MustCompile(`^(\d{6})(\d{4})([DC])(\d+,\d{2})N([A-Z]{3})NONREF//(\w{1,16})$`)
+)
+
+// readMT940 parses the MT940 fields written by WriteMT940 back into a statement
+func readMT940(t *testing.T, data []byte) *store.Statement {
+	t.Helper()
+
+	type field struct{ tag, value string }
+	var fields []field
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n") {
+		if len(line) > mt940LineLength {
+			t.Fatalf("line longer than %d characters: %q", mt940LineLength, line)
+		}
+		switch {
+		case line == "-":
+		case strings.HasPrefix(line, ":"):
+			tag, value, _ := strings.Cut(line[1:], ":")
+			fields = append(fields, field{tag: tag, value: value})
+		default:
+			fields[len(fields)-1].value += line
+		}
+	}
+
+	amount := func(mark, value string) decimal.Decimal {
+		d := decimal.RequireFromString(strings.Replace(value, ",", ".", 1))
+		if mark == "D" {
+			return d.Neg()
+		}
+		return d
+	}
+
+	statement := &store.Statement{}
+	var entry *store.StatementEntry
+	for _, f := range fields {
+		switch f.tag {
+		case "25":
+			statement.AccountNumber = f.value
+		case "60F", "62F":
+			m := mt940BalanceRe.FindStringSubmatch(f.value)
+			if m == nil {
+				t.Fatalf("invalid :%s: field %q", f.tag, f.value)
+			}
+			statement.Currency = m[3]
+			if f.tag == "60F" {
+				statement.OpeningBalance = amount(m[1], m[4])
+			} else {
+				statement.ClosingBalance = amount(m[1], m[4])
+			}
+		case "61":
+			m := mt940LineRe.FindStringSubmatch(f.value)
+			if m == nil {
+				t.Fatalf("invalid :61: field %q", f.value)
+			}
+			createdAt, err := time.Parse("060102", m[1])
+			if err != nil {
+				t.Fatalf("invalid value date %q", m[1])
+			}
+			entry = &store.StatementEntry{}
+			entry.Amount = amount(m[3], m[4])
+			entry.CreatedAt = createdAt
+			statement.Entries = append(statement.Entries, entry)
+		case "86":
+			rest := f.value
+			if remi := strings.Index(rest, "/REMI/"); remi >= 0 {
+				entry.Description = rest[remi+len("/REMI/"):]
+				rest = rest[:remi]
+			}
+			if cpty := strings.Index(rest, "/CPTY/"); cpty >= 0 {
+				entry.CounterpartyAccountID = rest[cpty+len("/CPTY/"):]
+				rest = rest[:cpty]
+			}
+			entry.TransactionID = strings.TrimPrefix(rest, "/TRID/")
+		}
+	}
+
+	return statement
+}
+
+func TestWriteMT940_RoundTrip(t *testing.T) {
+	want := testStatement()
+	want.Entries[1].Description = strings.Repeat("Payment for services rendered: consulting - ", 10)
+
+	var buf bytes.Buffer
+	if err := WriteMT940(&buf, want, time.Now()); err != nil {
+		t.Fatalf("WriteMT940() error = %v", err)
+	}
+
+	got := readMT940(t, buf.Bytes())
+
+	if got.AccountNumber != want.AccountNumber || got.Currency != want.Currency {
+		t.Errorf("account = %s %s, want %s %s", got.AccountNumber, got.Currency, want.AccountNumber, want.Currency)
+	}
+	if !got.OpeningBalance.Equal(want.OpeningBalance) {
+		t.Errorf("opening balance = %s, want %s", got.OpeningBalance, want.OpeningBalance)
+	}
+	if !got.ClosingBalance.Equal(want.ClosingBalance) {
+		t.Errorf("closing balance = %s, want %s", got.ClosingBalance, want.ClosingBalance)
+	}
+	if len(got.Entries) != len(want.Entries) {
+		t.Fatalf("got %d entries, want %d", len(got.Entries), len(want.Entries))
+	}
+
+	for i, w := range want.Entries {
+		g := got.Entries[i]
+		if !g.Amount.Equal(w.Amount) {
+			t.Errorf("entry %d amount = %s, want %s", i, g.Amount, w.Amount)
+		}
+		if !g.CreatedAt.Equal(w.CreatedAt.Truncate(24 * time.Hour)) {
+			t.Errorf("entry %d date = %s, want %s", i, g.CreatedAt, w.CreatedAt)
+		}
+		if g.TransactionID != w.TransactionID {
+			t.Errorf("entry %d transaction id = %s, want %s", i, g.TransactionID, w.TransactionID)
+		}
+		if g.CounterpartyAccountID != w.CounterpartyAccountID {
+			t.Errorf("entry %d counterparty = %s, want %s", i, g.CounterpartyAccountID, w.CounterpartyAccountID)
+		}
+		if !strings.HasPrefix(mt940Text(w.Description), g.Description) {
+			t.Errorf("entry %d description = %q is not a prefix of %q", i, g.Description, w.Description)
+		}
+	}
+
+	if got.Entries[0].Description != "Invoice 2026-091 . fees" {
+		t.Errorf("entry 0 description = %q", got.Entries[0].Description)
+	}
+}
+
+func Test_mt940Wrap(t *testing.T) {
+	lines := mt940Wrap(":86:", strings.Repeat("a", 60)+":"+strings.Repeat("-", 70)+strings.Repeat("b", 400), 6)
+
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want 6", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) > mt940LineLength {
+			t.Errorf("line %d has %d characters", i, len(line))
+		}
+		if i > 0 && (strings.HasPrefix(line, ":") || strings.HasPrefix(line, "-")) {
+			t.Errorf("line %d starts with a field separator: %q", i, line)
+		}
+	}
+}
+
+func Test_mt940Amount(t *testing.T) {
+	tests := []struct {
+		amount       string
+		currencyCode string
+		want         string
+	}{
+		{"-150.5", "EUR", "150,50"},
+		{"1500", "JPY", "1500,"},
+		{"1.5", "KWD", "1,500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currencyCode, func(t *testing.T) {
+			if got := mt940Amount(decimal.RequireFromString(tt.amount), tt.currencyCode); got != tt.want {
+				t.Errorf("mt940Amount(%s, %s) = %s, want %s", tt.amount, tt.currencyCode, got, tt.want)
+			}
+		})
+	}
+}
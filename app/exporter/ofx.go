@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// ofxHeader is the OFX 2.2 processing instruction that follows the XML declaration
+const ofxHeader = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankID identifies dbank in BANKACCTFROM
+const ofxBankID = "DBANK"
+
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	Signon  struct {
+		SONRS struct {
+			Status   ofxStatus `xml:"STATUS"`
+			DTServer string    `xml:"DTSERVER"`
+			Language string    `xml:"LANGUAGE"`
+		} `xml:"SONRS"`
+	} `xml:"SIGNONMSGSRSV1"`
+	Bank struct {
+		STMTTRNRS struct {
+			TRNUID string    `xml:"TRNUID"`
+			Status ofxStatus `xml:"STATUS"`
+			STMTRS ofxSTMTRS `xml:"STMTRS"`
+		} `xml:"STMTTRNRS"`
+	} `xml:"BANKMSGSRSV1"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSTMTRS struct {
+	CurDef       string `xml:"CURDEF"`
+	BankAcctFrom struct {
+		BankID   string `xml:"BANKID"`
+		AcctID   string `xml:"ACCTID"`
+		AcctType string `xml:"ACCTTYPE"`
+	} `xml:"BANKACCTFROM"`
+	BankTranList struct {
+		DTStart string       `xml:"DTSTART"`
+		DTEnd   string       `xml:"DTEND"`
+		STMTTRN []ofxSTMTTRN `xml:"STMTTRN"`
+	} `xml:"BANKTRANLIST"`
+	LedgerBal struct {
+		BalAmt string `xml:"BALAMT"`
+		DTAsOf string `xml:"DTASOF"`
+	} `xml:"LEDGERBAL"`
+}
+
+type ofxSTMTTRN struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	RefNum   string `xml:"REFNUM"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+// WriteOFX renders a statement as an OFX 2.2 bank statement response.
+// FITID is the posting UUID and REFNUM the compact dbank transaction UUID.
+func WriteOFX(w io.Writer, statement *store.Statement, createdAt time.Time) error {
+	var doc ofxDocument
+	doc.Signon.SONRS.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.Signon.SONRS.DTServer = ofxTime(createdAt)
+	doc.Signon.SONRS.Language = "ENG"
+
+	doc.Bank.STMTTRNRS.TRNUID = statementID(statement)
+	doc.Bank.STMTTRNRS.Status = ofxStatus{Code: 0, Severity: "INFO"}
+
+	rs := &doc.Bank.STMTTRNRS.STMTRS
+	rs.CurDef = statement.Currency
+	rs.BankAcctFrom.BankID = ofxBankID
+	rs.BankAcctFrom.AcctID = truncate(statement.AccountNumber, 22)
+	rs.BankAcctFrom.AcctType = "CHECKING"
+	rs.BankTranList.DTStart = ofxTime(statement.From)
+	rs.BankTranList.DTEnd = ofxTime(statement.To)
+	rs.LedgerBal.BalAmt = ofxAmount(statement.ClosingBalance, statement.Currency)
+	rs.LedgerBal.DTAsOf = ofxTime(statement.To)
+
+	for _, entry := range statement.Entries {
+		trn := ofxSTMTTRN{
+			TrnType:  "CREDIT",
+			DTPosted: ofxTime(entry.CreatedAt),
+			TrnAmt:   ofxAmount(entry.Amount, entry.Currency),
+			FITID:    entry.ID,
+			RefNum:   compactUUID(entry.TransactionID),
+			Name:     truncate(compactUUID(entry.CounterpartyAccountID), 32),
+			Memo:     truncate(entry.Description, 255),
+		}
+		if entry.Amount.IsNegative() {
+			trn.TrnType = "DEBIT"
+		}
+		rs.BankTranList.STMTTRN = append(rs.BankTranList.STMTTRN, trn)
+	}
+
+	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+"\n"+ofxHeader); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode OFX document: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ofxTime renders a datetime in the OFX format with an explicit GMT offset
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// ofxAmount renders a signed amount with the minor units of its currency,
+// debits are negative
+func ofxAmount(amount decimal.Decimal, currencyCode string) string {
+	return formatAmount(amount, currencyCode)
+}
@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWriteOFX_RoundTrip(t *testing.T) {
+	want := testStatement()
+
+	var buf bytes.Buffer
+	if err := WriteOFX(&buf, want, time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteOFX() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `<?OFX OFXHEADER="200" VERSION="220"`) {
+		t.Fatalf("missing OFX 2 header:\n%s", buf.String())
+	}
+
+	var doc ofxDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to parse OFX: %v", err)
+	}
+
+	parseTime := func(s string) time.Time {
+		v, err := time.Parse("20060102150405.000", strings.TrimSuffix(s, "[0:GMT]"))
+		if err != nil {
+			t.Fatalf("invalid OFX datetime %q", s)
+		}
+		return v
+	}
+
+	rs := doc.Bank.STMTTRNRS.STMTRS
+	if rs.CurDef != want.Currency || rs.BankAcctFrom.AcctID != want.AccountNumber {
+		t.Errorf("account = %s %s, want %s %s", rs.BankAcctFrom.AcctID, rs.CurDef, want.AccountNumber, want.Currency)
+	}
+	if from := parseTime(rs.BankTranList.DTStart); !from.Equal(want.From) {
+		t.Errorf("DTSTART = %s, want %s", from, want.From)
+	}
+	if to := parseTime(rs.BankTranList.DTEnd); !to.Equal(want.To) {
+		t.Errorf("DTEND = %s, want %s", to, want.To)
+	}
+	if balance := decimal.RequireFromString(rs.LedgerBal.BalAmt); !balance.Equal(want.ClosingBalance) {
+		t.Errorf("ledger balance = %s, want %s", balance, want.ClosingBalance)
+	}
+	if len(rs.BankTranList.STMTTRN) != len(want.Entries) {
+		t.Fatalf("got %d transactions, want %d", len(rs.BankTranList.STMTTRN), len(want.Entries))
+	}
+
+	for i, w := range want.Entries {
+		g := rs.BankTranList.STMTTRN[i]
+		if amount := decimal.RequireFromString(g.TrnAmt); !amount.Equal(w.Amount) {
+			t.Errorf("transaction %d amount = %s, want %s", i, amount, w.Amount)
+		}
+		if posted := parseTime(g.DTPosted); !posted.Equal(w.CreatedAt) {
+			t.Errorf("transaction %d posted = %s, want %s", i, posted, w.CreatedAt)
+		}
+		if g.FITID != w.ID {
+			t.Errorf("transaction %d FITID = %s, want %s", i, g.FITID, w.ID)
+		}
+		if g.RefNum != compactUUID(w.TransactionID) {
+			t.Errorf("transaction %d REFNUM = %s, want %s", i, g.RefNum, w.TransactionID)
+		}
+		if g.Memo != w.Description {
+			t.Errorf("transaction %d memo = %q, want %q", i, g.Memo, w.Description)
+		}
+		if wantType := map[bool]string{true: "DEBIT", false: "CREDIT"}[w.Amount.IsNegative()]; g.TrnType != wantType {
+			t.Errorf("transaction %d type = %s, want %s", i, g.TrnType, wantType)
+		}
+	}
+}
+
+func Test_ofxAmount(t *testing.T) {
+	if got := ofxAmount(decimal.RequireFromString("-1500"), "JPY"); got != "-1500" {
+		t.Errorf("ofxAmount(-1500, JPY) = %s, want -1500", got)
+	}
+	if got := ofxAmount(decimal.RequireFromString("2.5"), "KWD"); got != "2.500" {
+		t.Errorf("ofxAmount(2.5, KWD) = %s, want 2.500", got)
+	}
+}
@@ -0,0 +1,92 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// CompositeProvider merges the rates of several providers in priority order.
+// A provider that fails is skipped and the next one is used instead. For each
+// currency pair the rate of the first provider wins, unless it is stale and a
+// later provider has a fresh one. Rates whose EffectiveAt is older than maxAge
+// are flagged as stale.
+type CompositeProvider struct {
+	logger    *slog.Logger
+	providers []FXRateProvider
+	maxAge    time.Duration
+	now       func() time.Time
+}
+
+// NewCompositeProvider creates a provider that falls back between providers,
+// a zero maxAge never flags rates as stale
+func NewCompositeProvider(
+	logger *slog.Logger,
+	maxAge time.Duration,
+	providers ...FXRateProvider,
+) *CompositeProvider {
+	return &CompositeProvider{
+		logger:    logger,
+		providers: providers,
+		maxAge:    maxAge,
+		now:       time.Now,
+	}
+}
+
+// Ensure CompositeProvider implements the FXRateProvider interface
+var _ FXRateProvider = (*CompositeProvider)(nil)
+
+// Name returns "composite"
+func (p *CompositeProvider) Name() string {
+	return "composite"
+}
+
+// Rates returns the merged rates, it only fails when every provider fails
+func (p *CompositeProvider) Rates(ctx context.Context) ([]*store.FXRate, error) {
+	var (
+		rates []*store.FXRate
+		pairs = make(map[string]int)
+		errs  []error
+	)
+
+	now := p.now()
+	for _, provider := range p.providers {
+		providerRates, err := provider.Rates(ctx)
+		if err != nil {
+			p.logger.WarnContext(ctx, "fx rate provider failed, falling back",
+				"provider", provider.Name(),
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		for _, rate := range providerRates {
+			rate := *rate
+			if rate.Source == "" {
+				rate.Source = provider.Name()
+			}
+			rate.Stale = p.maxAge > 0 && now.Sub(rate.EffectiveAt) > p.maxAge
+
+			pair := rate.BaseCurrency + "/" + rate.QuoteCurrency
+			i, ok := pairs[pair]
+			switch {
+			case !ok:
+				pairs[pair] = len(rates)
+				rates = append(rates, &rate)
+			case rates[i].Stale && !rate.Stale:
+				rates[i] = &rate
+			}
+		}
+	}
+
+	if len(errs) == len(p.providers) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,98 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// staticProvider serves fixed rates or fails with err
+type staticProvider struct {
+	name  string
+	rates []*store.FXRate
+	err   error
+}
+
+func (p *staticProvider) Name() string {
+	return p.name
+}
+
+func (p *staticProvider) Rates(context.Context) ([]*store.FXRate, error) {
+	return p.rates, p.err
+}
+
+func testRate(pair string, rate string, effectiveAt time.Time) *store.FXRate {
+	return &store.FXRate{
+		BaseCurrency:  pair[:3],
+		QuoteCurrency: pair[4:],
+		Rate:          decimal.RequireFromString(rate),
+		EffectiveAt:   effectiveAt,
+	}
+}
+
+func TestCompositeProvider_Rates(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Hour)
+	old := now.Add(-48 * time.Hour)
+
+	primary := &staticProvider{name: "admin", rates: []*store.FXRate{
+		testRate("EUR/USD", "1.10", fresh),
+		testRate("GBP/USD", "1.30", old),
+		testRate("USD/CHF", "0.90", old),
+	}}
+	fallback := &staticProvider{name: "file", rates: []*store.FXRate{
+		testRate("EUR/USD", "1.05", fresh),
+		testRate("GBP/USD", "1.29", fresh),
+		testRate("USD/CHF", "0.91", old),
+		testRate("USD/JPY", "150", fresh),
+	}}
+	failing := &staticProvider{name: "feed", err: errors.New("unreachable")}
+
+	provider := NewCompositeProvider(slog.New(slog.NewTextHandler(io.Discard, nil)), 24*time.Hour,
+		failing, primary, fallback)
+	provider.now = func() time.Time { return now }
+
+	rates, err := provider.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+
+	want := []struct {
+		pair   string
+		rate   string
+		source string
+		stale  bool
+	}{
+		{"EUR/USD", "1.10", "admin", false},
+		{"GBP/USD", "1.29", "file", false},
+		{"USD/CHF", "0.90", "admin", true},
+		{"USD/JPY", "150", "file", false},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("Rates() returned %d rates, want %d", len(rates), len(want))
+	}
+	for i, w := range want {
+		got := rates[i]
+		if pair := got.BaseCurrency + "/" + got.QuoteCurrency; pair != w.pair ||
+			!got.Rate.Equal(decimal.RequireFromString(w.rate)) || got.Source != w.source || got.Stale != w.stale {
+			t.Errorf("rate %d = %s %s from %s stale %t, want %s %s from %s stale %t", i,
+				pair, got.Rate, got.Source, got.Stale, w.pair, w.rate, w.source, w.stale)
+		}
+	}
+
+	if primary.rates[2].Stale {
+		t.Error("Rates() flagged the rate of the underlying provider")
+	}
+
+	provider = NewCompositeProvider(slog.New(slog.NewTextHandler(io.Discard, nil)), 0, failing, failing)
+	if _, err = provider.Rates(context.Background()); err == nil {
+		t.Error("Rates() with every provider failing error = nil, want an error")
+	}
+}
@@ -0,0 +1,181 @@
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// FileProvider loads rates from a local CSV or JSON file, for environments
+// without access to a market data feed. The file is read on every refresh,
+// so edits are picked up without a restart.
+//
+// CSV files start with a header naming the columns base_currency,
+// quote_currency, rate and optionally spread and effective_at. JSON files hold
+// an array of objects with the same keys. effective_at is an RFC 3339
+// timestamp and defaults to the modification time of the file.
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider creates a provider of the rates in a .csv or .json file
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Ensure FileProvider implements the FXRateProvider interface
+var _ FXRateProvider = (*FileProvider)(nil)
+
+// Name returns "file"
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// fileRate is a rate as written in a rates file
+type fileRate struct {
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	Spread        decimal.Decimal `json:"spread"`
+	EffectiveAt   *time.Time      `json:"effective_at"`
+}
+
+// Rates reads the rates file
+func (p *FileProvider) Rates(_ context.Context) ([]*store.FXRate, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat rates file: %w", err)
+	}
+
+	var rates []fileRate
+	switch ext := strings.ToLower(filepath.Ext(p.path)); ext {
+	case ".csv":
+		rates, err = readCSVRates(file)
+	case ".json":
+		err = json.NewDecoder(file).Decode(&rates)
+	default:
+		return nil, fmt.Errorf("unsupported rates file extension %q, want .csv or .json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file %s: %w", p.path, err)
+	}
+
+	result := make([]*store.FXRate, 0, len(rates))
+	for i, rate := range rates {
+		if err = rate.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rate %d in %s: %w", i+1, p.path, err)
+		}
+
+		effectiveAt := info.ModTime()
+		if rate.EffectiveAt != nil {
+			effectiveAt = *rate.EffectiveAt
+		}
+
+		result = append(result, &store.FXRate{
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			Rate:          rate.Rate,
+			Spread:        rate.Spread,
+			EffectiveAt:   effectiveAt,
+			Source:        p.Name(),
+		})
+	}
+
+	return result, nil
+}
+
+func (r *fileRate) validate() error {
+	switch {
+	case r.BaseCurrency == "" || r.QuoteCurrency == "":
+		return errors.New("base_currency and quote_currency are required")
+	case r.BaseCurrency == r.QuoteCurrency:
+		return errors.New("base_currency and quote_currency must differ")
+	case !r.Rate.IsPositive():
+		return errors.New("rate must be positive")
+	case r.Spread.IsNegative() || r.Spread.GreaterThanOrEqual(decimal.NewFromInt(1)):
+		return errors.New("spread must be at least 0 and below 1")
+	}
+	return nil
+}
+
+// readCSVRates reads the rates of a CSV file, the columns are found by the header
+func readCSVRates(r io.Reader) ([]fileRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"base_currency", "quote_currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rates []fileRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rate := fileRate{
+			BaseCurrency:  value(record, "base_currency"),
+			QuoteCurrency: value(record, "quote_currency"),
+		}
+
+		if rate.Rate, err = decimal.NewFromString(value(record, "rate")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate: %w", line, err)
+		}
+
+		if spread := value(record, "spread"); spread != "" {
+			if rate.Spread, err = decimal.NewFromString(spread); err != nil {
+				return nil, fmt.Errorf("line %d: invalid spread: %w", line, err)
+			}
+		}
+
+		if effectiveAt := value(record, "effective_at"); effectiveAt != "" {
+			parsed, err := time.Parse(time.RFC3339, effectiveAt)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid effective_at: %w", line, err)
+			}
+			rate.EffectiveAt = &parsed
+		}
+
+		rates = append(rates, rate)
+	}
+}
@@ -0,0 +1,88 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFileProvider_Rates(t *testing.T) {
+	for _, path := range []string{"testdata/rates.csv", "testdata/rates.json"} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rates, err := NewFileProvider(path).Rates(context.Background())
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if len(rates) != 3 {
+				t.Fatalf("Rates() returned %d rates, want 3", len(rates))
+			}
+
+			effectiveAt := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+			want := []struct {
+				pair        string
+				rate        string
+				spread      string
+				effectiveAt time.Time
+			}{
+				{"EUR/USD", "1.0842", "0.002", effectiveAt},
+				{"GBP/USD", "1.295", "0", effectiveAt},
+				{"USD/JPY", "151.37", "0.005", info.ModTime()},
+			}
+			for i, w := range want {
+				got := rates[i]
+				if pair := got.BaseCurrency + "/" + got.QuoteCurrency; pair != w.pair {
+					t.Errorf("rate %d pair = %s, want %s", i, pair, w.pair)
+				}
+				if !got.Rate.Equal(decimal.RequireFromString(w.rate)) || !got.Spread.Equal(decimal.RequireFromString(w.spread)) {
+					t.Errorf("rate %d = %s spread %s, want %s spread %s", i, got.Rate, got.Spread, w.rate, w.spread)
+				}
+				if !got.EffectiveAt.Equal(w.effectiveAt) {
+					t.Errorf("rate %d effective at %s, want %s", i, got.EffectiveAt, w.effectiveAt)
+				}
+				if got.Source != "file" {
+					t.Errorf("rate %d source = %s, want file", i, got.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestFileProvider_Rates_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "missing column", file: "rates.csv", content: "base_currency,rate\nEUR,1.1\n"},
+		{name: "bad rate", file: "rates.csv", content: "base_currency,quote_currency,rate\nEUR,USD,abc\n"},
+		{name: "same currency", file: "rates.csv", content: "base_currency,quote_currency,rate\nEUR,EUR,1\n"},
+		{name: "negative rate", file: "rates.json", content: `[{"base_currency":"EUR","quote_currency":"USD","rate":"-1"}]`},
+		{name: "spread of one", file: "rates.json", content: `[{"base_currency":"EUR","quote_currency":"USD","rate":"1","spread":"1"}]`},
+		{name: "unsupported extension", file: "rates.txt", content: "EUR USD 1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := NewFileProvider(path).Rates(context.Background()); err == nil {
+				t.Error("Rates() error = nil, want an error")
+			}
+		})
+	}
+
+	if _, err := NewFileProvider(filepath.Join(t.TempDir(), "missing.csv")).Rates(context.Background()); err == nil {
+		t.Error("Rates() of a missing file error = nil, want an error")
+	}
+}
@@ -0,0 +1,49 @@
+// Package fx fetches FX rates from pluggable providers and stores them as
+// snapshots that converted transfers reference.
+package fx
+
+import (
+	"context"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// FXRateProvider is a source of FX rates
+type FXRateProvider interface {
+	// Name identifies the provider, it is recorded as the source of its rates
+	Name() string
+	// Rates returns the current rate of every currency pair the provider knows
+	Rates(ctx context.Context) ([]*store.FXRate, error)
+}
+
+// AdminProvider serves the rates entered through the admin SetFXRate RPC
+type AdminProvider struct {
+	store *store.Store
+}
+
+// NewAdminProvider creates a provider of the admin-entered rates
+func NewAdminProvider(store *store.Store) *AdminProvider {
+	return &AdminProvider{store: store}
+}
+
+// Ensure AdminProvider implements the FXRateProvider interface
+var _ FXRateProvider = (*AdminProvider)(nil)
+
+// Name returns "admin"
+func (p *AdminProvider) Name() string {
+	return "admin"
+}
+
+// Rates returns the latest effective admin-entered rate of every currency pair
+func (p *AdminProvider) Rates(ctx context.Context) ([]*store.FXRate, error) {
+	rates, err := p.store.ManualFXRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rate := range rates {
+		rate.Source = p.Name()
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,129 @@
+package fx
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// Refresher periodically fetches the rates of a provider and stores them as a
+// snapshot, conversions use the latest stored rate of a currency pair
+type Refresher struct {
+	logger   *slog.Logger
+	provider FXRateProvider
+	store    *store.Store
+	interval time.Duration
+}
+
+// NewRefresher creates a refresher that stores the rates of provider every interval
+func NewRefresher(
+	logger *slog.Logger,
+	provider FXRateProvider,
+	store *store.Store,
+	interval time.Duration,
+) *Refresher {
+	return &Refresher{
+		logger:   logger,
+		provider: provider,
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Refresh fetches the rates once and stores them as a snapshot, unless they
+// are the rates of the latest snapshot of the provider. It returns the id of
+// the snapshot holding the rates, which is empty when the provider had no rates.
+func (r *Refresher) Refresh(ctx context.Context) (string, error) {
+	rates, err := r.provider.Rates(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rates) == 0 {
+		return "", nil
+	}
+
+	latestID, latest, err := r.store.LatestFXSnapshot(ctx, r.provider.Name())
+	if err != nil {
+		return "", err
+	}
+	if latestID != "" && sameRates(latest, rates, r.provider.Name()) {
+		r.logger.DebugContext(ctx, "fx rates unchanged", "snapshot_id", latestID)
+		return latestID, nil
+	}
+
+	snapshotID, err := r.store.CreateFXSnapshot(ctx, r.provider.Name(), rates)
+	if err != nil {
+		return "", err
+	}
+
+	var stale int
+	for _, rate := range rates {
+		if rate.Stale {
+			stale++
+		}
+	}
+
+	r.logger.InfoContext(ctx, "fx rates refreshed",
+		"snapshot_id", snapshotID,
+		"rates", len(rates),
+		"stale", stale,
+	)
+	return snapshotID, nil
+}
+
+// Run refreshes the rates right away and then every interval until ctx is done
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Refresh(ctx); err != nil {
+			r.logger.ErrorContext(ctx, "failed to refresh fx rates", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sameRates reports whether stored and fetched hold the same rate of every
+// currency pair. Fetched rates without a source are stored with source, and
+// times are compared to the microsecond Postgres stores.
+func sameRates(stored, fetched []*store.FXRate, source string) bool {
+	if len(stored) != len(fetched) {
+		return false
+	}
+
+	pairs := make(map[string]*store.FXRate, len(stored))
+	for _, rate := range stored {
+		pairs[rate.BaseCurrency+"/"+rate.QuoteCurrency] = rate
+	}
+
+	for _, rate := range fetched {
+		old, ok := pairs[rate.BaseCurrency+"/"+rate.QuoteCurrency]
+		if !ok {
+			return false
+		}
+
+		rateSource := rate.Source
+		if rateSource == "" {
+			rateSource = source
+		}
+
+		if !old.Rate.Equal(rate.Rate) ||
+			!old.Spread.Equal(rate.Spread) ||
+			!old.EffectiveAt.Truncate(time.Microsecond).Equal(rate.EffectiveAt.Truncate(time.Microsecond)) ||
+			old.Source != rateSource ||
+			old.Stale != rate.Stale {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+func TestSameRates(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 123456789, time.UTC)
+	stored := func() []*store.FXRate {
+		rates := []*store.FXRate{
+			testRate("EUR/USD", "1.1", now.Truncate(time.Microsecond)),
+			testRate("GBP/USD", "1.3", now.Truncate(time.Microsecond)),
+		}
+		for _, rate := range rates {
+			rate.Source = "file"
+		}
+		return rates
+	}
+
+	tests := []struct {
+		name    string
+		fetched []*store.FXRate
+		want    bool
+	}{
+		{
+			name:    "same rates in another order",
+			fetched: []*store.FXRate{testRate("GBP/USD", "1.30", now), testRate("EUR/USD", "1.1", now)},
+			want:    true,
+		},
+		{
+			name:    "changed rate",
+			fetched: []*store.FXRate{testRate("EUR/USD", "1.2", now), testRate("GBP/USD", "1.3", now)},
+			want:    false,
+		},
+		{
+			name:    "later effective time",
+			fetched: []*store.FXRate{testRate("EUR/USD", "1.1", now.Add(time.Second)), testRate("GBP/USD", "1.3", now)},
+			want:    false,
+		},
+		{
+			name:    "missing pair",
+			fetched: []*store.FXRate{testRate("EUR/USD", "1.1", now)},
+			want:    false,
+		},
+		{
+			name: "rate became stale",
+			fetched: func() []*store.FXRate {
+				rates := []*store.FXRate{testRate("EUR/USD", "1.1", now), testRate("GBP/USD", "1.3", now)}
+				rates[0].Stale = true
+				return rates
+			}(),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameRates(stored(), tt.fetched, "file"); got != tt.want {
+				t.Errorf("sameRates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package interest
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// Publisher publishes the event of an interest payment
+type Publisher func(ctx context.Context, payment *store.PostedTransaction)
+
+// Job accrues and posts interest for every day that has ended, see
+// store.RunInterest for what a run of a day does
+type Job struct {
+	logger   *slog.Logger
+	store    *store.Store
+	publish  Publisher
+	interval time.Duration
+}
+
+// NewJob creates a job that looks for days to run every interval and
+// publishes the interest payments with publish, which may be nil
+func NewJob(
+	logger *slog.Logger,
+	store *store.Store,
+	publish Publisher,
+	interval time.Duration,
+) *Job {
+	return &Job{
+		logger:   logger,
+		store:    store,
+		publish:  publish,
+		interval: interval,
+	}
+}
+
+// RunDate runs the interest of one day, running a day again changes nothing
+func (j *Job) RunDate(ctx context.Context, date time.Time) (*store.InterestRun, error) {
+	run, err := j.store.RunInterest(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.publish != nil {
+		for _, payment := range run.Payments {
+			j.publish(ctx, payment)
+		}
+	}
+
+	j.logger.InfoContext(ctx, "interest run completed",
+		"date", run.Date.Format(time.DateOnly),
+		"accruals", run.Accruals,
+		"postings", run.Postings,
+	)
+	return run, nil
+}
+
+// CatchUp runs every day after the last completed run up to yesterday.
+// Without a previous run it only runs yesterday.
+func (j *Job) CatchUp(ctx context.Context) error {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+
+	date := yesterday
+	last, ok, err := j.store.LastInterestRun(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		date = last.AddDate(0, 0, 1)
+	}
+
+	for ; !date.After(yesterday) && ctx.Err() == nil; date = date.AddDate(0, 0, 1) {
+		if _, err = j.RunDate(ctx, date); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run catches up right away and then every interval until ctx is done
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.CatchUp(ctx); err != nil {
+			j.logger.ErrorContext(ctx, "failed to run interest", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
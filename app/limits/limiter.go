@@ -0,0 +1,289 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// Reasons of the ErrorInfo detail of a transfer rejected by a limit
+const (
+	ReasonPerTransaction = "PER_TRANSACTION_LIMIT_EXCEEDED"
+	ReasonDailyAmount    = "DAILY_AMOUNT_LIMIT_EXCEEDED"
+	ReasonMonthlyAmount  = "MONTHLY_AMOUNT_LIMIT_EXCEEDED"
+	ReasonDailyCount     = "DAILY_COUNT_LIMIT_EXCEEDED"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail of a rejected transfer
+const ErrorDomain = store.ErrorDomain
+
+const (
+	// dayWindow and monthWindow are the sliding windows of the daily and
+	// monthly limits
+	dayWindow   = 24 * time.Hour
+	monthWindow = 30 * 24 * time.Hour
+
+	// amountPlaces is the precision amounts are kept in Redis with, the
+	// precision of the ledger
+	amountPlaces = 6
+
+	// rebuiltMember marks a window that holds every transfer of the last
+	// month, a window without it is rebuilt from Postgres. It is scored a month
+	// ahead, so a window is also rebuilt two months after its last rebuild.
+	rebuiltMember = "rebuilt"
+)
+
+// reserveScript removes the transfers that left the monthly window, checks
+// the limits against the transfers still in it and adds the new transfer.
+// Members are "<transaction id>|<amount in millionths>" scored by the time
+// of the transfer in milliseconds. It returns REBUILD when the window was lost,
+// the limit that was hit or OK.
+//
+// KEYS[1] window
+// ARGV    now, day window, month window, member, amount,
+//
+//	daily count, daily amount, monthly amount (-1 when not set)
+var reserveScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], '` + rebuiltMember + `') then
+	return 'REBUILD'
+end
+
+local now = tonumber(ARGV[1])
+local dayStart = now - tonumber(ARGV[2])
+local monthStart = now - tonumber(ARGV[3])
+local amount = tonumber(ARGV[5])
+local dailyCount = tonumber(ARGV[6])
+local dailyAmount = tonumber(ARGV[7])
+local monthlyAmount = tonumber(ARGV[8])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. monthStart)
+
+local dayCount, dayTotal, monthTotal = 0, 0, 0
+local members = redis.call('ZRANGE', KEYS[1], 0, -1, 'WITHSCORES')
+for i = 1, #members, 2 do
+	local sent = tonumber(string.match(members[i], '|(%d+)$'))
+	if sent then
+		monthTotal = monthTotal + sent
+		if tonumber(members[i + 1]) >= dayStart then
+			dayCount = dayCount + 1
+			dayTotal = dayTotal + sent
+		end
+	end
+end
+
+if dailyCount >= 0 and dayCount + 1 > dailyCount then
+	return 'DAILY_COUNT'
+end
+if dailyAmount >= 0 and dayTotal + amount > dailyAmount then
+	return 'DAILY_AMOUNT'
+end
+if monthlyAmount >= 0 and monthTotal + amount > monthlyAmount then
+	return 'MONTHLY_AMOUNT'
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 'OK'
+`)
+
+// Limiter enforces the transfer limits of the sending accounts. The transfers
+// of the last month are counted in a sliding window per account in Redis,
+// rebuilt from the transactions in Postgres when Redis lost it.
+type Limiter struct {
+	logger *slog.Logger
+	redis  *redis.Client
+	store  *store.Store
+}
+
+// NewLimiter creates a limiter that counts transfers in redis
+func NewLimiter(
+	logger *slog.Logger,
+	redis *redis.Client,
+	store *store.Store,
+) *Limiter {
+	return &Limiter{
+		logger: logger,
+		redis:  redis,
+		store:  store,
+	}
+}
+
+// Reserve checks a transfer against the limits of its sender and counts it in
+// the windows of the sender. release uncounts it and must be called when the
+// transfer is not created. A transfer over a limit is rejected with
+// ResourceExhausted and an ErrorInfo detail whose reason names the limit.
+func (l *Limiter) Reserve(
+	ctx context.Context,
+	request *store.TransactionRequest,
+) (release func(context.Context), err error) {
+	release = func(context.Context) {}
+
+	limits, err := l.store.GetAccountLimits(ctx, request.FromAccountID, request.Currency)
+	if err != nil || limits == nil {
+		return release, err
+	}
+
+	if limits.PerTransactionMax.Valid && request.Amount.GreaterThan(limits.PerTransactionMax.Decimal) {
+		return release, limitError(ReasonPerTransaction, limits.PerTransactionMax.Decimal, limits.Currency, "transaction")
+	}
+
+	if !limits.DailyAmount.Valid && !limits.MonthlyAmount.Valid && limits.DailyCount == nil {
+		return release, nil
+	}
+
+	key := windowKey(request.FromAccountID)
+	member := windowMember(request.TransactionID, request.Amount)
+	args := []any{
+		time.Now().UnixMilli(),
+		dayWindow.Milliseconds(),
+		monthWindow.Milliseconds(),
+		member,
+		amountMillionths(request.Amount),
+		-1, -1, -1,
+	}
+	if limits.DailyCount != nil {
+		args[5] = *limits.DailyCount
+	}
+	if limits.DailyAmount.Valid {
+		args[6] = amountMillionths(limits.DailyAmount.Decimal)
+	}
+	if limits.MonthlyAmount.Valid {
+		args[7] = amountMillionths(limits.MonthlyAmount.Decimal)
+	}
+
+	result, err := reserveScript.Run(ctx, l.redis, []string{key}, args...).Text()
+	if err == nil && result == "REBUILD" {
+		if err = l.rebuild(ctx, request.FromAccountID); err == nil {
+			result, err = reserveScript.Run(ctx, l.redis, []string{key}, args...).Text()
+		}
+	}
+	if err != nil {
+		l.logger.ErrorContext(ctx, "failed to check transfer limits", "error", err,
+			"account_id", request.FromAccountID)
+		return release, status.Errorf(codes.Unavailable, "failed to check transfer limits")
+	}
+
+	switch result {
+	case "OK":
+	case "DAILY_COUNT":
+		return release, limitError(ReasonDailyCount, decimal.NewFromInt(int64(*limits.DailyCount)), "", "day")
+	case "DAILY_AMOUNT":
+		return release, limitError(ReasonDailyAmount, limits.DailyAmount.Decimal, limits.Currency, "day")
+	case "MONTHLY_AMOUNT":
+		return release, limitError(ReasonMonthlyAmount, limits.MonthlyAmount.Decimal, limits.Currency, "month")
+	default:
+		l.logger.ErrorContext(ctx, "unexpected transfer limit result", "result", result)
+		return release, status.Errorf(codes.Internal, "failed to check transfer limits")
+	}
+
+	return func(ctx context.Context) {
+		l.Release(ctx, request.FromAccountID, request.TransactionID, request.Amount)
+	}, nil
+}
+
+// Release uncounts a transfer that was counted by Reserve but never moved
+// money, such as a transfer rejected on review
+func (l *Limiter) Release(
+	ctx context.Context,
+	accountID, transactionID string,
+	amount decimal.Decimal,
+) {
+	if err := l.redis.ZRem(ctx, windowKey(accountID), windowMember(transactionID, amount)).Err(); err != nil {
+		l.logger.ErrorContext(ctx, "failed to release transfer limits", "error", err,
+			"account_id", accountID, "transaction_id", transactionID)
+	}
+}
+
+// rebuild fills the window of an account with its transfers of the last month.
+// Transfers counted concurrently are kept, their members are the same.
+func (l *Limiter) rebuild(ctx context.Context, accountID string) error {
+	transfers, err := l.store.OutgoingTransfers(ctx, accountID, time.Now().Add(-monthWindow))
+	if err != nil {
+		return err
+	}
+
+	members := make([]redis.Z, 0, len(transfers)+1)
+	for _, transfer := range transfers {
+		members = append(members, redis.Z{
+			Score:  float64(transfer.CreatedAt.UnixMilli()),
+			Member: windowMember(transfer.TransactionID, transfer.Amount),
+		})
+	}
+	members = append(members, redis.Z{Score: float64(time.Now().Add(monthWindow).UnixMilli()), Member: rebuiltMember})
+
+	key := windowKey(accountID)
+	pipe := l.redis.TxPipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.PExpire(ctx, key, monthWindow)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	l.logger.InfoContext(ctx, "transfer limit window rebuilt",
+		"account_id", accountID,
+		"transfers", len(transfers),
+	)
+	return nil
+}
+
+// limitError is the ResourceExhausted error of a transfer over a limit
+func limitError(reason string, limit decimal.Decimal, currency, window string) error {
+	message := fmt.Sprintf("transfer exceeds the %s limit of %s", window, limit)
+	if currency != "" {
+		message += " " + currency
+	}
+
+	metadata := map[string]string{
+		"limit":  limit.String(),
+		"window": window,
+	}
+	if currency != "" {
+		metadata["currency"] = currency
+	}
+
+	st, err := status.New(codes.ResourceExhausted, message).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	return st.Err()
+}
+
+// windowKey is the Redis key of the window of an account
+func windowKey(accountID string) string {
+	return "dbank:limits:" + accountID
+}
+
+// windowMember is the member of a transfer in a window
+func windowMember(transactionID string, amount decimal.Decimal) string {
+	return transactionID + "|" + strconv.FormatInt(amountMillionths(amount), 10)
+}
+
+// amountMillionths is an amount in millionths, the integer Redis compares
+func amountMillionths(amount decimal.Decimal) int64 {
+	return amount.Shift(amountPlaces).IntPart()
+}
+
+// Reason returns the reason of the ErrorInfo detail of an error, empty when
+// it has none
+func Reason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			return info.Reason
+		}
+	}
+	return ""
+}
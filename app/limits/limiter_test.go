@@ -0,0 +1,250 @@
+package limits
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+	"github.com/amjadjibon/dbank/db"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+	"github.com/amjadjibon/dbank/pkg/redisx"
+)
+
+func TestLimitError(t *testing.T) {
+	err := limitError(ReasonDailyAmount, decimal.NewFromInt(500), "USD", "day")
+
+	if got := status.Code(err); got != codes.ResourceExhausted {
+		t.Errorf("code = %s, want %s", got, codes.ResourceExhausted)
+	}
+	if got := Reason(err); got != ReasonDailyAmount {
+		t.Errorf("Reason() = %q, want %q", got, ReasonDailyAmount)
+	}
+	if got := Reason(status.Error(codes.ResourceExhausted, "busy")); got != "" {
+		t.Errorf("Reason() without details = %q, want empty", got)
+	}
+}
+
+func TestWindowMember(t *testing.T) {
+	if got := windowMember("tx", decimal.RequireFromString("12.345678")); got != "tx|12345678" {
+		t.Errorf("windowMember() = %q, want tx|12345678", got)
+	}
+}
+
+// newTestLimiter creates a limiter against the database in DB_URL and the
+// Redis in REDIS_URL, the tests are skipped when either is not set
+func newTestLimiter(t *testing.T) *Limiter {
+	t.Helper()
+
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		t.Skip("DB_URL is not set")
+	}
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+
+	ctx := context.Background()
+	if err := db.MigrateUp(ctx, dbURL); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	pg, err := dbx.NewPostgres(dbURL, dbx.MaxPoolSize(5))
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(pg.Close)
+
+	rdb, err := redisx.NewRedisClient(ctx, redisURL)
+	if err != nil {
+		t.Fatalf("failed to connect to redis: %v", err)
+	}
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewLimiter(logger, rdb, store.NewStore(pg, logger))
+}
+
+// createLimitedAccount creates a user with a USD account limited by limit and
+// returns the account id. The limits limit does not set are set high, so the
+// limits of the less specific scopes do not apply.
+func createLimitedAccount(t *testing.T, l *Limiter, limit *store.TransferLimit) string {
+	t.Helper()
+
+	ctx := context.Background()
+	id := idx.UUID4()
+	accountID := idx.UUID4()
+	err := l.store.CreateAccount(ctx, &store.CreateUserRequest{
+		ID:            id,
+		Username:      "test-" + id,
+		Email:         id + "@example.com",
+		Password:      "secret",
+		AccountID:     accountID,
+		AccountName:   "test",
+		AccountType:   "checking",
+		AccountNumber: accountID[:8],
+		Balance:       decimal.NewFromInt(1000),
+		Currency:      "USD",
+		Status:        store.AccountStatusActive,
+	})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	t.Cleanup(func() { l.redis.Del(context.Background(), windowKey(accountID)) })
+
+	high := decimal.NewNullDecimal(decimal.NewFromInt(1_000_000))
+	limit.Scope = store.LimitScopeAccount
+	limit.ScopeValue = accountID
+	limit.Currency = "USD"
+	if !limit.PerTransactionMax.Valid {
+		limit.PerTransactionMax = high
+	}
+	if !limit.DailyAmount.Valid {
+		limit.DailyAmount = high
+	}
+	if !limit.MonthlyAmount.Valid {
+		limit.MonthlyAmount = high
+	}
+	if limit.DailyCount == nil {
+		count := 1_000
+		limit.DailyCount = &count
+	}
+	if err = l.store.SetTransferLimit(ctx, limit); err != nil {
+		t.Fatalf("SetTransferLimit() error = %v", err)
+	}
+
+	return accountID
+}
+
+// transferRequest is a USD transfer from an account
+func transferRequest(from, amount string) *store.TransactionRequest {
+	return &store.TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   from,
+		TransactionType: "transfer",
+		Amount:          decimal.RequireFromString(amount),
+		Currency:        "USD",
+		Status:          store.TransactionStatusSuccess,
+	}
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	two := 2
+	tests := []struct {
+		name    string
+		limit   *store.TransferLimit
+		amounts []string
+		want    string
+	}{
+		{
+			name:    "daily count",
+			limit:   &store.TransferLimit{DailyCount: &two},
+			amounts: []string{"1", "1", "1"},
+			want:    ReasonDailyCount,
+		},
+		{
+			name:    "daily amount",
+			limit:   &store.TransferLimit{DailyAmount: decimal.NewNullDecimal(decimal.NewFromInt(100))},
+			amounts: []string{"60", "40", "0.000001"},
+			want:    ReasonDailyAmount,
+		},
+		{
+			name:    "monthly amount",
+			limit:   &store.TransferLimit{MonthlyAmount: decimal.NewNullDecimal(decimal.NewFromInt(100))},
+			amounts: []string{"60", "40", "0.000001"},
+			want:    ReasonMonthlyAmount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID := createLimitedAccount(t, l, tt.limit)
+
+			last := len(tt.amounts) - 1
+			for _, amount := range tt.amounts[:last] {
+				if _, err := l.Reserve(ctx, transferRequest(accountID, amount)); err != nil {
+					t.Fatalf("Reserve(%s) error = %v", amount, err)
+				}
+			}
+
+			_, err := l.Reserve(ctx, transferRequest(accountID, tt.amounts[last]))
+			if status.Code(err) != codes.ResourceExhausted || Reason(err) != tt.want {
+				t.Errorf("Reserve(%s) over the limit error = %v, want %s", tt.amounts[last], err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter_Release(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	one := 1
+	accountID := createLimitedAccount(t, l, &store.TransferLimit{DailyCount: &one})
+
+	release, err := l.Reserve(ctx, transferRequest(accountID, "10"))
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err = l.Reserve(ctx, transferRequest(accountID, "10")); Reason(err) != ReasonDailyCount {
+		t.Fatalf("Reserve() over the limit error = %v, want %s", err, ReasonDailyCount)
+	}
+
+	// the transfer failed, so it must not count towards the limit
+	release(ctx)
+
+	if _, err = l.Reserve(ctx, transferRequest(accountID, "10")); err != nil {
+		t.Errorf("Reserve() after the release error = %v", err)
+	}
+}
+
+func TestLimiter_Rebuild(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	two := 2
+	accountID := createLimitedAccount(t, l, &store.TransferLimit{
+		DailyCount:  &two,
+		DailyAmount: decimal.NewNullDecimal(decimal.NewFromInt(100)),
+	})
+	to := createLimitedAccount(t, l, &store.TransferLimit{})
+
+	request := transferRequest(accountID, "70")
+	request.ToAccountID = to
+	if _, err := l.Reserve(ctx, request); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := l.store.CreateTransaction(ctx, request); err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+
+	// Redis lost the window, the transfer is counted again from Postgres
+	if err := l.redis.Del(ctx, windowKey(accountID)).Err(); err != nil {
+		t.Fatalf("failed to delete the window: %v", err)
+	}
+
+	_, err := l.Reserve(ctx, transferRequest(accountID, "40"))
+	if Reason(err) != ReasonDailyAmount {
+		t.Errorf("Reserve() after the window was lost error = %v, want %s", err, ReasonDailyAmount)
+	}
+	if err = l.redis.ZScore(ctx, windowKey(accountID), rebuiltMember).Err(); err != nil {
+		t.Errorf("window was not marked rebuilt: %v", err)
+	}
+
+	if _, err = l.Reserve(ctx, transferRequest(accountID, "30")); err != nil {
+		t.Errorf("Reserve() within the rebuilt window error = %v", err)
+	}
+	if _, err = l.Reserve(ctx, transferRequest(accountID, "1")); Reason(err) != ReasonDailyCount {
+		t.Errorf("Reserve() over the rebuilt count error = %v, want %s", err, ReasonDailyCount)
+	}
+}
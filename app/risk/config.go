@@ -0,0 +1,116 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// Types of rules in a rules file
+const (
+	RuleTypeNewPayeeAmount   = "new_payee_amount"
+	RuleTypeNewPayeeVelocity = "new_payee_velocity"
+	RuleTypeAmountSpike      = "amount_spike"
+)
+
+// RuleConfig declares a rule in a rules file. Action is the decision of the
+// rule when it triggers, review or deny. The other fields are the parameters
+// of the rule type:
+//
+//   - new_payee_amount: amount
+//   - new_payee_velocity: count and window
+//   - amount_spike: multiplier, window and min_history
+//
+// window is a Go duration such as "1h".
+type RuleConfig struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Action     string          `json:"action"`
+	Amount     decimal.Decimal `json:"amount"`
+	Count      int             `json:"count"`
+	Window     string          `json:"window"`
+	Multiplier decimal.Decimal `json:"multiplier"`
+	MinHistory int             `json:"min_history"`
+}
+
+// LoadRules reads a JSON file holding an array of rule declarations, the
+// rules run in the order of the file
+func LoadRules(path string) ([]Rule, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var configs []*RuleConfig
+	if err = json.Unmarshal(body, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(configs))
+	names := make(map[string]bool, len(configs))
+	for i, config := range configs {
+		rule, err := NewRule(config)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+
+		if names[rule.Name()] {
+			return nil, fmt.Errorf("rule %d: duplicate name %q", i+1, rule.Name())
+		}
+		names[rule.Name()] = true
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// NewRule creates the rule a declaration describes
+func NewRule(config *RuleConfig) (Rule, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if config.Action != store.RiskDecisionReview && config.Action != store.RiskDecisionDeny {
+		return nil, fmt.Errorf("action must be %s or %s", store.RiskDecisionReview, store.RiskDecisionDeny)
+	}
+
+	var window time.Duration
+	if config.Window != "" {
+		var err error
+		if window, err = time.ParseDuration(config.Window); err != nil {
+			return nil, fmt.Errorf("invalid window: %w", err)
+		}
+	}
+
+	switch config.Type {
+	case RuleTypeNewPayeeAmount:
+		if !config.Amount.IsPositive() {
+			return nil, fmt.Errorf("%s needs a positive amount", config.Type)
+		}
+		return &NewPayeeAmountRule{name: config.Name, action: config.Action, amount: config.Amount}, nil
+	case RuleTypeNewPayeeVelocity:
+		if config.Count <= 0 || window <= 0 {
+			return nil, fmt.Errorf("%s needs a positive count and window", config.Type)
+		}
+		return &NewPayeeVelocityRule{name: config.Name, action: config.Action, count: config.Count, window: window}, nil
+	case RuleTypeAmountSpike:
+		if !config.Multiplier.IsPositive() || window <= 0 {
+			return nil, fmt.Errorf("%s needs a positive multiplier and window", config.Type)
+		}
+		return &AmountSpikeRule{
+			name:       config.Name,
+			action:     config.Action,
+			multiplier: config.Multiplier,
+			window:     window,
+			minHistory: config.MinHistory,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", config.Type)
+	}
+}
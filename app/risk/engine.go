@@ -0,0 +1,105 @@
+package risk
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// severity orders the decisions, the most severe decision of the rules wins
+var severity = map[string]int{
+	store.RiskDecisionAllow:  0,
+	store.RiskDecisionReview: 1,
+	store.RiskDecisionDeny:   2,
+}
+
+// Assessment is the outcome of the rules on a transfer: the most severe
+// decision and the decision of every rule
+type Assessment struct {
+	Decision  string
+	Decisions []*store.RiskDecision
+}
+
+// Denial returns the first decision that denied the transfer, nil when none did
+func (a *Assessment) Denial() *store.RiskDecision {
+	for _, decision := range a.Decisions {
+		if decision.Decision == store.RiskDecisionDeny {
+			return decision
+		}
+	}
+	return nil
+}
+
+// Engine runs a chain of rules on the transfers between customer accounts
+// before they move money and stores every decision
+type Engine struct {
+	logger *slog.Logger
+	store  *store.Store
+	rules  []Rule
+}
+
+// NewEngine creates an engine that runs rules in order
+func NewEngine(
+	logger *slog.Logger,
+	store *store.Store,
+	rules []Rule,
+) *Engine {
+	return &Engine{
+		logger: logger,
+		store:  store,
+		rules:  rules,
+	}
+}
+
+// Assess runs every rule on a transfer and stores their decisions
+func (e *Engine) Assess(
+	ctx context.Context,
+	request *store.TransactionRequest,
+) (*Assessment, error) {
+	assessment, err := evaluate(ctx, e.store, e.rules, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = e.store.SaveRiskDecisions(ctx, request.TransactionID, request.FromAccountID,
+		assessment.Decisions); err != nil {
+		return nil, err
+	}
+
+	if assessment.Decision != store.RiskDecisionAllow {
+		e.logger.InfoContext(ctx, "transfer flagged by risk rules",
+			"transaction_id", request.TransactionID,
+			"from_account_id", request.FromAccountID,
+			"decision", assessment.Decision,
+		)
+	}
+
+	return assessment, nil
+}
+
+// evaluate runs rules on a transfer against a history
+func evaluate(
+	ctx context.Context,
+	history History,
+	rules []Rule,
+	request *store.TransactionRequest,
+) (*Assessment, error) {
+	assessment := &Assessment{
+		Decision:  store.RiskDecisionAllow,
+		Decisions: make([]*store.RiskDecision, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		decision, err := rule.Evaluate(ctx, history, request)
+		if err != nil {
+			return nil, err
+		}
+
+		assessment.Decisions = append(assessment.Decisions, decision)
+		if severity[decision.Decision] > severity[assessment.Decision] {
+			assessment.Decision = decision.Decision
+		}
+	}
+
+	return assessment, nil
+}
@@ -0,0 +1,161 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// History is the transfer history of the senders the rules look at
+type History interface {
+	PayeeTransferCount(ctx context.Context, fromAccountID, toAccountID string) (int, error)
+	NewPayeeCount(ctx context.Context, fromAccountID string, since time.Time) (int, error)
+	TransferStatsSince(ctx context.Context, fromAccountID string, since time.Time) (*store.TransferStats, error)
+}
+
+// Ensure the store provides the history of the rules
+var _ History = (*store.Store)(nil)
+
+// Rule decides whether a transfer is allowed, reviewed or denied. A rule that
+// does not trigger allows the transfer, one that does returns its action.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, history History, request *store.TransactionRequest) (*store.RiskDecision, error)
+}
+
+// NewPayeeAmountRule triggers on a transfer of more than Amount to a payee
+// the sender never paid before
+type NewPayeeAmountRule struct {
+	name   string
+	action string
+	amount decimal.Decimal
+}
+
+// Name returns the name of the rule
+func (r *NewPayeeAmountRule) Name() string {
+	return r.name
+}
+
+// Evaluate checks the amount of a transfer to a new payee
+func (r *NewPayeeAmountRule) Evaluate(
+	ctx context.Context,
+	history History,
+	request *store.TransactionRequest,
+) (*store.RiskDecision, error) {
+	if request.Amount.LessThanOrEqual(r.amount) {
+		return allow(r.name), nil
+	}
+
+	transfers, err := history.PayeeTransferCount(ctx, request.FromAccountID, request.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfers > 0 {
+		return allow(r.name), nil
+	}
+
+	return &store.RiskDecision{
+		Rule:     r.name,
+		Decision: r.action,
+		Reason:   fmt.Sprintf("amount %s to a new payee is above %s", request.Amount, r.amount),
+	}, nil
+}
+
+// NewPayeeVelocityRule triggers when a transfer to a new payee makes the
+// sender pay more than Count new payees within Window
+type NewPayeeVelocityRule struct {
+	name   string
+	action string
+	count  int
+	window time.Duration
+}
+
+// Name returns the name of the rule
+func (r *NewPayeeVelocityRule) Name() string {
+	return r.name
+}
+
+// Evaluate counts the new payees of the sender within the window
+func (r *NewPayeeVelocityRule) Evaluate(
+	ctx context.Context,
+	history History,
+	request *store.TransactionRequest,
+) (*store.RiskDecision, error) {
+	transfers, err := history.PayeeTransferCount(ctx, request.FromAccountID, request.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfers > 0 {
+		return allow(r.name), nil
+	}
+
+	payees, err := history.NewPayeeCount(ctx, request.FromAccountID, time.Now().Add(-r.window))
+	if err != nil {
+		return nil, err
+	}
+
+	if payees+1 <= r.count {
+		return allow(r.name), nil
+	}
+
+	return &store.RiskDecision{
+		Rule:     r.name,
+		Decision: r.action,
+		Reason:   fmt.Sprintf("%d new payees within %s, more than %d", payees+1, r.window, r.count),
+	}, nil
+}
+
+// AmountSpikeRule triggers on a transfer of more than Multiplier times the
+// average transfer of the sender within Window. Senders with fewer than
+// MinHistory transfers in the window are not checked.
+type AmountSpikeRule struct {
+	name       string
+	action     string
+	multiplier decimal.Decimal
+	window     time.Duration
+	minHistory int
+}
+
+// Name returns the name of the rule
+func (r *AmountSpikeRule) Name() string {
+	return r.name
+}
+
+// Evaluate compares the amount with the average transfer of the sender
+func (r *AmountSpikeRule) Evaluate(
+	ctx context.Context,
+	history History,
+	request *store.TransactionRequest,
+) (*store.RiskDecision, error) {
+	stats, err := history.TransferStatsSince(ctx, request.FromAccountID, time.Now().Add(-r.window))
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Count < r.minHistory || !stats.Average.IsPositive() {
+		return allow(r.name), nil
+	}
+
+	threshold := stats.Average.Mul(r.multiplier)
+	if request.Amount.LessThanOrEqual(threshold) {
+		return allow(r.name), nil
+	}
+
+	return &store.RiskDecision{
+		Rule:     r.name,
+		Decision: r.action,
+		Reason: fmt.Sprintf("amount %s is more than %s times the average of %s",
+			request.Amount, r.multiplier, stats.Average.Round(2)),
+	}, nil
+}
+
+// allow is the decision of a rule that did not trigger
+func allow(rule string) *store.RiskDecision {
+	return &store.RiskDecision{Rule: rule, Decision: store.RiskDecisionAllow}
+}
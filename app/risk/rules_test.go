@@ -0,0 +1,126 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+// fakeHistory is a sender history with fixed answers
+type fakeHistory struct {
+	payeeTransfers int
+	newPayees      int
+	stats          store.TransferStats
+}
+
+func (h *fakeHistory) PayeeTransferCount(context.Context, string, string) (int, error) {
+	return h.payeeTransfers, nil
+}
+
+func (h *fakeHistory) NewPayeeCount(context.Context, string, time.Time) (int, error) {
+	return h.newPayees, nil
+}
+
+func (h *fakeHistory) TransferStatsSince(context.Context, string, time.Time) (*store.TransferStats, error) {
+	return &h.stats, nil
+}
+
+func TestRules(t *testing.T) {
+	d := decimal.RequireFromString
+
+	newPayeeAmount := &NewPayeeAmountRule{name: "amount", action: store.RiskDecisionReview, amount: d("1000")}
+	velocity := &NewPayeeVelocityRule{name: "velocity", action: store.RiskDecisionDeny, count: 3, window: time.Hour}
+	spike := &AmountSpikeRule{name: "spike", action: store.RiskDecisionReview, multiplier: d("5"),
+		window: 30 * 24 * time.Hour, minHistory: 3}
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		history *fakeHistory
+		amount  string
+		want    string
+	}{
+		{"new payee below the amount", newPayeeAmount, &fakeHistory{}, "1000", store.RiskDecisionAllow},
+		{"new payee above the amount", newPayeeAmount, &fakeHistory{}, "1000.01", store.RiskDecisionReview},
+		{"known payee above the amount", newPayeeAmount, &fakeHistory{payeeTransfers: 1}, "5000", store.RiskDecisionAllow},
+		{"third new payee within the window", velocity, &fakeHistory{newPayees: 2}, "10", store.RiskDecisionAllow},
+		{"fourth new payee within the window", velocity, &fakeHistory{newPayees: 3}, "10", store.RiskDecisionDeny},
+		{"known payee after many new ones", velocity, &fakeHistory{payeeTransfers: 2, newPayees: 10}, "10", store.RiskDecisionAllow},
+		{
+			"amount within the multiplier", spike,
+			&fakeHistory{stats: store.TransferStats{Count: 3, Average: d("100")}}, "500", store.RiskDecisionAllow,
+		},
+		{
+			"amount above the multiplier", spike,
+			&fakeHistory{stats: store.TransferStats{Count: 3, Average: d("100")}}, "500.01", store.RiskDecisionReview,
+		},
+		{
+			"too little history", spike,
+			&fakeHistory{stats: store.TransferStats{Count: 2, Average: d("1")}}, "500", store.RiskDecisionAllow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := tt.rule.Evaluate(context.Background(), tt.history, &store.TransactionRequest{
+				FromAccountID: "from",
+				ToAccountID:   "to",
+				Amount:        d(tt.amount),
+			})
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if decision.Decision != tt.want || decision.Rule != tt.rule.Name() {
+				t.Errorf("Evaluate() = %+v, want %s by %s", decision, tt.want, tt.rule.Name())
+			}
+			if decision.Decision != store.RiskDecisionAllow && decision.Reason == "" {
+				t.Error("Evaluate() triggered without a reason")
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rules, err := LoadRules("testdata/rules.json")
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("LoadRules() returned %d rules, want 3", len(rules))
+	}
+
+	// a large transfer to the fourth new payee of the hour is reviewed by one
+	// rule and denied by another, the denial wins
+	assessment, err := evaluate(context.Background(), &fakeHistory{newPayees: 3}, rules, &store.TransactionRequest{
+		Amount: decimal.NewFromInt(2000),
+	})
+	if err != nil {
+		t.Fatalf("evaluate() error = %v", err)
+	}
+	if assessment.Decision != store.RiskDecisionDeny {
+		t.Errorf("decision = %s, want %s", assessment.Decision, store.RiskDecisionDeny)
+	}
+	if len(assessment.Decisions) != 3 {
+		t.Errorf("decisions = %d, want one per rule", len(assessment.Decisions))
+	}
+	if denial := assessment.Denial(); denial == nil || denial.Rule != "new-payee-velocity" {
+		t.Errorf("Denial() = %+v, want new-payee-velocity", denial)
+	}
+}
+
+func TestNewRule_Invalid(t *testing.T) {
+	for _, config := range []*RuleConfig{
+		{Type: RuleTypeNewPayeeAmount, Action: store.RiskDecisionReview, Amount: decimal.NewFromInt(1)},
+		{Name: "allow", Type: RuleTypeNewPayeeAmount, Action: store.RiskDecisionAllow, Amount: decimal.NewFromInt(1)},
+		{Name: "no amount", Type: RuleTypeNewPayeeAmount, Action: store.RiskDecisionReview},
+		{Name: "bad window", Type: RuleTypeNewPayeeVelocity, Action: store.RiskDecisionDeny, Count: 1, Window: "hour"},
+		{Name: "unknown", Type: "unknown", Action: store.RiskDecisionDeny},
+	} {
+		if _, err := NewRule(config); err == nil {
+			t.Errorf("NewRule(%+v) succeeded, want an error", config)
+		}
+	}
+}
@@ -9,16 +9,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/amjadjibon/dbank/app/consumer"
+	"github.com/amjadjibon/dbank/app/fx"
+	"github.com/amjadjibon/dbank/app/interest"
+	"github.com/amjadjibon/dbank/app/limits"
+	"github.com/amjadjibon/dbank/app/risk"
 	"github.com/amjadjibon/dbank/app/service"
 	"github.com/amjadjibon/dbank/app/store"
 	"github.com/amjadjibon/dbank/app/swagger"
@@ -39,6 +45,16 @@ type Server struct {
 	consumer       *consumer.Consumer
 	rabbitmqClient *amqpx.RabbitMQClient
 	mongoClient    *mongo.Client
+	redisClient    *redis.Client
+	fxRefresher    *fx.Refresher
+
+	scheduledTransfers *service.ScheduledTransferService
+	schedulerInterval  time.Duration
+	holds              *service.HoldService
+	holdExpiryInterval time.Duration
+	interestJob        *interest.Job
+	batches            *service.BatchService
+	batchInterval      time.Duration
 }
 
 func NewServer(
@@ -73,13 +89,6 @@ func NewServer(
 	logger.InfoContext(ctx, "connected to Redis",
 		"redis_url", cfg.RedisURL,
 	)
-	defer func() {
-		if err := redisClient.Close(); err != nil {
-			logger.ErrorContext(ctx, "failed to close Redis client",
-				"error", err,
-			)
-		}
-	}()
 
 	mongoClient, err := mongox.NewMongoClient(ctx, cfg.MongoURL)
 	if err != nil {
@@ -93,14 +102,6 @@ func NewServer(
 		"mongo_url", cfg.MongoURL,
 	)
 
-	defer func() {
-		if err := mongoClient.Disconnect(ctx); err != nil {
-			logger.ErrorContext(ctx, "failed to disconnect MongoDB client",
-				"error", err,
-			)
-		}
-	}()
-
 	// Initialize RabbitMQ client
 	rabbitmqClient, err := amqpx.NewRabbitMQClient(cfg.RabbitMQURL)
 	if err != nil {
@@ -120,15 +121,62 @@ func NewServer(
 	grpcServer := grpc.NewServer(opts...)
 
 	storage := store.NewStore(db, logger)
-	accountsService := service.NewAccountService(logger, storage)
-	transactionsService := service.NewTransactionService(logger, storage, rabbitmqClient)
+	usersService := service.NewUserService(logger, storage)
+	accountsService := service.NewAccountService(logger, storage, rabbitmqClient)
+	limiter := limits.NewLimiter(logger, redisClient, storage)
+
+	var riskEngine *risk.Engine
+	if cfg.RiskRulesFile != "" {
+		rules, err := risk.LoadRules(cfg.RiskRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load risk rules: %w", err)
+		}
+		riskEngine = risk.NewEngine(logger, storage, rules)
+		logger.InfoContext(ctx, "loaded risk rules",
+			"risk_rules_file", cfg.RiskRulesFile,
+			"rules", len(rules),
+		)
+	}
+
+	transactionsService := service.NewTransactionService(logger, storage, rabbitmqClient, limiter, riskEngine)
+	statementsService := service.NewStatementService(logger, storage)
+	fxService := service.NewFXService(logger, storage)
 
+	// Admin-entered rates take precedence over the rates file
+	fxProviders := []fx.FXRateProvider{fx.NewAdminProvider(storage)}
+	if cfg.FXRatesFile != "" {
+		fxProviders = append(fxProviders, fx.NewFileProvider(cfg.FXRatesFile))
+	}
+	fxRefresher := fx.NewRefresher(logger,
+		fx.NewCompositeProvider(logger, cfg.FXMaxRateAge, fxProviders...),
+		storage,
+		cfg.FXRefreshInterval,
+	)
+	adminService := service.NewAdminService(logger, storage, fxRefresher)
+	scheduledTransfersService := service.NewScheduledTransferService(logger, storage, transactionsService)
+	holdsService := service.NewHoldService(logger, storage, transactionsService, cfg.HoldTTL)
+	batchesService := service.NewBatchService(logger, storage, transactionsService, rabbitmqClient)
+
+	dbankv1.RegisterUserServiceServer(grpcServer, usersService)
 	dbankv1.RegisterAccountServiceServer(grpcServer, accountsService)
 	dbankv1.RegisterTransactionServiceServer(grpcServer, transactionsService)
+	dbankv1.RegisterStatementServiceServer(grpcServer, statementsService)
+	dbankv1.RegisterFXServiceServer(grpcServer, fxService)
+	dbankv1.RegisterAdminServiceServer(grpcServer, adminService)
+	dbankv1.RegisterScheduledTransferServiceServer(grpcServer, scheduledTransfersService)
+	dbankv1.RegisterHoldServiceServer(grpcServer, holdsService)
+	dbankv1.RegisterBatchServiceServer(grpcServer, batchesService)
 
 	reflection.Register(grpcServer)
 
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+	)
+	err = dbankv1.RegisterUserServiceHandlerServer(ctx, mux, usersService)
+	if err != nil {
+		return nil, err
+	}
+
 	err = dbankv1.RegisterAccountServiceHandlerServer(ctx, mux, accountsService)
 	if err != nil {
 		return nil, err
@@ -139,7 +187,40 @@ func NewServer(
 		return nil, err
 	}
 
+	err = dbankv1.RegisterStatementServiceHandlerServer(ctx, mux, statementsService)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbankv1.RegisterFXServiceHandlerServer(ctx, mux, fxService)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbankv1.RegisterAdminServiceHandlerServer(ctx, mux, adminService)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbankv1.RegisterScheduledTransferServiceHandlerServer(ctx, mux, scheduledTransfersService)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbankv1.RegisterHoldServiceHandlerServer(ctx, mux, holdsService)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbankv1.RegisterBatchServiceHandlerServer(ctx, mux, batchesService)
+	if err != nil {
+		return nil, err
+	}
+
 	router := chi.NewRouter()
+	router.Get("/dbank/v1/accounts/{id}/statements.camt053", statementsService.ExportCamt053)
+	router.Get("/dbank/v1/accounts/{account_id}/statement", statementsService.NegotiateStatement(mux))
+	router.Post("/dbank/v1/batches/upload", batchesService.Upload)
 	router.HandleFunc("/dbank/*", func(w http.ResponseWriter, r *http.Request) {
 		mux.ServeHTTP(w, r)
 	})
@@ -179,6 +260,8 @@ func NewServer(
 
 	messageConsumer.RegisterHandler(amqpx.TransactionSuccessRoute,
 		consumer.NewMongoLedgerConsumer(logger, mongoClient, mongoDatabaseName))
+	messageConsumer.RegisterHandler(amqpx.TransactionReversedRoute,
+		consumer.NewMongoLedgerConsumer(logger, mongoClient, mongoDatabaseName))
 
 	return &Server{
 		logger:         logger,
@@ -188,9 +271,29 @@ func NewServer(
 		consumer:       messageConsumer,
 		rabbitmqClient: rabbitmqClient,
 		mongoClient:    mongoClient,
+		redisClient:    redisClient,
+		fxRefresher:    fxRefresher,
+
+		scheduledTransfers: scheduledTransfersService,
+		schedulerInterval:  cfg.SchedulerInterval,
+		holds:              holdsService,
+		holdExpiryInterval: cfg.HoldExpiryInterval,
+		interestJob:        interest.NewJob(logger, storage, transactionsService.PublishPosted, cfg.InterestInterval),
+		batches:            batchesService,
+		batchInterval:      cfg.BatchInterval,
 	}, nil
 }
 
+// incomingHeaderMatcher forwards the Idempotency-Key header to gRPC metadata
+// on top of the headers grpc-gateway forwards by default
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, service.IdempotencyKeyHeader) {
+		return service.IdempotencyKeyMetadata, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 // Start runs both the gRPC and HTTP servers concurrently.
 func (s *Server) Start(ctx context.Context) error {
 	errCh := make(chan error)
@@ -225,6 +328,42 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
+	// Refresh the FX rates until the server is stopped
+	go func() {
+		s.logger.InfoContext(ctx, "starting FX rate refresher...")
+		s.fxRefresher.Run(ctx)
+	}()
+
+	// Execute the scheduled transfers as they fall due
+	go func() {
+		s.logger.InfoContext(ctx, "starting scheduled transfer worker...",
+			"interval", s.schedulerInterval,
+		)
+		s.scheduledTransfers.RunWorker(ctx, s.schedulerInterval)
+	}()
+
+	// Release the holds that expire
+	go func() {
+		s.logger.InfoContext(ctx, "starting hold expiry worker...",
+			"interval", s.holdExpiryInterval,
+		)
+		s.holds.RunExpiry(ctx, s.holdExpiryInterval)
+	}()
+
+	// Accrue and post interest for the days that end
+	go func() {
+		s.logger.InfoContext(ctx, "starting interest job...")
+		s.interestJob.Run(ctx)
+	}()
+
+	// Execute the rows of the uploaded payment files
+	go func() {
+		s.logger.InfoContext(ctx, "starting batch worker...",
+			"interval", s.batchInterval,
+		)
+		s.batches.RunWorker(ctx, s.batchInterval)
+	}()
+
 	// Channel to listen for interrupt signals (for graceful shutdown)
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -270,6 +409,17 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Close Redis connection
+	if s.redisClient != nil {
+		if err := s.redisClient.Close(); err != nil {
+			s.logger.ErrorContext(ctx, "failed to close Redis client",
+				"error", err,
+			)
+		} else {
+			s.logger.InfoContext(ctx, "Redis connection closed")
+		}
+	}
+
 	// Close RabbitMQ connection
 	if s.rabbitmqClient != nil {
 		if err := s.rabbitmqClient.Close(); err != nil {
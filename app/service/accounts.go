@@ -3,29 +3,36 @@ package service
 import (
 	"context"
 	"log/slog"
-	"strconv"
+	"slices"
+	"time"
 
 	"github.com/amjadjibon/dbank/app/store"
 	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+	"github.com/amjadjibon/dbank/pkg/amqpx"
+	"github.com/amjadjibon/dbank/pkg/currency"
 	"github.com/amjadjibon/dbank/pkg/passw"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type AccountService struct {
-	logger       *slog.Logger
-	accountStore *store.Store
+	logger         *slog.Logger
+	accountStore   *store.Store
+	rabbitmqClient *amqpx.RabbitMQClient
 	dbankv1.UnimplementedAccountServiceServer
 }
 
 func NewAccountService(
 	logger *slog.Logger,
 	accountStore *store.Store,
+	rabbitmqClient *amqpx.RabbitMQClient,
 ) *AccountService {
 	return &AccountService{
-		accountStore: accountStore,
-		logger:       logger,
+		accountStore:   accountStore,
+		logger:         logger,
+		rabbitmqClient: rabbitmqClient,
 	}
 }
 
@@ -40,8 +47,16 @@ func (a *AccountService) CreateAccount(
 		return nil, status.Errorf(codes.InvalidArgument, "username, email, and password are required")
 	}
 
-	if request.AccountName == "" || request.AccountType == "" || request.AccountCurrency == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "account name, type, and currency are required")
+	balanceString, currencyCode, err := requestAmount(request.OpeningBalance,
+		request.AccountBalance, request.AccountCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := a.openingAccount(ctx, request.AccountName, request.AccountType,
+		balanceString, currencyCode, request.AccountStatus)
+	if err != nil {
+		return nil, err
 	}
 
 	// Hash the password
@@ -51,57 +66,128 @@ func (a *AccountService) CreateAccount(
 		return nil, status.Errorf(codes.Internal, "failed to hash password")
 	}
 
-	// Parse account balance
-	balance, err := strconv.ParseFloat(request.AccountBalance, 64)
-	if err != nil {
-		a.logger.ErrorContext(ctx, "failed to parse balance", "error", err)
-		return nil, status.Errorf(codes.InvalidArgument, "invalid balance format")
-	}
-
-	// Set default status if not provided
-	accountStatus := request.AccountStatus
-	if accountStatus == "" {
-		accountStatus = "active"
-	}
-
-	// Generate UUIDs
-	userID := uuid.New().String()
-	accountID := uuid.New().String()
-
-	// Generate an account number (this is a simple implementation, you might want a more sophisticated approach)
-	accountNumber := uuid.New().String()[:8]
+	account.UserID = uuid.New().String()
 
-	// Create the account
+	// Create the user together with the account
 	err = a.accountStore.CreateAccount(ctx, &store.CreateUserRequest{
-		ID:            userID,
+		ID:            account.UserID,
 		Username:      request.Username,
 		Email:         request.Email,
 		Password:      hashedPassword,
-		AccountID:     accountID,
-		AccountName:   request.AccountName,
-		AccountType:   request.AccountType,
-		AccountNumber: accountNumber,
-		Balance:       balance,
-		Currency:      request.AccountCurrency,
-		Status:        accountStatus,
+		AccountID:     account.AccountID,
+		AccountName:   account.AccountName,
+		AccountType:   account.AccountType,
+		AccountNumber: account.AccountNumber,
+		Balance:       account.Balance,
+		Currency:      account.Currency,
+		Status:        account.Status,
 	})
 	if err != nil {
 		a.logger.ErrorContext(ctx, "failed to create account", "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to create account: %v", err)
+		return nil, status.Errorf(status.Code(err), "failed to create account: %v", err)
 	}
 
-	return &dbankv1.CreateAccountResponse{
-		Id:              userID,
-		Username:        request.Username,
-		Email:           request.Email,
-		AccountName:     request.AccountName,
-		AccountType:     request.AccountType,
-		AccountBalance:  request.AccountBalance,
-		AccountCurrency: request.AccountCurrency,
-		AccountStatus:   accountStatus,
+	return openedAccountResponse(account, request.Username, request.Email), nil
+}
+
+// OpenAccount opens another account for an existing user
+func (a *AccountService) OpenAccount(
+	ctx context.Context,
+	request *dbankv1.OpenAccountRequest,
+) (*dbankv1.CreateAccountResponse, error) {
+	if request.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	balanceString, currencyCode, err := requestAmount(request.OpeningBalance, "", request.AccountCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := a.openingAccount(ctx, request.AccountName, request.AccountType,
+		balanceString, currencyCode, request.AccountStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.accountStore.GetUser(ctx, request.UserId)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", request.UserId)
+		return nil, status.Errorf(status.Code(err), "failed to get user: %v", err)
+	}
+
+	account.UserID = user.ID
+	if err = a.accountStore.OpenAccount(ctx, account); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to open account: %v", err)
+	}
+
+	return openedAccountResponse(account, user.Username, user.Email), nil
+}
+
+// openingAccount validates the account fields shared by CreateAccount and
+// OpenAccount and returns the account to open, with a new id and number
+func (a *AccountService) openingAccount(
+	ctx context.Context,
+	accountName, accountType, balanceString, currencyCode, accountStatus string,
+) (*store.OpenAccountRequest, error) {
+	if accountName == "" || accountType == "" || currencyCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account name, type, and currency are required")
+	}
+
+	// Parse account balance, an account may be opened empty
+	balance := decimal.Zero
+	if balanceString != "" {
+		var err error
+		if balance, err = parseBalance(balanceString); err != nil {
+			a.logger.ErrorContext(ctx, "failed to parse balance", "error", err)
+			return nil, err
+		}
+	}
+
+	if balance.IsNegative() {
+		return nil, status.Errorf(codes.InvalidArgument, "balance cannot be negative")
+	}
+
+	if err := validateMoney(balance, currencyCode); err != nil {
+		return nil, err
+	}
+
+	// Accounts are opened active unless they wait for activation
+	if accountStatus == "" {
+		accountStatus = store.AccountStatusActive
+	}
+	if accountStatus != store.AccountStatusActive && accountStatus != store.AccountStatusPending {
+		return nil, status.Errorf(codes.InvalidArgument, "accounts are opened active or pending, not %q", accountStatus)
+	}
+
+	return &store.OpenAccountRequest{
+		AccountID:   uuid.New().String(),
+		AccountName: accountName,
+		AccountType: accountType,
+		// Generate an account number (this is a simple implementation, you might want a more sophisticated approach)
+		AccountNumber: uuid.New().String()[:8],
+		Balance:       balance,
+		Currency:      currencyCode,
+		Status:        accountStatus,
 	}, nil
 }
 
+// openedAccountResponse maps an opened account to its API representation
+func openedAccountResponse(account *store.OpenAccountRequest, username, email string) *dbankv1.CreateAccountResponse {
+	return &dbankv1.CreateAccountResponse{
+		Id:              account.AccountID,
+		UserId:          account.UserID,
+		Username:        username,
+		Email:           email,
+		AccountName:     account.AccountName,
+		AccountType:     account.AccountType,
+		AccountBalance:  account.Balance.String(),
+		AccountCurrency: account.Currency,
+		AccountStatus:   account.Status,
+		Balance:         newMoney(account.Balance, account.Currency),
+	}
+}
+
 // GetAccounts
 func (a *AccountService) ListAccounts(
 	ctx context.Context,
@@ -114,24 +200,42 @@ func (a *AccountService) ListAccounts(
 		return nil, status.Errorf(codes.Internal, "failed to get accounts: %v", err)
 	}
 
+	return listAccountsResponse(accounts), nil
+}
+
+// ListUserAccounts lists all accounts of a user
+func (a *AccountService) ListUserAccounts(
+	ctx context.Context,
+	request *dbankv1.ListUserAccountsRequest,
+) (*dbankv1.ListAccountsResponse, error) {
+	if request.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	// a user without accounts is listed empty, an unknown user is not found
+	if _, err := a.accountStore.GetUser(ctx, request.UserId); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get user: %v", err)
+	}
+
+	accounts, err := a.accountStore.ListUserAccounts(ctx, request.UserId)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "failed to list user accounts", "error", err, "user_id", request.UserId)
+		return nil, status.Errorf(status.Code(err), "failed to list user accounts: %v", err)
+	}
+
+	return listAccountsResponse(accounts), nil
+}
+
+func listAccountsResponse(accounts []*store.AccountDetails) *dbankv1.ListAccountsResponse {
 	var accountList []*dbankv1.GetAccountResponse
 	for _, account := range accounts {
-		accountList = append(accountList, &dbankv1.GetAccountResponse{
-			Id:              account.ID,
-			Username:        account.Username,
-			Email:           account.Email,
-			AccountName:     account.AccountName,
-			AccountType:     account.AccountType,
-			AccountBalance:  strconv.FormatFloat(account.Balance, 'f', 2, 64),
-			AccountCurrency: account.Currency,
-			AccountStatus:   account.Status,
-		})
+		accountList = append(accountList, accountResponse(account))
 	}
 
 	return &dbankv1.ListAccountsResponse{
 		Accounts:   accountList,
 		TotalCount: uint64(len(accountList)),
-	}, nil
+	}
 }
 
 func (a *AccountService) GetAccount(
@@ -149,19 +253,33 @@ func (a *AccountService) GetAccount(
 		return nil, status.Errorf(codes.NotFound, "account not found: %v", err)
 	}
 
-	// Format balance as string for the response
-	balanceStr := strconv.FormatFloat(account.Balance, 'f', 2, 64)
+	return accountResponse(account), nil
+}
 
+// accountResponse maps stored account details to their API representation
+func accountResponse(account *store.AccountDetails) *dbankv1.GetAccountResponse {
 	return &dbankv1.GetAccountResponse{
-		Id:              account.ID,
-		Username:        account.Username,
-		Email:           account.Email,
-		AccountName:     account.AccountName,
-		AccountType:     account.AccountType,
-		AccountBalance:  balanceStr,
-		AccountCurrency: account.Currency,
-		AccountStatus:   account.Status,
-	}, nil
+		Id:               account.ID,
+		UserId:           account.UserID,
+		Username:         account.Username,
+		Email:            account.Email,
+		AccountName:      account.AccountName,
+		AccountType:      account.AccountType,
+		AccountBalance:   account.Balance.String(),
+		AccountCurrency:  account.Currency,
+		AccountStatus:    account.Status,
+		HeldBalance:      account.HeldBalance.String(),
+		AvailableBalance: account.AvailableBalance.String(),
+		OverdraftLimit:   account.OverdraftLimit.String(),
+		OverdrawnSince:   formatOptionalTime(account.OverdrawnSince),
+		MaxOverdraft:     account.MaxOverdraft.String(),
+		Balance:          newMoney(account.Balance, account.Currency),
+		Held:             newMoney(account.HeldBalance, account.Currency),
+		Available:        newMoney(account.AvailableBalance, account.Currency),
+		Overdraft:        newMoney(account.OverdraftLimit, account.Currency),
+		DeepestOverdraft: newMoney(account.MaxOverdraft, account.Currency),
+		ClosedAt:         formatOptionalTime(account.ClosedAt),
+	}
 }
 
 func (a *AccountService) UpdateAccount(
@@ -176,7 +294,7 @@ func (a *AccountService) UpdateAccount(
 	existingAccount, err := a.accountStore.GetAccount(ctx, request.Id)
 	if err != nil {
 		a.logger.ErrorContext(ctx, "failed to get account for update", "error", err, "id", request.Id)
-		return nil, status.Errorf(codes.NotFound, "account not found: %v", err)
+		return nil, status.Errorf(status.Code(err), "failed to get account: %v", err)
 	}
 
 	// Prepare update data
@@ -185,27 +303,6 @@ func (a *AccountService) UpdateAccount(
 	}
 
 	// Update fields that are provided
-	if request.Username != "" {
-		updateData.Username = request.Username
-	} else {
-		updateData.Username = existingAccount.Username
-	}
-
-	if request.Email != "" {
-		updateData.Email = request.Email
-	} else {
-		updateData.Email = existingAccount.Email
-	}
-
-	if request.Password != "" {
-		hashedPassword, err := passw.HashPassword(request.Password)
-		if err != nil {
-			a.logger.ErrorContext(ctx, "failed to hash password", "error", err)
-			return nil, status.Errorf(codes.Internal, "failed to hash password")
-		}
-		updateData.Password = hashedPassword
-	}
-
 	if request.AccountName != "" {
 		updateData.AccountName = request.AccountName
 	} else {
@@ -218,51 +315,248 @@ func (a *AccountService) UpdateAccount(
 		updateData.AccountType = existingAccount.AccountType
 	}
 
-	if request.AccountBalance != "" {
-		balance, err := strconv.ParseFloat(request.AccountBalance, 64)
-		if err != nil {
-			a.logger.ErrorContext(ctx, "failed to parse balance", "error", err)
-			return nil, status.Errorf(codes.InvalidArgument, "invalid balance format")
-		}
-		updateData.Balance = balance
-	} else {
-		updateData.Balance = existingAccount.Balance
-	}
-
 	if request.AccountCurrency != "" {
+		if _, ok := currency.Lookup(request.AccountCurrency); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown currency %q", request.AccountCurrency)
+		}
 		updateData.Currency = request.AccountCurrency
 	} else {
 		updateData.Currency = existingAccount.Currency
 	}
 
-	if request.AccountStatus != "" {
-		updateData.Status = request.AccountStatus
-	} else {
-		updateData.Status = existingAccount.Status
+	balanceString := request.AccountBalance
+	if request.Balance != nil {
+		if request.AccountBalance != "" {
+			return nil, status.Errorf(codes.InvalidArgument, "balance cannot be combined with account_balance")
+		}
+		if request.Balance.CurrencyCode != updateData.Currency {
+			return nil, status.Errorf(codes.InvalidArgument, "balance currency %s does not match the account currency %s",
+				request.Balance.CurrencyCode, updateData.Currency)
+		}
+		balanceString = request.Balance.Amount
+	}
+
+	if balanceString != "" {
+		balance, err := parseBalance(balanceString)
+		if err != nil {
+			a.logger.ErrorContext(ctx, "failed to parse balance", "error", err)
+			return nil, err
+		}
+		if err = validateMoney(balance, updateData.Currency); err != nil {
+			return nil, err
+		}
+		updateData.Balance = &balance
+	}
+
+	// A new status goes through the account state machine and is audited
+	if request.AccountStatus != "" && request.AccountStatus != existingAccount.Status {
+		if !slices.Contains(store.AccountStatuses, request.AccountStatus) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown account status %q", request.AccountStatus)
+		}
+		if request.AccountStatus == store.AccountStatusClosed {
+			return nil, status.Errorf(codes.InvalidArgument, "accounts are closed with CloseAccount")
+		}
+		if request.StatusReason == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "status_reason is required to change the account status")
+		}
+		updateData.StatusChange = &store.AccountStatusChange{
+			AccountID: existingAccount.ID,
+			Status:    request.AccountStatus,
+			Reason:    request.StatusReason,
+			Actor:     request.Actor,
+		}
 	}
 
 	// Update the account
 	updatedAccount, err := a.accountStore.UpdateAccount(ctx, updateData)
 	if err != nil {
 		a.logger.ErrorContext(ctx, "failed to update account", "error", err, "id", request.Id)
-		return nil, status.Errorf(codes.Internal, "failed to update account: %v", err)
+		return nil, status.Errorf(status.Code(err), "failed to update account: %v", err)
 	}
 
-	// Format balance for response
-	balanceStr := strconv.FormatFloat(updatedAccount.Balance, 'f', 2, 64)
-
 	return &dbankv1.UpdateAccountResponse{
 		Id:              updatedAccount.ID,
+		UserId:          updatedAccount.UserID,
 		Username:        updatedAccount.Username,
 		Email:           updatedAccount.Email,
 		AccountName:     updatedAccount.AccountName,
 		AccountType:     updatedAccount.AccountType,
-		AccountBalance:  balanceStr,
+		AccountBalance:  updatedAccount.Balance.String(),
 		AccountCurrency: updatedAccount.Currency,
 		AccountStatus:   updatedAccount.Status,
+		Balance:         newMoney(updatedAccount.Balance, updatedAccount.Currency),
 	}, nil
 }
 
+// FreezeAccount stops all money movements of an account and audits the change
+func (a *AccountService) FreezeAccount(
+	ctx context.Context,
+	request *dbankv1.AccountStatusRequest,
+) (*dbankv1.AccountStatusChange, error) {
+	return a.changeStatus(ctx, "freeze", a.accountStore.FreezeAccount,
+		request.AccountId, request.Reason, request.Actor)
+}
+
+// UnfreezeAccount makes a frozen account active again and audits the change
+func (a *AccountService) UnfreezeAccount(
+	ctx context.Context,
+	request *dbankv1.AccountStatusRequest,
+) (*dbankv1.AccountStatusChange, error) {
+	return a.changeStatus(ctx, "unfreeze", a.accountStore.UnfreezeAccount,
+		request.AccountId, request.Reason, request.Actor)
+}
+
+// CloseAccount sweeps the balance of an account to the nominated account or
+// the suspense account, closes the account and returns its final statement
+func (a *AccountService) CloseAccount(
+	ctx context.Context,
+	request *dbankv1.CloseAccountRequest,
+) (*dbankv1.CloseAccountResponse, error) {
+	a.logger.InfoContext(ctx, "Closing account",
+		"account_id", request.AccountId,
+		"sweep_to_account_id", request.SweepToAccountId,
+		"actor", request.Actor,
+	)
+
+	if request.AccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	if request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	return a.closeAccount(ctx, &store.AccountClosure{
+		AccountStatusChange: store.AccountStatusChange{
+			AccountID: request.AccountId,
+			Reason:    request.Reason,
+			Actor:     request.Actor,
+		},
+		SweepToAccountID: request.SweepToAccountId,
+	})
+}
+
+// closeAccount closes an account, publishes an account.closed event and
+// reads the statement of the account from its opening to its closure
+func (a *AccountService) closeAccount(
+	ctx context.Context,
+	closure *store.AccountClosure,
+) (*dbankv1.CloseAccountResponse, error) {
+	if err := a.accountStore.CloseAccount(ctx, closure); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to close account: %v", err)
+	}
+
+	a.publishClosed(ctx, closure)
+
+	response := &dbankv1.CloseAccountResponse{
+		AccountId:      closure.AccountID,
+		Status:         closure.Status,
+		PreviousStatus: closure.PreviousStatus,
+		Reason:         closure.Reason,
+		Actor:          closure.Actor,
+		AuditLogId:     closure.AuditLogID,
+		ClosedAt:       closure.ClosedAt.Format(time.RFC3339),
+	}
+	if sweep := closure.Sweep; sweep != nil {
+		response.SweepTransactionId = sweep.TransactionID
+		response.Swept = newMoney(sweep.Amount, sweep.Currency)
+		response.SweptToAccountId = sweep.ToAccountID
+	}
+
+	// the statement period ends after the postings of the closure, which
+	// carry the closing time itself
+	statement, err := a.accountStore.GetStatement(ctx, closure.AccountID,
+		closure.OpenedAt, closure.ClosedAt.Add(time.Microsecond))
+	if err != nil {
+		a.logger.ErrorContext(ctx, "failed to get final statement", "error", err, "account_id", closure.AccountID)
+		return nil, status.Errorf(status.Code(err), "account closed, failed to get final statement: %v", err)
+	}
+	response.FinalStatement = statementResponse(statement)
+
+	return response, nil
+}
+
+// publishClosed publishes the account.closed event of a closure, and the
+// interest and sweep it posted as transactions so that the ledger
+// projection records them
+func (a *AccountService) publishClosed(ctx context.Context, closure *store.AccountClosure) {
+	if a.rabbitmqClient == nil {
+		return
+	}
+
+	if closure.Interest != nil {
+		publishPosted(ctx, a.logger, a.rabbitmqClient, closure.Interest)
+	}
+	if closure.Sweep != nil {
+		publishPosted(ctx, a.logger, a.rabbitmqClient, &store.PostedTransaction{
+			Transaction: closure.Sweep,
+			Entries:     closure.Entries,
+		})
+	}
+
+	event := &amqpx.AccountClosedEvent{
+		AccountID:      closure.AccountID,
+		PreviousStatus: closure.PreviousStatus,
+		Reason:         closure.Reason,
+		Actor:          closure.Actor,
+		Timestamp:      closure.ClosedAt.Unix(),
+	}
+	if sweep := closure.Sweep; sweep != nil {
+		event.SweepTransactionID = sweep.TransactionID
+		event.SweptAmount = sweep.Amount.String()
+		event.SweptToAccountID = sweep.ToAccountID
+		event.Currency = sweep.Currency
+		event.Postings = ledgerPostings(closure.Entries)
+	}
+
+	if err := a.rabbitmqClient.PublishEvent(ctx, amqpx.AccountExchange, amqpx.AccountClosedRoute, event); err != nil {
+		a.logger.WarnContext(ctx, "Failed to publish account closed event", "error", err)
+	}
+}
+
+// changeStatus validates a status change request and makes it with change
+func (a *AccountService) changeStatus(
+	ctx context.Context,
+	operation string,
+	change func(ctx context.Context, change *store.AccountStatusChange) error,
+	accountID, reason, actor string,
+) (*dbankv1.AccountStatusChange, error) {
+	a.logger.InfoContext(ctx, "Changing account status",
+		"operation", operation,
+		"account_id", accountID,
+		"actor", actor,
+	)
+
+	if accountID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	if reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	statusChange := &store.AccountStatusChange{
+		AccountID: accountID,
+		Reason:    reason,
+		Actor:     actor,
+	}
+	if err := change(ctx, statusChange); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to %s account: %v", operation, err)
+	}
+
+	return &dbankv1.AccountStatusChange{
+		AccountId:      statusChange.AccountID,
+		Status:         statusChange.Status,
+		PreviousStatus: statusChange.PreviousStatus,
+		Reason:         statusChange.Reason,
+		Actor:          statusChange.Actor,
+		AuditLogId:     statusChange.AuditLogID,
+	}, nil
+}
+
+// DeleteAccount closes an account, its balance is swept to the suspense
+// account. Accounts are closed rather than deleted so that they and
+// their history stay readable.
 func (a *AccountService) DeleteAccount(
 	ctx context.Context,
 	request *dbankv1.DeleteAccountRequest,
@@ -272,21 +566,50 @@ func (a *AccountService) DeleteAccount(
 	}
 
 	// First check if the account exists
-	_, err := a.accountStore.GetAccount(ctx, request.Id)
+	account, err := a.accountStore.GetAccount(ctx, request.Id)
 	if err != nil {
 		a.logger.ErrorContext(ctx, "failed to get account for deletion", "error", err, "id", request.Id)
 		return nil, status.Errorf(codes.NotFound, "account not found: %v", err)
 	}
 
-	// Delete the account
-	err = a.accountStore.DeleteAccount(ctx, request.Id)
+	_, err = a.closeAccount(ctx, &store.AccountClosure{
+		AccountStatusChange: store.AccountStatusChange{
+			AccountID: account.ID,
+			Reason:    "deleted with DeleteAccount",
+		},
+	})
 	if err != nil {
-		a.logger.ErrorContext(ctx, "failed to delete account", "error", err, "id", request.Id)
-		return nil, status.Errorf(codes.Internal, "failed to delete account: %v", err)
+		return nil, err
 	}
 
 	return &dbankv1.DeleteAccountResponse{
 		Id:      request.Id,
-		Message: "Account successfully deleted",
+		Message: "Account successfully closed",
 	}, nil
 }
+
+// balanceScale is the number of decimal places the balance columns store
+const balanceScale = 6
+
+// parseBalance parses a balance without rounding, a balance with more
+// decimal places than the columns store is refused
+func parseBalance(value string) (decimal.Decimal, error) {
+	balance, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "invalid balance format")
+	}
+
+	if !balance.Equal(balance.Truncate(balanceScale)) {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "balance has more than %d decimal places", balanceScale)
+	}
+
+	return balance, nil
+}
+
+// formatOptionalTime formats t as RFC 3339, or returns an empty string for nil
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseBalance(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		code  codes.Code
+	}{
+		{value: "100", want: "100"},
+		{value: "0.1", want: "0.1"},
+		// float64 rounds these, the last one to 9007199254740992
+		{value: "12345678901234.123457", want: "12345678901234.123457"},
+		{value: "9007199254740993", want: "9007199254740993"},
+		{value: "0.000001", want: "0.000001"},
+		{value: "1.5000000", want: "1.5"},
+		{value: "0.0000001", code: codes.InvalidArgument},
+		{value: "abc", code: codes.InvalidArgument},
+		{value: "", code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBalance(tt.value)
+			if status.Code(err) != tt.code {
+				t.Fatalf("parseBalance(%q) error = %v, want %v", tt.value, err, tt.code)
+			}
+			if err == nil && !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("parseBalance(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/fx"
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+	"github.com/amjadjibon/dbank/pkg/currency"
+)
+
+// AdminService manages the bank's reference data and account facilities
+type AdminService struct {
+	logger      *slog.Logger
+	adminStore  *store.Store
+	fxRefresher *fx.Refresher
+	dbankv1.UnimplementedAdminServiceServer
+}
+
+// NewAdminService creates a new admin service. fxRefresher is run after a
+// rate is entered, it may be nil to leave that to the scheduled refresh.
+func NewAdminService(
+	logger *slog.Logger,
+	adminStore *store.Store,
+	fxRefresher *fx.Refresher,
+) *AdminService {
+	return &AdminService{
+		logger:      logger,
+		adminStore:  adminStore,
+		fxRefresher: fxRefresher,
+	}
+}
+
+// Ensure Service implements the AdminServiceServer interface
+var _ dbankv1.AdminServiceServer = (*AdminService)(nil)
+
+// SetFXRate enters a rate for the admin FX rate provider
+func (a *AdminService) SetFXRate(
+	ctx context.Context,
+	request *dbankv1.SetFXRateRequest,
+) (*dbankv1.SetFXRateResponse, error) {
+	a.logger.InfoContext(ctx, "Setting FX rate",
+		"base_currency", request.BaseCurrency,
+		"quote_currency", request.QuoteCurrency,
+		"rate", request.Rate,
+	)
+
+	if request.BaseCurrency == "" || request.QuoteCurrency == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "base_currency and quote_currency are required")
+	}
+
+	if request.BaseCurrency == request.QuoteCurrency {
+		return nil, status.Errorf(codes.InvalidArgument, "base_currency and quote_currency must differ")
+	}
+
+	for _, code := range []string{request.BaseCurrency, request.QuoteCurrency} {
+		if _, ok := currency.Lookup(code); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown currency %q", code)
+		}
+	}
+
+	rate, err := decimal.NewFromString(request.Rate)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid rate format: %v", err)
+	}
+
+	if !rate.IsPositive() {
+		return nil, status.Errorf(codes.InvalidArgument, "rate must be positive")
+	}
+
+	spread := decimal.Zero
+	if request.Spread != "" {
+		spread, err = decimal.NewFromString(request.Spread)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid spread format: %v", err)
+		}
+
+		if spread.IsNegative() || spread.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+			return nil, status.Errorf(codes.InvalidArgument, "spread must be at least 0 and below 1")
+		}
+	}
+
+	effectiveAt := time.Now()
+	if request.EffectiveAt != "" {
+		effectiveAt, err = time.Parse(time.RFC3339, request.EffectiveAt)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid effective_at format: %v", err)
+		}
+	}
+
+	fxRate := &store.FXRate{
+		BaseCurrency:  request.BaseCurrency,
+		QuoteCurrency: request.QuoteCurrency,
+		Rate:          rate,
+		Spread:        spread,
+		EffectiveAt:   effectiveAt,
+	}
+	if err = a.adminStore.SetManualFXRate(ctx, fxRate); err != nil {
+		a.logger.ErrorContext(ctx, "failed to set fx rate", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to set fx rate: %v", err)
+	}
+
+	response := &dbankv1.SetFXRateResponse{
+		Id:            fxRate.ID,
+		BaseCurrency:  fxRate.BaseCurrency,
+		QuoteCurrency: fxRate.QuoteCurrency,
+		Rate:          fxRate.Rate.String(),
+		Spread:        fxRate.Spread.String(),
+		EffectiveAt:   fxRate.EffectiveAt.Format(time.RFC3339),
+	}
+
+	// The rate is stored either way, a failed refresh only delays it
+	// until the next scheduled one
+	if a.fxRefresher != nil {
+		snapshotID, err := a.fxRefresher.Refresh(ctx)
+		if err != nil {
+			a.logger.WarnContext(ctx, "failed to refresh fx rates", "error", err)
+		}
+		response.SnapshotId = snapshotID
+	}
+
+	return response, nil
+}
+
+// SetOverdraftLimit changes the overdraft limit of an account and audits the change
+func (a *AdminService) SetOverdraftLimit(
+	ctx context.Context,
+	request *dbankv1.SetOverdraftLimitRequest,
+) (*dbankv1.SetOverdraftLimitResponse, error) {
+	a.logger.InfoContext(ctx, "Setting overdraft limit",
+		"account_id", request.AccountId,
+		"limit", request.Limit,
+		"actor", request.Actor,
+	)
+
+	if request.AccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	limit, err := decimal.NewFromString(request.Limit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit format: %v", err)
+	}
+
+	if limit.IsNegative() {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	if request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	change := &store.OverdraftLimitChange{
+		AccountID: request.AccountId,
+		Limit:     limit,
+		Reason:    request.Reason,
+		Actor:     request.Actor,
+	}
+	if err = a.adminStore.SetOverdraftLimit(ctx, change); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to set overdraft limit: %v", err)
+	}
+
+	return &dbankv1.SetOverdraftLimitResponse{
+		AccountId:     change.AccountID,
+		Limit:         change.Limit.String(),
+		PreviousLimit: change.PreviousLimit.String(),
+		AuditLogId:    change.AuditLogID,
+	}, nil
+}
+
+// SetInterestPlan creates or replaces the interest plan of an account type
+func (a *AdminService) SetInterestPlan(
+	ctx context.Context,
+	request *dbankv1.SetInterestPlanRequest,
+) (*dbankv1.InterestPlan, error) {
+	a.logger.InfoContext(ctx, "Setting interest plan",
+		"account_type", request.AccountType,
+		"day_count", request.DayCount,
+		"tiers", len(request.Tiers),
+	)
+
+	if request.AccountType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_type is required")
+	}
+
+	if !slices.Contains(store.DayCounts, request.DayCount) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"day_count must be one of %s", strings.Join(store.DayCounts, ", "))
+	}
+
+	if len(request.Tiers) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "at least one tier is required")
+	}
+
+	plan := &store.InterestPlan{
+		AccountType: request.AccountType,
+		DayCount:    request.DayCount,
+	}
+	for _, requestTier := range request.Tiers {
+		minBalance, err := decimal.NewFromString(requestTier.MinBalance)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid min_balance format: %v", err)
+		}
+
+		if minBalance.IsNegative() {
+			return nil, status.Errorf(codes.InvalidArgument, "min_balance must not be negative")
+		}
+
+		annualRate, err := decimal.NewFromString(requestTier.AnnualRate)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid annual_rate format: %v", err)
+		}
+
+		if annualRate.IsNegative() {
+			return nil, status.Errorf(codes.InvalidArgument, "annual_rate must not be negative")
+		}
+
+		for _, tier := range plan.Tiers {
+			if tier.MinBalance.Equal(minBalance) {
+				return nil, status.Errorf(codes.InvalidArgument, "tiers must have different min_balance")
+			}
+		}
+
+		plan.Tiers = append(plan.Tiers, &store.InterestTier{MinBalance: minBalance, AnnualRate: annualRate})
+	}
+
+	if err := a.adminStore.SetInterestPlan(ctx, plan); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to set interest plan: %v", err)
+	}
+
+	response := &dbankv1.InterestPlan{
+		AccountType: plan.AccountType,
+		DayCount:    plan.DayCount,
+		UpdatedAt:   plan.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, tier := range plan.Tiers {
+		response.Tiers = append(response.Tiers, &dbankv1.InterestTier{
+			MinBalance: tier.MinBalance.String(),
+			AnnualRate: tier.AnnualRate.String(),
+		})
+	}
+
+	return response, nil
+}
+
+// SetFeeSchedule creates or replaces a fee schedule
+func (a *AdminService) SetFeeSchedule(
+	ctx context.Context,
+	request *dbankv1.SetFeeScheduleRequest,
+) (*dbankv1.FeeSchedule, error) {
+	a.logger.InfoContext(ctx, "Setting fee schedule",
+		"transaction_type", request.TransactionType,
+		"account_type", request.AccountType,
+		"currency", request.Currency,
+		"fee_type", request.FeeType,
+	)
+
+	if request.TransactionType == "" || request.Currency == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "transaction_type and currency are required")
+	}
+
+	if _, ok := currency.Lookup(request.Currency); !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown currency %q", request.Currency)
+	}
+
+	if !slices.Contains(store.FeeTypes, request.FeeType) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"fee_type must be one of %s", strings.Join(store.FeeTypes, ", "))
+	}
+
+	schedule := &store.FeeSchedule{
+		TransactionType: request.TransactionType,
+		AccountType:     request.AccountType,
+		Currency:        request.Currency,
+		FeeType:         request.FeeType,
+	}
+
+	var err error
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *decimal.Decimal
+	}{
+		{"flat_amount", request.FlatAmount, &schedule.FlatAmount},
+		{"rate", request.Rate, &schedule.Rate},
+		{"min_fee", request.MinFee, &schedule.MinFee},
+		{"max_fee", request.MaxFee, &schedule.MaxFee},
+	} {
+		if *field.dest, err = parseFeeAmount(field.name, field.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.MaxFee.IsPositive() && schedule.MaxFee.LessThan(schedule.MinFee) {
+		return nil, status.Errorf(codes.InvalidArgument, "max_fee must not be below min_fee")
+	}
+
+	for _, requestTier := range request.Tiers {
+		tier := &store.FeeTier{}
+		if tier.MinAmount, err = parseFeeAmount("min_amount", requestTier.MinAmount); err != nil {
+			return nil, err
+		}
+		if tier.FlatAmount, err = parseFeeAmount("flat_amount", requestTier.FlatAmount); err != nil {
+			return nil, err
+		}
+		if tier.Rate, err = parseFeeAmount("rate", requestTier.Rate); err != nil {
+			return nil, err
+		}
+		schedule.Tiers = append(schedule.Tiers, tier)
+	}
+
+	switch schedule.FeeType {
+	case store.FeeTypeFlat:
+		if !schedule.FlatAmount.IsPositive() {
+			return nil, status.Errorf(codes.InvalidArgument, "flat schedules need a positive flat_amount")
+		}
+	case store.FeeTypePercentage:
+		if !schedule.Rate.IsPositive() {
+			return nil, status.Errorf(codes.InvalidArgument, "percentage schedules need a positive rate")
+		}
+	case store.FeeTypeTiered:
+		if len(schedule.Tiers) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "tiered schedules need at least one tier")
+		}
+	}
+
+	if err = a.adminStore.SetFeeSchedule(ctx, schedule); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to set fee schedule: %v", err)
+	}
+
+	response := &dbankv1.FeeSchedule{
+		Id:              schedule.ID,
+		TransactionType: schedule.TransactionType,
+		AccountType:     schedule.AccountType,
+		Currency:        schedule.Currency,
+		FeeType:         schedule.FeeType,
+		FlatAmount:      schedule.FlatAmount.String(),
+		Rate:            schedule.Rate.String(),
+		MinFee:          schedule.MinFee.String(),
+		MaxFee:          schedule.MaxFee.String(),
+		UpdatedAt:       schedule.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, tier := range schedule.Tiers {
+		response.Tiers = append(response.Tiers, &dbankv1.FeeTier{
+			MinAmount:  tier.MinAmount.String(),
+			FlatAmount: tier.FlatAmount.String(),
+			Rate:       tier.Rate.String(),
+		})
+	}
+
+	return response, nil
+}
+
+// parseFeeAmount parses an optional, non-negative amount or rate of a fee
+// schedule, empty is zero
+func parseFeeAmount(name, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "invalid %s format: %v", name, err)
+	}
+
+	if amount.IsNegative() {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "%s must not be negative", name)
+	}
+
+	return amount, nil
+}
+
+// SetTransferLimit creates or replaces the transfer limits of a scope and currency
+func (a *AdminService) SetTransferLimit(
+	ctx context.Context,
+	request *dbankv1.SetTransferLimitRequest,
+) (*dbankv1.TransferLimit, error) {
+	a.logger.InfoContext(ctx, "Setting transfer limit",
+		"scope", request.Scope,
+		"scope_value", request.ScopeValue,
+		"currency", request.Currency,
+	)
+
+	if !slices.Contains(store.LimitScopes, request.Scope) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"scope must be one of %s", strings.Join(store.LimitScopes, ", "))
+	}
+
+	if request.ScopeValue == "" || request.Currency == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "scope_value and currency are required")
+	}
+
+	if _, ok := currency.Lookup(request.Currency); !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown currency %q", request.Currency)
+	}
+
+	limit := &store.TransferLimit{
+		Scope:      request.Scope,
+		ScopeValue: request.ScopeValue,
+		Currency:   request.Currency,
+	}
+
+	var err error
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *decimal.NullDecimal
+	}{
+		{"per_transaction_max", request.PerTransactionMax, &limit.PerTransactionMax},
+		{"daily_amount", request.DailyAmount, &limit.DailyAmount},
+		{"monthly_amount", request.MonthlyAmount, &limit.MonthlyAmount},
+	} {
+		if *field.dest, err = parseLimitAmount(field.name, field.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.DailyCount != nil {
+		if *request.DailyCount < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "daily_count must not be negative")
+		}
+		dailyCount := int(*request.DailyCount)
+		limit.DailyCount = &dailyCount
+	}
+
+	if !limit.PerTransactionMax.Valid && !limit.DailyAmount.Valid &&
+		!limit.MonthlyAmount.Valid && limit.DailyCount == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "at least one limit is required")
+	}
+
+	if err = a.adminStore.SetTransferLimit(ctx, limit); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to set transfer limit: %v", err)
+	}
+
+	response := &dbankv1.TransferLimit{
+		Id:         limit.ID,
+		Scope:      limit.Scope,
+		ScopeValue: limit.ScopeValue,
+		Currency:   limit.Currency,
+		UpdatedAt:  limit.UpdatedAt.Format(time.RFC3339),
+	}
+	if limit.PerTransactionMax.Valid {
+		response.PerTransactionMax = limit.PerTransactionMax.Decimal.String()
+	}
+	if limit.DailyAmount.Valid {
+		response.DailyAmount = limit.DailyAmount.Decimal.String()
+	}
+	if limit.MonthlyAmount.Valid {
+		response.MonthlyAmount = limit.MonthlyAmount.Decimal.String()
+	}
+	if limit.DailyCount != nil {
+		dailyCount := int32(*limit.DailyCount)
+		response.DailyCount = &dailyCount
+	}
+
+	return response, nil
+}
+
+// parseLimitAmount parses an optional, non-negative transfer limit, empty
+// leaves the limit unset
+func parseLimitAmount(name, value string) (decimal.NullDecimal, error) {
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, status.Errorf(codes.InvalidArgument, "invalid %s format: %v", name, err)
+	}
+
+	if amount.IsNegative() {
+		return decimal.NullDecimal{}, status.Errorf(codes.InvalidArgument, "%s must not be negative", name)
+	}
+
+	return decimal.NewNullDecimal(amount), nil
+}
+
+// SetKYCTier changes the KYC tier of a user and audits the change
+func (a *AdminService) SetKYCTier(
+	ctx context.Context,
+	request *dbankv1.SetKYCTierRequest,
+) (*dbankv1.SetKYCTierResponse, error) {
+	a.logger.InfoContext(ctx, "Setting KYC tier",
+		"user_id", request.UserId,
+		"tier", request.Tier,
+		"actor", request.Actor,
+	)
+
+	if request.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	if request.Tier == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "tier is required")
+	}
+
+	if request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	change := &store.KYCTierChange{
+		UserID: request.UserId,
+		Tier:   request.Tier,
+		Reason: request.Reason,
+		Actor:  request.Actor,
+	}
+	if err := a.adminStore.SetKYCTier(ctx, change); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to set kyc tier: %v", err)
+	}
+
+	return &dbankv1.SetKYCTierResponse{
+		UserId:       change.UserID,
+		Tier:         change.Tier,
+		PreviousTier: change.PreviousTier,
+		AuditLogId:   change.AuditLogID,
+	}, nil
+}
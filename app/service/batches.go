@@ -0,0 +1,494 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/amjadjibon/dbank/app/batch"
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+	"github.com/amjadjibon/dbank/pkg/amqpx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+const (
+	// maxBatchFileSize is the largest payment file accepted by Upload
+	maxBatchFileSize = 10 << 20
+
+	// batchStaleAfter is how long a batch can stay processing before another
+	// worker takes it over from a worker that stopped
+	batchStaleAfter = 15 * time.Minute
+)
+
+// BatchService takes payment files and runs the worker that executes their
+// rows as transfers
+type BatchService struct {
+	logger              *slog.Logger
+	batchStore          *store.Store
+	transactionsService *TransactionService
+	rabbitmqClient      *amqpx.RabbitMQClient
+	wake                chan struct{}
+	dbankv1.UnimplementedBatchServiceServer
+}
+
+// NewBatchService creates a new batch service, the rows of a batch are
+// executed through the CreateTransaction of transactionsService
+func NewBatchService(
+	logger *slog.Logger,
+	batchStore *store.Store,
+	transactionsService *TransactionService,
+	rabbitmqClient *amqpx.RabbitMQClient,
+) *BatchService {
+	return &BatchService{
+		logger:              logger,
+		batchStore:          batchStore,
+		transactionsService: transactionsService,
+		rabbitmqClient:      rabbitmqClient,
+		wake:                make(chan struct{}, 1),
+	}
+}
+
+// Ensure Service implements the BatchServiceServer interface
+var _ dbankv1.BatchServiceServer = (*BatchService)(nil)
+
+// CreateBatch validates the rows of a payment file and stores them for the
+// worker. Invalid rows are kept with their error, the file is only refused
+// when it cannot be read.
+func (s *BatchService) CreateBatch(
+	ctx context.Context,
+	request *dbankv1.CreateBatchRequest,
+) (*dbankv1.Batch, error) {
+	s.logger.InfoContext(ctx, "Creating batch",
+		"from_account_id", request.FromAccountId,
+		"mode", request.Mode,
+		"format", request.Format,
+		"file_name", request.FileName,
+	)
+
+	if !slices.Contains(store.BatchModes, request.Mode) {
+		return nil, status.Errorf(codes.InvalidArgument, "mode must be %q or %q",
+			store.BatchModeAllOrNothing, store.BatchModeBestEffort)
+	}
+
+	format := request.Format
+	if format == "" {
+		format = batch.DetectFormat(request.FileName, request.Content)
+	}
+	if !slices.Contains(batch.Formats, format) {
+		return nil, status.Errorf(codes.InvalidArgument, "format must be %q or %q",
+			batch.FormatCSV, batch.FormatPain001)
+	}
+
+	file, err := batch.Parse(format, bytes.NewReader(request.Content))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payment file: %v", err)
+	}
+
+	fromAccountID := request.FromAccountId
+	if file.FromAccountID != "" {
+		if fromAccountID != "" && fromAccountID != file.FromAccountID {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"from_account_id %s does not match the debtor account %s of the file",
+				fromAccountID, file.FromAccountID)
+		}
+		fromAccountID = file.FromAccountID
+	}
+
+	if fromAccountID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "from_account_id is required")
+	}
+
+	if uuid.Validate(fromAccountID) != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from_account_id %q", fromAccountID)
+	}
+
+	b := &store.Batch{
+		ID:            idx.UUID4(),
+		FromAccountID: fromAccountID,
+		Mode:          request.Mode,
+		Format:        format,
+		FileName:      request.FileName,
+		Rows:          make([]*store.BatchRow, 0, len(file.Payments)),
+	}
+	for _, payment := range file.Payments {
+		row := &store.BatchRow{
+			Number:      payment.Number,
+			ToAccountID: payment.ToAccountID,
+			Amount:      payment.Amount,
+			Currency:    payment.Currency,
+			Description: payment.Description,
+			Reference:   payment.Reference,
+			Status:      store.BatchRowPending,
+		}
+		if err := validateBatchRow(fromAccountID, payment); err != nil {
+			row.Status = store.BatchRowInvalid
+			row.Error = status.Convert(err).Message()
+		}
+		b.Rows = append(b.Rows, row)
+	}
+
+	if err = s.batchStore.CreateBatch(ctx, b); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to create batch: %v", err)
+	}
+
+	s.Wake()
+
+	return batchResponse(b), nil
+}
+
+// validateBatchRow validates a payment of a file as a transfer request
+func validateBatchRow(fromAccountID string, payment *batch.Payment) error {
+	if payment.ToAccountID != "" && uuid.Validate(payment.ToAccountID) != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid to_account_id %q", payment.ToAccountID)
+	}
+
+	_, err := validateTransferRequest(fromAccountID, payment.ToAccountID, payment.Amount, payment.Currency)
+	return err
+}
+
+// Upload creates a batch from a multipart/form-data request, with the payment
+// file in the "file" part and the from_account_id, mode and format fields of
+// CreateBatch. The format is detected from the file when it is not given.
+func (s *BatchService) Upload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchFileSize)
+	if err := r.ParseMultipartForm(maxBatchFileSize); err != nil {
+		http.Error(w, fmt.Sprintf("invalid multipart form: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.CreateBatch(ctx, &dbankv1.CreateBatchRequest{
+		FromAccountId: r.FormValue("from_account_id"),
+		Mode:          r.FormValue("mode"),
+		Format:        r.FormValue("format"),
+		FileName:      header.Filename,
+		Content:       content,
+	})
+	if err != nil {
+		st := status.Convert(err)
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+
+	// Render the batch the way the gateway renders CreateBatch
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to marshal batch", "error", err)
+		http.Error(w, "failed to marshal batch", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// GetBatch returns a batch and the result of its rows
+func (s *BatchService) GetBatch(
+	ctx context.Context,
+	request *dbankv1.GetBatchRequest,
+) (*dbankv1.Batch, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	b, err := s.batchStore.GetBatch(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get batch: %v", err)
+	}
+
+	return batchResponse(b), nil
+}
+
+// Wake makes the worker look for pending batches now instead of at its next tick
+func (s *BatchService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunWorker processes the pending batches every interval, or as soon as one
+// is created, until ctx is done. Several workers, also in different
+// processes, can run at the same time.
+func (s *BatchService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// RunPending processes the pending batches, one batch at a time
+func (s *BatchService) RunPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		b, err := s.batchStore.ClaimBatch(ctx, batchStaleAfter)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to claim batch", "error", err)
+			return
+		}
+		if b == nil {
+			return
+		}
+
+		if err = s.processBatch(ctx, b); err != nil {
+			s.logger.ErrorContext(ctx, "failed to process batch", "id", b.ID, "error", err)
+			return
+		}
+	}
+}
+
+// processBatch executes the pending rows of a claimed batch in file order.
+// The rows of an all_or_nothing batch are executed together, see executeBatch.
+// Rows that already have a result, from a worker that stopped, keep it.
+func (s *BatchService) processBatch(ctx context.Context, b *store.Batch) error {
+	s.logger.InfoContext(ctx, "Processing batch",
+		"id", b.ID,
+		"mode", b.Mode,
+		"rows", len(b.Rows),
+	)
+
+	var failure string
+	if b.Mode == store.BatchModeAllOrNothing {
+		var rows []*store.BatchRow
+		rows, failure = s.executeBatch(ctx, b)
+		for _, row := range rows {
+			if err := s.batchStore.SaveBatchRow(ctx, b, row); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, row := range b.Rows {
+			if row.Status != store.BatchRowPending {
+				continue
+			}
+
+			s.executeRow(ctx, b, row)
+			if err := s.batchStore.SaveBatchRow(ctx, b, row); err != nil {
+				return err
+			}
+		}
+	}
+
+	batchStatus := store.BatchStatusCompleted
+	if failure != "" {
+		batchStatus = store.BatchStatusFailed
+	}
+
+	if err := s.batchStore.CompleteBatch(ctx, b, batchStatus, failure); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Processed batch",
+		"id", b.ID,
+		"status", b.Status,
+		"succeeded", b.Succeeded,
+		"in_review", b.InReview,
+		"failed", b.Failed,
+	)
+
+	s.publishBatch(ctx, b)
+	return nil
+}
+
+// batchFailure describes the first invalid or failed row, empty when there is none
+func batchFailure(rows []*store.BatchRow) string {
+	for _, row := range rows {
+		switch row.Status {
+		case store.BatchRowInvalid:
+			return fmt.Sprintf("row %d is invalid: %s", row.Number, row.Error)
+		case store.BatchRowFailed:
+			return fmt.Sprintf("row %d failed: %s", row.Number, row.Error)
+		}
+	}
+	return ""
+}
+
+// executeBatch moves the money of the pending rows of an all_or_nothing batch
+// in one database transaction, so that either every row moves money or none
+// does. When a row is invalid or fails, it is the failure of the batch and the
+// other pending rows are skipped. It returns the rows it changed.
+func (s *BatchService) executeBatch(ctx context.Context, b *store.Batch) ([]*store.BatchRow, string) {
+	var rows []*store.BatchRow
+	for _, row := range b.Rows {
+		if row.Status == store.BatchRowPending {
+			rows = append(rows, row)
+		}
+	}
+
+	failure := batchFailure(b.Rows)
+	if failure == "" && len(rows) > 0 {
+		requests := make([]*dbankv1.CreateTransactionRequest, 0, len(rows))
+		for _, row := range rows {
+			requests = append(requests, rowRequest(b, row))
+		}
+
+		responses, failed, err := s.transactionsService.executeTransactions(ctx, requests)
+		if err == nil {
+			for i, row := range rows {
+				row.Status = responses[i].Status
+				row.TransactionID = responses[i].Id
+			}
+			return rows, ""
+		}
+
+		if failed >= 0 {
+			rows[failed].Status = store.BatchRowFailed
+			rows[failed].Error = status.Convert(err).Message()
+			failure = batchFailure(rows[failed : failed+1])
+		} else {
+			failure = status.Convert(err).Message()
+		}
+	}
+
+	for _, row := range rows {
+		if row.Status == store.BatchRowPending {
+			row.Status = store.BatchRowSkipped
+		}
+	}
+	return rows, failure
+}
+
+// executeRow moves the money of a row like CreateTransaction
+func (s *BatchService) executeRow(ctx context.Context, b *store.Batch, row *store.BatchRow) {
+	request := rowRequest(b, row)
+	response, err := s.transactionsService.createTransaction(ctx, request, request.IdempotencyKey)
+	if err != nil {
+		row.Status = store.BatchRowFailed
+		row.Error = status.Convert(err).Message()
+		return
+	}
+
+	row.Status = response.Status
+	row.TransactionID = response.Id
+}
+
+// rowRequest is the transfer of a row. The idempotency key is derived from
+// the row under the internal prefix, so executing the same row again replays
+// the first transfer instead of paying twice.
+func rowRequest(b *store.Batch, row *store.BatchRow) *dbankv1.CreateTransactionRequest {
+	description := row.Description
+	if description == "" {
+		description = row.Reference
+	}
+
+	return &dbankv1.CreateTransactionRequest{
+		FromAccountId:   b.FromAccountID,
+		ToAccountId:     row.ToAccountID,
+		TransactionType: store.TransactionTypeTransfer,
+		Amount:          row.Amount,
+		Currency:        row.Currency,
+		Description:     description,
+		IdempotencyKey:  internalIdempotencyKey("batch:%s:%d", b.ID, row.Number),
+	}
+}
+
+// publishBatch publishes the summary of a processed batch
+func (s *BatchService) publishBatch(ctx context.Context, b *store.Batch) {
+	if s.rabbitmqClient == nil {
+		return
+	}
+
+	event := &amqpx.BatchEvent{
+		BatchID:       b.ID,
+		FromAccountID: b.FromAccountID,
+		Mode:          b.Mode,
+		Status:        b.Status,
+		TotalRows:     len(b.Rows),
+		Succeeded:     b.Succeeded,
+		InReview:      b.InReview,
+		Failed:        b.Failed,
+		Error:         b.Error,
+		Timestamp:     time.Now().Unix(),
+	}
+
+	if err := s.rabbitmqClient.PublishEvent(
+		ctx,
+		amqpx.BatchExchange,
+		amqpx.BatchFinishedRoute,
+		event,
+	); err != nil {
+		s.logger.WarnContext(ctx, "Failed to publish batch event", "error", err)
+	} else {
+		s.logger.InfoContext(ctx, "Published batch finished event",
+			"batch_id", b.ID,
+		)
+	}
+}
+
+// batchResponse maps a stored batch to its API representation
+func batchResponse(b *store.Batch) *dbankv1.Batch {
+	response := &dbankv1.Batch{
+		Id:            b.ID,
+		FromAccountId: b.FromAccountID,
+		Mode:          b.Mode,
+		Format:        b.Format,
+		FileName:      b.FileName,
+		Status:        b.Status,
+		TotalRows:     uint32(len(b.Rows)),
+		Succeeded:     uint32(b.Succeeded),
+		InReview:      uint32(b.InReview),
+		Failed:        uint32(b.Failed),
+		Error:         b.Error,
+		CreatedAt:     b.CreatedAt.Format(time.RFC3339),
+		Rows:          make([]*dbankv1.BatchRow, 0, len(b.Rows)),
+	}
+	if b.CompletedAt != nil {
+		response.CompletedAt = b.CompletedAt.Format(time.RFC3339)
+	}
+	for _, row := range b.Rows {
+		rowResponse := &dbankv1.BatchRow{
+			RowNumber:     uint32(row.Number),
+			ToAccountId:   row.ToAccountID,
+			Amount:        row.Amount,
+			Currency:      row.Currency,
+			Description:   row.Description,
+			Reference:     row.Reference,
+			Status:        row.Status,
+			TransactionId: row.TransactionID,
+			Error:         row.Error,
+		}
+		// the amount of a valid row fits its currency
+		if row.Status != store.BatchRowInvalid {
+			if amount, err := decimal.NewFromString(row.Amount); err == nil {
+				rowResponse.Money = newMoney(amount, row.Currency)
+			}
+		}
+		response.Rows = append(response.Rows, rowResponse)
+	}
+	return response
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+// FXService quotes currency conversions
+type FXService struct {
+	logger  *slog.Logger
+	fxStore *store.Store
+	dbankv1.UnimplementedFXServiceServer
+}
+
+// NewFXService creates a new FX service
+func NewFXService(
+	logger *slog.Logger,
+	fxStore *store.Store,
+) *FXService {
+	return &FXService{
+		logger:  logger,
+		fxStore: fxStore,
+	}
+}
+
+// Ensure Service implements the FXServiceServer interface
+var _ dbankv1.FXServiceServer = (*FXService)(nil)
+
+// ConvertAmount converts an amount at the current rate and spread
+func (f *FXService) ConvertAmount(
+	ctx context.Context,
+	request *dbankv1.ConvertAmountRequest,
+) (*dbankv1.ConvertAmountResponse, error) {
+	f.logger.InfoContext(ctx, "Converting amount",
+		"from_currency", request.FromCurrency,
+		"to_currency", request.ToCurrency,
+		"amount", request.Amount,
+	)
+
+	if request.FromCurrency == "" || request.ToCurrency == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "from_currency and to_currency are required")
+	}
+
+	if request.FromCurrency == request.ToCurrency {
+		return nil, status.Errorf(codes.InvalidArgument, "from_currency and to_currency must differ")
+	}
+
+	amount, err := decimal.NewFromString(request.Amount)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+	}
+
+	if !amount.IsPositive() {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+
+	conversion, err := f.fxStore.ConvertAmount(ctx, request.FromCurrency, request.ToCurrency, amount)
+	if err != nil {
+		f.logger.ErrorContext(ctx, "failed to convert amount", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to convert amount: %v", err)
+	}
+
+	return &dbankv1.ConvertAmountResponse{
+		FromCurrency:    conversion.Rate.BaseCurrency,
+		ToCurrency:      conversion.Rate.QuoteCurrency,
+		Amount:          conversion.Amount.String(),
+		ConvertedAmount: conversion.ConvertedAmount.String(),
+		Rate:            conversion.Rate.Rate.String(),
+		Spread:          conversion.Rate.Spread.String(),
+		RateId:          conversion.Rate.ID,
+		EffectiveAt:     conversion.Rate.EffectiveAt.Format(time.RFC3339),
+		Source:          conversion.Rate.Source,
+		SnapshotId:      conversion.Rate.SnapshotID,
+		Stale:           conversion.Rate.Stale,
+		Money:           newMoney(conversion.Amount, conversion.Rate.BaseCurrency),
+		ConvertedMoney:  newMoney(conversion.ConvertedAmount, conversion.Rate.QuoteCurrency),
+	}, nil
+}
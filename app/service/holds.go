@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+// HoldService authorizes, captures and voids holds, and releases the holds
+// that expire
+type HoldService struct {
+	logger              *slog.Logger
+	holdStore           *store.Store
+	transactionsService *TransactionService
+	defaultTTL          time.Duration
+	dbankv1.UnimplementedHoldServiceServer
+}
+
+// NewHoldService creates a new hold service. Holds expire after defaultTTL
+// unless the request sets a TTL, captures are executed through transactionsService.
+func NewHoldService(
+	logger *slog.Logger,
+	holdStore *store.Store,
+	transactionsService *TransactionService,
+	defaultTTL time.Duration,
+) *HoldService {
+	return &HoldService{
+		logger:              logger,
+		holdStore:           holdStore,
+		transactionsService: transactionsService,
+		defaultTTL:          defaultTTL,
+	}
+}
+
+// Ensure Service implements the HoldServiceServer interface
+var _ dbankv1.HoldServiceServer = (*HoldService)(nil)
+
+// AuthorizeHold reserves an amount on an account
+func (h *HoldService) AuthorizeHold(
+	ctx context.Context,
+	request *dbankv1.AuthorizeHoldRequest,
+) (*dbankv1.Hold, error) {
+	h.logger.InfoContext(ctx, "Authorizing hold",
+		"account_id", request.AccountId,
+		"to_account_id", request.ToAccountId,
+		"amount", request.Amount,
+	)
+
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := validateCashRequest(request.AccountId, amountString, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ToAccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "to_account_id is required")
+	}
+
+	if request.AccountId == request.ToAccountId {
+		return nil, status.Errorf(codes.InvalidArgument, "account and to account cannot be the same")
+	}
+
+	ttl := h.defaultTTL
+	if request.Ttl != "" {
+		if ttl, err = time.ParseDuration(request.Ttl); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ttl format: %v", err)
+		}
+		if ttl <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "ttl must be positive")
+		}
+	}
+
+	hold := &store.Hold{
+		AccountID:   request.AccountId,
+		ToAccountID: request.ToAccountId,
+		Amount:      amount,
+		Currency:    currencyCode,
+		Description: request.Description,
+		ExpiresAt:   time.Now().Add(ttl).UTC(),
+	}
+	if err = h.holdStore.AuthorizeHold(ctx, hold); err != nil {
+		return nil, wrapError(err, "failed to authorize hold")
+	}
+
+	return holdResponse(hold), nil
+}
+
+// GetHold returns a hold
+func (h *HoldService) GetHold(
+	ctx context.Context,
+	request *dbankv1.GetHoldRequest,
+) (*dbankv1.Hold, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	hold, err := h.holdStore.GetHold(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get hold: %v", err)
+	}
+
+	return holdResponse(hold), nil
+}
+
+// CaptureHold pays a held amount with a transaction that goes through the
+// same path as CreateTransaction, including idempotency and events
+func (h *HoldService) CaptureHold(
+	ctx context.Context,
+	request *dbankv1.CaptureHoldRequest,
+) (*dbankv1.CaptureHoldResponse, error) {
+	h.logger.InfoContext(ctx, "Capturing hold", "id", request.Id, "amount", request.Amount)
+
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	hold, err := h.holdStore.GetHold(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to capture hold: %v", err)
+	}
+
+	amount := hold.Amount
+	if request.Amount != "" {
+		if amount, err = decimal.NewFromString(request.Amount); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+		}
+		if !amount.IsPositive() {
+			return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+		}
+		if err = validateMoney(amount, hold.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	description := request.Description
+	if description == "" {
+		description = hold.Description
+	}
+
+	key, err := idempotencyKey(ctx, request.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	unkeyed := proto.CloneOf(request)
+	unkeyed.IdempotencyKey = ""
+
+	// the store checks the hold again once the accounts are locked
+	transaction, err := h.transactionsService.executeTransaction(ctx,
+		key, unkeyed,
+		&store.TransactionRequest{
+			FromAccountID:   hold.AccountID,
+			ToAccountID:     hold.ToAccountID,
+			TransactionType: store.TransactionTypeCapture,
+			Amount:          amount,
+			Currency:        hold.Currency,
+			Description:     description,
+			HoldID:          hold.ID,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if hold, err = h.holdStore.GetHold(ctx, request.Id); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get captured hold: %v", err)
+	}
+
+	return &dbankv1.CaptureHoldResponse{
+		Hold:        holdResponse(hold),
+		Transaction: transaction,
+	}, nil
+}
+
+// VoidHold releases a hold without paying it
+func (h *HoldService) VoidHold(
+	ctx context.Context,
+	request *dbankv1.VoidHoldRequest,
+) (*dbankv1.Hold, error) {
+	h.logger.InfoContext(ctx, "Voiding hold", "id", request.Id)
+
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	hold, err := h.holdStore.VoidHold(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to void hold: %v", err)
+	}
+
+	return holdResponse(hold), nil
+}
+
+// RunExpiry releases the expired holds every interval until ctx is done
+func (h *HoldService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.ExpireHolds(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireHolds releases the holds that are expired now
+func (h *HoldService) ExpireHolds(ctx context.Context) {
+	for ctx.Err() == nil {
+		expired, err := h.holdStore.ExpireHolds(ctx)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to expire holds", "error", err)
+			return
+		}
+		if expired == 0 {
+			return
+		}
+		h.logger.InfoContext(ctx, "expired holds", "count", expired)
+	}
+}
+
+// holdResponse maps a stored hold to its API representation
+func holdResponse(hold *store.Hold) *dbankv1.Hold {
+	response := &dbankv1.Hold{
+		Id:            hold.ID,
+		AccountId:     hold.AccountID,
+		ToAccountId:   hold.ToAccountID,
+		Amount:        hold.Amount.String(),
+		Currency:      hold.Currency,
+		Description:   hold.Description,
+		Status:        hold.Status,
+		TransactionId: hold.TransactionID,
+		ExpiresAt:     hold.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:     hold.CreatedAt.Format(time.RFC3339),
+		Money:         newMoney(hold.Amount, hold.Currency),
+	}
+	if hold.Status == store.HoldStatusCaptured {
+		response.CapturedAmount = hold.CapturedAmount.String()
+		response.CapturedMoney = newMoney(hold.CapturedAmount, hold.Currency)
+	}
+	return response
+}
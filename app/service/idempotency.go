@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IdempotencyKeyHeader is the HTTP header clients send the idempotency key in
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyKeyMetadata is the gRPC metadata key the header is mapped to
+	IdempotencyKeyMetadata = "idempotency-key"
+)
+
+// internalKeyPrefix starts the idempotency keys the service derives itself,
+// such as the key of a scheduled transfer occurrence. Clients cannot send
+// keys with it, so that they cannot take the key of an occurrence first.
+const internalKeyPrefix = "dbank:"
+
+// internalIdempotencyKey returns a key under the prefix reserved for the service
+func internalIdempotencyKey(format string, args ...any) string {
+	return internalKeyPrefix + fmt.Sprintf(format, args...)
+}
+
+// idempotencyKey returns the key from the request message, falling back
+// to the idempotency-key metadata set by gRPC clients or the HTTP gateway.
+// A key with the reserved internal prefix is refused.
+func idempotencyKey(ctx context.Context, key string) (string, error) {
+	if key == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(IdempotencyKeyMetadata); len(values) > 0 {
+				key = values[0]
+			}
+		}
+	}
+
+	if strings.HasPrefix(key, internalKeyPrefix) {
+		return "", status.Errorf(codes.InvalidArgument, "idempotency key must not start with %q", internalKeyPrefix)
+	}
+
+	return key, nil
+}
+
+// requestHash returns a stable hash of a request message.
+// Callers clear the idempotency key on the message before hashing it.
+// The message name is hashed too, so that a key used for one RPC is not
+// replayed for another RPC whose request happens to encode the same way.
+func requestHash(request proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(proto.MessageName(request)+"\n"), body...))
+	return hex.EncodeToString(sum[:]), nil
+}
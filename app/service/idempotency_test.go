@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+func TestRequestHash(t *testing.T) {
+	transfer := &dbankv1.CreateTransactionRequest{FromAccountId: "a", ToAccountId: "b", Amount: "10", Currency: "USD"}
+	deposit := &dbankv1.DepositRequest{AccountId: "a", Amount: "10", Currency: "USD"}
+
+	hash := func(request proto.Message) string {
+		hash, err := requestHash(request)
+		if err != nil {
+			t.Fatalf("requestHash() error = %v", err)
+		}
+		return hash
+	}
+
+	other := proto.CloneOf(transfer)
+	other.Amount = "20"
+
+	tests := []struct {
+		name    string
+		stored  string
+		request proto.Message
+		want    bool
+	}{
+		{"same request", hash(transfer), transfer, true},
+		{"different request", hash(transfer), other, false},
+		{"another rpc", hash(transfer), deposit, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hash(tt.request) == tt.stored; got != tt.want {
+				t.Errorf("requestHash() matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyMetadata, "from-header"))
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"field", ctx, "from-field", "from-field", false},
+		{"metadata", ctx, "", "from-header", false},
+		{"none", context.Background(), "", "", false},
+		{"internal field", ctx, internalIdempotencyKey("scheduled-transfer:a:1"), "", true},
+		{
+			"internal metadata",
+			metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(IdempotencyKeyMetadata, internalIdempotencyKey("batch:a:1"))),
+			"", "", true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := idempotencyKey(tt.ctx, tt.key)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("idempotencyKey() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+			if tt.wantErr && status.Code(err) != codes.InvalidArgument {
+				t.Errorf("idempotencyKey() error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
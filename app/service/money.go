@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+	"github.com/amjadjibon/dbank/pkg/currency"
+)
+
+// requestAmount returns the amount and currency of a request from its money,
+// or from the amount and currency fields it replaces when money is not set
+func requestAmount(money *dbankv1.Money, amount, currencyCode string) (string, string, error) {
+	if money == nil {
+		return amount, currencyCode, nil
+	}
+
+	if amount != "" || currencyCode != "" {
+		return "", "", status.Errorf(codes.InvalidArgument, "money cannot be combined with amount and currency")
+	}
+
+	return money.Amount, money.CurrencyCode, nil
+}
+
+// validateMoney checks that a currency is in the ISO 4217 registry and that
+// an amount fits its minor units
+func validateMoney(amount decimal.Decimal, currencyCode string) error {
+	if err := currency.Validate(currencyCode, amount); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	return nil
+}
+
+// newMoney returns an amount as Money, written with the minor units of its currency
+func newMoney(amount decimal.Decimal, currencyCode string) *dbankv1.Money {
+	return &dbankv1.Money{
+		CurrencyCode: currencyCode,
+		Amount:       currency.Get(currencyCode).Format(amount),
+	}
+}
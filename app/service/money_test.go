@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+func TestRequestAmount(t *testing.T) {
+	money := &dbankv1.Money{CurrencyCode: "EUR", Amount: "12.50"}
+
+	amount, currencyCode, err := requestAmount(money, "", "")
+	if err != nil || amount != "12.50" || currencyCode != "EUR" {
+		t.Errorf("requestAmount(money) = %q, %q, %v, want 12.50, EUR", amount, currencyCode, err)
+	}
+
+	amount, currencyCode, err = requestAmount(nil, "3", "USD")
+	if err != nil || amount != "3" || currencyCode != "USD" {
+		t.Errorf("requestAmount(nil) = %q, %q, %v, want 3, USD", amount, currencyCode, err)
+	}
+
+	if _, _, err = requestAmount(money, "3", ""); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("requestAmount(money, amount) error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		amount       string
+		currencyCode string
+		want         string
+	}{
+		{"10", "USD", "10.00"},
+		{"1500", "JPY", "1500"},
+		{"1.5", "KWD", "1.500"},
+		{"0.123456", "USD", "0.123456"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currencyCode+" "+tt.amount, func(t *testing.T) {
+			got := newMoney(decimal.RequireFromString(tt.amount), tt.currencyCode)
+			if got.Amount != tt.want || got.CurrencyCode != tt.currencyCode {
+				t.Errorf("newMoney(%s, %s) = %v, want %s", tt.amount, tt.currencyCode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterAmount(t *testing.T) {
+	filter := &store.TransactionFilter{}
+	got, err := filterAmount("min_amount", &dbankv1.Money{CurrencyCode: "EUR", Amount: "5"}, "", filter)
+	if err != nil || !got.Equal(decimal.NewFromInt(5)) || filter.Currency != "EUR" {
+		t.Errorf("filterAmount(money) = %v, %v with currency %q, want 5 EUR", got, err, filter.Currency)
+	}
+
+	_, err = filterAmount("max_amount", &dbankv1.Money{CurrencyCode: "USD", Amount: "9"}, "", filter)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("filterAmount() in another currency error = %v, want InvalidArgument", err)
+	}
+
+	if got, err = filterAmount("max_amount", nil, "", filter); got != nil || err != nil {
+		t.Errorf("filterAmount() without a bound = %v, %v, want nil", got, err)
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+// defaultRetryInterval is the time between the retries of an occurrence
+// that failed for insufficient funds, unless the schedule sets one
+const defaultRetryInterval = time.Hour
+
+// ScheduledTransferService manages standing orders and runs the worker that executes them
+type ScheduledTransferService struct {
+	logger              *slog.Logger
+	scheduleStore       *store.Store
+	transactionsService *TransactionService
+	dbankv1.UnimplementedScheduledTransferServiceServer
+}
+
+// NewScheduledTransferService creates a new scheduled transfer service, due
+// occurrences are executed through the CreateTransaction of transactionsService
+func NewScheduledTransferService(
+	logger *slog.Logger,
+	scheduleStore *store.Store,
+	transactionsService *TransactionService,
+) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		logger:              logger,
+		scheduleStore:       scheduleStore,
+		transactionsService: transactionsService,
+	}
+}
+
+// Ensure Service implements the ScheduledTransferServiceServer interface
+var _ dbankv1.ScheduledTransferServiceServer = (*ScheduledTransferService)(nil)
+
+// CreateScheduledTransfer creates a standing order
+func (s *ScheduledTransferService) CreateScheduledTransfer(
+	ctx context.Context,
+	request *dbankv1.CreateScheduledTransferRequest,
+) (*dbankv1.ScheduledTransfer, error) {
+	s.logger.InfoContext(ctx, "Creating scheduled transfer",
+		"from_account_id", request.FromAccountId,
+		"to_account_id", request.ToAccountId,
+		"amount", request.Amount,
+		"frequency", request.Frequency,
+	)
+
+	if request.FromAccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "from_account_id is required")
+	}
+
+	if request.ToAccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "to_account_id is required")
+	}
+
+	if request.FromAccountId == request.ToAccountId {
+		return nil, status.Errorf(codes.InvalidArgument, "from and to account cannot be the same")
+	}
+
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := decimal.NewFromString(amountString)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+	}
+
+	if !amount.IsPositive() {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if currencyCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "currency is required")
+	}
+
+	if err = validateMoney(amount, currencyCode); err != nil {
+		return nil, err
+	}
+
+	switch request.Frequency {
+	case store.FrequencyOnce, store.FrequencyDaily, store.FrequencyWeekly, store.FrequencyMonthly:
+		if request.CronExpression != "" {
+			return nil, status.Errorf(codes.InvalidArgument, "cron_expression is only allowed with the cron frequency")
+		}
+	case store.FrequencyCron:
+		if request.CronExpression == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "cron_expression is required with the cron frequency")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "frequency must be one of %q, %q, %q, %q or %q",
+			store.FrequencyOnce, store.FrequencyDaily, store.FrequencyWeekly, store.FrequencyMonthly, store.FrequencyCron)
+	}
+
+	transfer := &store.ScheduledTransfer{
+		FromAccountID:  request.FromAccountId,
+		ToAccountID:    request.ToAccountId,
+		Amount:         amount,
+		Currency:       currencyCode,
+		Description:    request.Description,
+		Frequency:      request.Frequency,
+		CronExpression: request.CronExpression,
+		StartAt:        time.Now().UTC().Truncate(time.Second),
+		MaxRuns:        int(request.MaxRuns),
+		MaxRetries:     int(request.MaxRetries),
+		RetryInterval:  defaultRetryInterval,
+	}
+
+	if request.StartAt != "" {
+		if transfer.StartAt, err = time.Parse(time.RFC3339, request.StartAt); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid start_at format: %v", err)
+		}
+		transfer.StartAt = transfer.StartAt.UTC()
+	}
+
+	if request.EndAt != "" {
+		endAt, err := time.Parse(time.RFC3339, request.EndAt)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid end_at format: %v", err)
+		}
+		if endAt.Before(transfer.StartAt) {
+			return nil, status.Errorf(codes.InvalidArgument, "end_at must not be before start_at")
+		}
+		transfer.EndAt = &endAt
+	}
+
+	if request.RetryInterval != "" {
+		if transfer.RetryInterval, err = time.ParseDuration(request.RetryInterval); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid retry_interval format: %v", err)
+		}
+		if transfer.RetryInterval < time.Second {
+			return nil, status.Errorf(codes.InvalidArgument, "retry_interval must be at least one second")
+		}
+	}
+
+	// Fail early for accounts that do not exist or hold another currency,
+	// instead of on every occurrence
+	err = s.scheduleStore.CheckTransferCurrency(ctx, &store.TransactionRequest{
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Currency:      transfer.Currency,
+	})
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to create scheduled transfer: %v", err)
+	}
+
+	if err = s.scheduleStore.CreateScheduledTransfer(ctx, transfer); err != nil {
+		s.logger.ErrorContext(ctx, "failed to create scheduled transfer", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to create scheduled transfer: %v", err)
+	}
+
+	return scheduledTransferResponse(transfer), nil
+}
+
+// GetScheduledTransfer returns a standing order
+func (s *ScheduledTransferService) GetScheduledTransfer(
+	ctx context.Context,
+	request *dbankv1.GetScheduledTransferRequest,
+) (*dbankv1.ScheduledTransfer, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	transfer, err := s.scheduleStore.GetScheduledTransfer(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get scheduled transfer: %v", err)
+	}
+
+	return scheduledTransferResponse(transfer), nil
+}
+
+// ListScheduledTransfers lists the standing orders paying from or into an account
+func (s *ScheduledTransferService) ListScheduledTransfers(
+	ctx context.Context,
+	request *dbankv1.ListScheduledTransfersRequest,
+) (*dbankv1.ListScheduledTransfersResponse, error) {
+	if request.AccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	transfers, err := s.scheduleStore.ListScheduledTransfers(ctx, request.AccountId)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to list scheduled transfers: %v", err)
+	}
+
+	response := &dbankv1.ListScheduledTransfersResponse{
+		ScheduledTransfers: make([]*dbankv1.ScheduledTransfer, 0, len(transfers)),
+	}
+	for _, transfer := range transfers {
+		response.ScheduledTransfers = append(response.ScheduledTransfers, scheduledTransferResponse(transfer))
+	}
+
+	return response, nil
+}
+
+// PauseScheduledTransfer stops the occurrences of a standing order until it is resumed
+func (s *ScheduledTransferService) PauseScheduledTransfer(
+	ctx context.Context,
+	request *dbankv1.ScheduledTransferActionRequest,
+) (*dbankv1.ScheduledTransfer, error) {
+	return s.changeScheduledTransfer(ctx, "pause", request.Id, s.scheduleStore.PauseScheduledTransfer)
+}
+
+// ResumeScheduledTransfer reactivates a paused standing order
+func (s *ScheduledTransferService) ResumeScheduledTransfer(
+	ctx context.Context,
+	request *dbankv1.ScheduledTransferActionRequest,
+) (*dbankv1.ScheduledTransfer, error) {
+	return s.changeScheduledTransfer(ctx, "resume", request.Id,
+		func(ctx context.Context, id string) (*store.ScheduledTransfer, error) {
+			return s.scheduleStore.ResumeScheduledTransfer(ctx, id, time.Now())
+		})
+}
+
+// CancelScheduledTransfer stops a standing order for good
+func (s *ScheduledTransferService) CancelScheduledTransfer(
+	ctx context.Context,
+	request *dbankv1.ScheduledTransferActionRequest,
+) (*dbankv1.ScheduledTransfer, error) {
+	return s.changeScheduledTransfer(ctx, "cancel", request.Id, s.scheduleStore.CancelScheduledTransfer)
+}
+
+func (s *ScheduledTransferService) changeScheduledTransfer(
+	ctx context.Context,
+	action string,
+	id string,
+	change func(ctx context.Context, id string) (*store.ScheduledTransfer, error),
+) (*dbankv1.ScheduledTransfer, error) {
+	s.logger.InfoContext(ctx, "Changing scheduled transfer", "action", action, "id", id)
+
+	if id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	transfer, err := change(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to %s scheduled transfer: %v", action, err)
+	}
+
+	return scheduledTransferResponse(transfer), nil
+}
+
+// ListScheduledTransferRuns lists the outcome of every attempt of a standing order
+func (s *ScheduledTransferService) ListScheduledTransferRuns(
+	ctx context.Context,
+	request *dbankv1.ListScheduledTransferRunsRequest,
+) (*dbankv1.ListScheduledTransferRunsResponse, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	runs, err := s.scheduleStore.ListScheduledTransferRuns(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to list scheduled transfer runs: %v", err)
+	}
+
+	response := &dbankv1.ListScheduledTransferRunsResponse{
+		Runs: make([]*dbankv1.ScheduledTransferRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, &dbankv1.ScheduledTransferRun{
+			Id:            run.ID,
+			OccurrenceAt:  run.OccurrenceAt.Format(time.RFC3339),
+			Attempt:       uint32(run.Attempt),
+			Status:        run.Status,
+			TransactionId: run.TransactionID,
+			Error:         run.Error,
+			CreatedAt:     run.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response, nil
+}
+
+// RunWorker executes the due occurrences every interval until ctx is done.
+// Several workers, also in different processes, can run at the same time.
+func (s *ScheduledTransferService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue executes the occurrences that are due now, one schedule at a time
+func (s *ScheduledTransferService) RunDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := s.scheduleStore.ProcessDueScheduledTransfer(ctx, time.Now(), s.executeOccurrence)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to run scheduled transfers", "error", err)
+			return
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+// executeOccurrence moves the money of an occurrence like CreateTransaction.
+// The idempotency key is derived from the occurrence under the internal
+// prefix, so executing the same occurrence again replays the first transfer
+// instead of paying twice, and no client key can take its place.
+func (s *ScheduledTransferService) executeOccurrence(
+	ctx context.Context,
+	transfer *store.ScheduledTransfer,
+	occurrence time.Time,
+) (string, error) {
+	response, err := s.transactionsService.createTransaction(ctx, &dbankv1.CreateTransactionRequest{
+		FromAccountId:   transfer.FromAccountID,
+		ToAccountId:     transfer.ToAccountID,
+		TransactionType: store.TransactionTypeTransfer,
+		Amount:          transfer.Amount.String(),
+		Currency:        transfer.Currency,
+		Description:     transfer.Description,
+	}, internalIdempotencyKey("scheduled-transfer:%s:%d", transfer.ID, occurrence.Unix()))
+	if err != nil {
+		return "", err
+	}
+
+	return response.Id, nil
+}
+
+// scheduledTransferResponse maps a stored schedule to its API representation
+func scheduledTransferResponse(transfer *store.ScheduledTransfer) *dbankv1.ScheduledTransfer {
+	response := &dbankv1.ScheduledTransfer{
+		Id:             transfer.ID,
+		FromAccountId:  transfer.FromAccountID,
+		ToAccountId:    transfer.ToAccountID,
+		Amount:         transfer.Amount.String(),
+		Currency:       transfer.Currency,
+		Description:    transfer.Description,
+		Frequency:      transfer.Frequency,
+		CronExpression: transfer.CronExpression,
+		StartAt:        transfer.StartAt.Format(time.RFC3339),
+		MaxRuns:        uint32(transfer.MaxRuns),
+		RunCount:       uint32(transfer.RunCount),
+		MaxRetries:     uint32(transfer.MaxRetries),
+		RetryInterval:  transfer.RetryInterval.String(),
+		Status:         transfer.Status,
+		CreatedAt:      transfer.CreatedAt.Format(time.RFC3339),
+		Money:          newMoney(transfer.Amount, transfer.Currency),
+	}
+	if transfer.EndAt != nil {
+		response.EndAt = transfer.EndAt.Format(time.RFC3339)
+	}
+	if transfer.NextOccurrenceAt != nil {
+		response.NextOccurrenceAt = transfer.NextOccurrenceAt.Format(time.RFC3339)
+	}
+	if transfer.NextRunAt != nil {
+		response.NextRunAt = transfer.NextRunAt.Format(time.RFC3339)
+	}
+	return response
+}
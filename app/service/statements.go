@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/exporter"
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+)
+
+// StatementService builds account statements from the Postgres ledger
+type StatementService struct {
+	logger         *slog.Logger
+	statementStore *store.Store
+	dbankv1.UnimplementedStatementServiceServer
+}
+
+// NewStatementService creates a new statement service
+func NewStatementService(
+	logger *slog.Logger,
+	statementStore *store.Store,
+) *StatementService {
+	return &StatementService{
+		logger:         logger,
+		statementStore: statementStore,
+	}
+}
+
+// Ensure Service implements the StatementServiceServer interface
+var _ dbankv1.StatementServiceServer = (*StatementService)(nil)
+
+// GetStatement returns the opening balance, postings and closing balance of an account
+func (s *StatementService) GetStatement(
+	ctx context.Context,
+	request *dbankv1.GetStatementRequest,
+) (*dbankv1.GetStatementResponse, error) {
+	s.logger.InfoContext(ctx, "Getting statement",
+		"account_id", request.AccountId,
+		"from", request.From,
+		"to", request.To,
+	)
+
+	statement, err := s.loadStatement(ctx, request.AccountId, request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return statementResponse(statement), nil
+}
+
+// ExportCamt053 serves the statement of an account as an ISO 20022 camt.053 document.
+// The period is taken from the RFC3339 from and to query parameters.
+func (s *StatementService) ExportCamt053(w http.ResponseWriter, r *http.Request) {
+	s.exportStatement(w, r, chi.URLParam(r, "id"), exporter.Camt053)
+}
+
+// NegotiateStatement serves the statement route in the export format selected by
+// the Accept header and hands JSON requests over to next, the gateway handler
+func (s *StatementService) NegotiateStatement(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := exporter.Negotiate(r.Header.Get("Accept"))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.exportStatement(w, r, chi.URLParam(r, "account_id"), format)
+	}
+}
+
+// exportStatement writes the statement of an account for the from and to query
+// parameters in the given export format
+func (s *StatementService) exportStatement(
+	w http.ResponseWriter,
+	r *http.Request,
+	accountID string,
+	format exporter.Format,
+) {
+	ctx := r.Context()
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+
+	s.logger.InfoContext(ctx, "Exporting statement",
+		"account_id", accountID,
+		"format", format.Name,
+		"from", from,
+		"to", to,
+	)
+
+	statement, err := s.loadStatement(ctx, accountID, from, to)
+	if err != nil {
+		st := status.Convert(err)
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = format.Write(&buf, statement, time.Now()); err != nil {
+		s.logger.ErrorContext(ctx, "failed to write statement", "error", err, "format", format.Name)
+		http.Error(w, "failed to export statement", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", statement.AccountNumber+"."+format.Extension))
+	_, _ = buf.WriteTo(w)
+}
+
+// loadStatement validates the statement parameters and reads the statement
+func (s *StatementService) loadStatement(
+	ctx context.Context,
+	accountID, fromValue, toValue string,
+) (*store.Statement, error) {
+	if accountID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	from, err := time.Parse(time.RFC3339, fromValue)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from format: %v", err)
+	}
+
+	to, err := time.Parse(time.RFC3339, toValue)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid to format: %v", err)
+	}
+
+	if !from.Before(to) {
+		return nil, status.Errorf(codes.InvalidArgument, "from must be before to")
+	}
+
+	statement, err := s.statementStore.GetStatement(ctx, accountID, from, to)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get statement", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to get statement: %v", err)
+	}
+
+	return statement, nil
+}
+
+// statementResponse maps a statement to its API representation
+func statementResponse(statement *store.Statement) *dbankv1.GetStatementResponse {
+	response := &dbankv1.GetStatementResponse{
+		AccountId:      statement.AccountID,
+		AccountNumber:  statement.AccountNumber,
+		AccountName:    statement.AccountName,
+		Currency:       statement.Currency,
+		From:           statement.From.Format(time.RFC3339),
+		To:             statement.To.Format(time.RFC3339),
+		OpeningBalance: statement.OpeningBalance.String(),
+		TotalDebits:    statement.TotalDebits.String(),
+		TotalCredits:   statement.TotalCredits.String(),
+		ClosingBalance: statement.ClosingBalance.String(),
+		Entries:        make([]*dbankv1.StatementEntry, 0, len(statement.Entries)),
+
+		OpeningBalanceMoney: newMoney(statement.OpeningBalance, statement.Currency),
+		TotalDebitsMoney:    newMoney(statement.TotalDebits, statement.Currency),
+		TotalCreditsMoney:   newMoney(statement.TotalCredits, statement.Currency),
+		ClosingBalanceMoney: newMoney(statement.ClosingBalance, statement.Currency),
+	}
+
+	for _, entry := range statement.Entries {
+		response.Entries = append(response.Entries, &dbankv1.StatementEntry{
+			Id:                    entry.ID,
+			TransactionId:         entry.TransactionID,
+			TransactionType:       entry.TransactionType,
+			EntryType:             entry.EntryType,
+			Amount:                entry.Amount.String(),
+			Balance:               entry.Balance.String(),
+			Currency:              entry.Currency,
+			CounterpartyAccountId: entry.CounterpartyAccountID,
+			Description:           entry.Description,
+			CreatedAt:             entry.CreatedAt.Format(time.RFC3339),
+			Money:                 newMoney(entry.Amount, entry.Currency),
+			BalanceMoney:          newMoney(entry.Balance, entry.Currency),
+		})
+	}
+
+	return response
+}
@@ -5,12 +5,17 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/amjadjibon/dbank/app/limits"
+	"github.com/amjadjibon/dbank/app/risk"
 	"github.com/amjadjibon/dbank/app/store"
 	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
 	"github.com/amjadjibon/dbank/pkg/amqpx"
+	"github.com/amjadjibon/dbank/pkg/idx"
 	"github.com/shopspring/decimal"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // TransactionService represents the transaction service
@@ -18,19 +23,26 @@ type TransactionService struct {
 	logger           *slog.Logger
 	transactionStore *store.Store
 	rabbitmqClient   *amqpx.RabbitMQClient
+	limiter          *limits.Limiter
+	riskEngine       *risk.Engine
 	dbankv1.UnimplementedTransactionServiceServer
 }
 
-// NewTransactionService creates a new transaction service
+// NewTransactionService creates a new transaction service, transfers are
+// not limited when limiter is nil and not assessed when riskEngine is nil
 func NewTransactionService(
 	logger *slog.Logger,
 	transactionStore *store.Store,
 	rabbitmqClient *amqpx.RabbitMQClient,
+	limiter *limits.Limiter,
+	riskEngine *risk.Engine,
 ) *TransactionService {
 	return &TransactionService{
 		logger:           logger,
 		transactionStore: transactionStore,
 		rabbitmqClient:   rabbitmqClient,
+		limiter:          limiter,
+		riskEngine:       riskEngine,
 	}
 }
 
@@ -41,6 +53,21 @@ var _ dbankv1.TransactionServiceServer = (*TransactionService)(nil)
 func (t *TransactionService) CreateTransaction(
 	ctx context.Context,
 	request *dbankv1.CreateTransactionRequest,
+) (*dbankv1.CreateTransactionResponse, error) {
+	key, err := idempotencyKey(ctx, request.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.createTransaction(ctx, request, key)
+}
+
+// createTransaction moves money between two accounts with the idempotency
+// key as is, the workers of the service call it with their internal keys
+func (t *TransactionService) createTransaction(
+	ctx context.Context,
+	request *dbankv1.CreateTransactionRequest,
+	key string,
 ) (*dbankv1.CreateTransactionResponse, error) {
 	t.logger.InfoContext(ctx, "Creating transaction",
 		"from_account_id", request.FromAccountId,
@@ -48,96 +75,543 @@ func (t *TransactionService) CreateTransaction(
 		"amount", request.Amount,
 	)
 
-	// Validate request
-	if request.FromAccountId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "from_account_id is required")
+	transaction, err := transferRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	unkeyed := proto.CloneOf(request)
+	unkeyed.IdempotencyKey = ""
+
+	return t.executeTransaction(ctx, key, unkeyed, transaction)
+}
+
+// transferRequest validates a transfer and maps it to its store request
+func transferRequest(request *dbankv1.CreateTransactionRequest) (*store.TransactionRequest, error) {
+	amount, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
 	}
 
-	if request.ToAccountId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "to_account_id is required")
+	amountDecimal, err := validateTransferRequest(request.FromAccountId, request.ToAccountId,
+		amount, currencyCode)
+	if err != nil {
+		return nil, err
 	}
 
-	amountDecimal, err := decimal.NewFromString(request.Amount)
+	return &store.TransactionRequest{
+		FromAccountID:   request.FromAccountId,
+		ToAccountID:     request.ToAccountId,
+		TransactionType: request.TransactionType,
+		Amount:          amountDecimal,
+		Currency:        currencyCode,
+		Description:     request.Description,
+	}, nil
+}
+
+// QuoteTransaction returns the fee and the conversion of a transfer at the
+// current schedules and rates. The transfer itself may differ when they change
+// before it is created.
+func (t *TransactionService) QuoteTransaction(
+	ctx context.Context,
+	request *dbankv1.QuoteTransactionRequest,
+) (*dbankv1.QuoteTransactionResponse, error) {
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := validateTransferRequest(request.FromAccountId, request.ToAccountId,
+		amountString, currencyCode)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+		return nil, err
+	}
+
+	transaction := &store.TransactionRequest{
+		FromAccountID:   request.FromAccountId,
+		ToAccountID:     request.ToAccountId,
+		TransactionType: request.TransactionType,
+		Amount:          amount,
+		Currency:        currencyCode,
+	}
+	if err = t.prepareTransaction(ctx, transaction); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to quote transaction: %v", err)
+	}
+
+	fee := decimal.Zero
+	if transaction.Fee != nil {
+		fee = transaction.Fee.Amount
+	}
+
+	toAmount, toCurrency := amount, currencyCode
+	if conversion := transaction.Conversion; conversion != nil {
+		toAmount, toCurrency = conversion.ConvertedAmount, conversion.Rate.QuoteCurrency
+	}
+
+	response := &dbankv1.QuoteTransactionResponse{
+		Amount:          amount.String(),
+		Currency:        currencyCode,
+		Fee:             fee.String(),
+		TotalDebit:      amount.Add(fee).String(),
+		ToAmount:        toAmount.String(),
+		ToCurrency:      toCurrency,
+		Money:           newMoney(amount, currencyCode),
+		FeeMoney:        newMoney(fee, currencyCode),
+		TotalDebitMoney: newMoney(amount.Add(fee), currencyCode),
+		ToMoney:         newMoney(toAmount, toCurrency),
+	}
+	if conversion := transaction.Conversion; conversion != nil {
+		response.FxRate = conversion.Rate.Rate.String()
+		response.FxSpread = conversion.Rate.Spread.String()
+		response.FxSnapshotId = conversion.Rate.SnapshotID
+	}
+
+	return response, nil
+}
+
+// validateTransferRequest validates the fields shared by transfers and their quotes
+func validateTransferRequest(fromAccountID, toAccountID, amount, currency string) (decimal.Decimal, error) {
+	if fromAccountID == "" {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "from_account_id is required")
+	}
+
+	if toAccountID == "" {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "to_account_id is required")
+	}
+
+	amountDecimal, err := decimal.NewFromString(amount)
+	if err != nil {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
 	}
 
 	if amountDecimal.LessThanOrEqual(decimal.Zero) {
-		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if currency == "" {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "currency is required")
+	}
+
+	if err = validateMoney(amountDecimal, currency); err != nil {
+		return decimal.Zero, err
+	}
+
+	if fromAccountID == toAccountID {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "from and to account cannot be the same")
 	}
 
-	if request.Currency == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "currency is required")
+	return amountDecimal, nil
+}
+
+// prepareTransaction checks the currency against the accounts, quotes the
+// conversion of transfers into an account that holds another currency and
+// computes the fee
+func (t *TransactionService) prepareTransaction(
+	ctx context.Context,
+	transaction *store.TransactionRequest,
+) error {
+	if err := t.transactionStore.PrepareConversion(ctx, transaction); err != nil {
+		return err
 	}
 
-	// get account by ids
-	fromAccount, err := t.transactionStore.GetAccount(ctx, request.FromAccountId)
+	return t.transactionStore.PrepareFee(ctx, transaction)
+}
+
+// Deposit credits an account with money entering the bank, posted against the cash-in account
+func (t *TransactionService) Deposit(
+	ctx context.Context,
+	request *dbankv1.DepositRequest,
+) (*dbankv1.CreateTransactionResponse, error) {
+	t.logger.InfoContext(ctx, "Depositing",
+		"account_id", request.AccountId,
+		"amount", request.Amount,
+	)
+
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
 	if err != nil {
-		t.logger.ErrorContext(ctx, "failed to get from account", "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to get from account: %v", err)
+		return nil, err
 	}
 
-	toAccount, err := t.transactionStore.GetAccount(ctx, request.ToAccountId)
+	amount, err := validateCashRequest(request.AccountId, amountString, currencyCode)
 	if err != nil {
-		t.logger.ErrorContext(ctx, "failed to get to account", "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to get to account: %v", err)
+		return nil, err
 	}
 
-	fromAccountBalance := decimal.NewFromFloat(fromAccount.Balance)
-	toAccountBalance := decimal.NewFromFloat(toAccount.Balance)
-	if fromAccountBalance.LessThan(amountDecimal) {
-		return nil, status.Errorf(codes.InvalidArgument, "insufficient balance in from account")
+	cashIn, err := t.transactionStore.SystemAccountID(ctx, store.SystemAccountCashIn, currencyCode)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get cash-in account: %v", err)
 	}
-	if fromAccount.ID == toAccount.ID {
-		return nil, status.Errorf(codes.InvalidArgument, "from and to account cannot be the same")
+
+	key, err := idempotencyKey(ctx, request.IdempotencyKey)
+	if err != nil {
+		return nil, err
 	}
 
-	newFromAccountBalance := fromAccountBalance.Sub(amountDecimal)
-	newToAccountBalance := toAccountBalance.Add(amountDecimal)
+	unkeyed := proto.CloneOf(request)
+	unkeyed.IdempotencyKey = ""
+
+	return t.executeTransaction(ctx, key, unkeyed, &store.TransactionRequest{
+		FromAccountID:       cashIn,
+		ToAccountID:         request.AccountId,
+		TransactionType:     store.TransactionTypeDeposit,
+		Amount:              amount,
+		Currency:            currencyCode,
+		Description:         request.Description,
+		AllowSystemAccounts: true,
+	})
+}
 
-	// Create transaction
-	if err := t.transactionStore.CreateTransaction(ctx, &store.TransactionRequest{
-		FromAccountID:      request.FromAccountId,
-		ToAccountID:        request.ToAccountId,
-		TransactionType:    request.TransactionType,
-		FromAccountBalance: newFromAccountBalance,
-		ToAccountBalance:   newToAccountBalance,
-		Amount:             amountDecimal,
-		Currency:           request.Currency,
-		Description:        request.Description,
-		Status:             "success",
-	}); err != nil {
+// Withdraw debits an account with money leaving the bank, posted against the cash-out account
+func (t *TransactionService) Withdraw(
+	ctx context.Context,
+	request *dbankv1.WithdrawRequest,
+) (*dbankv1.CreateTransactionResponse, error) {
+	t.logger.InfoContext(ctx, "Withdrawing",
+		"account_id", request.AccountId,
+		"amount", request.Amount,
+	)
+
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := validateCashRequest(request.AccountId, amountString, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	cashOut, err := t.transactionStore.SystemAccountID(ctx, store.SystemAccountCashOut, currencyCode)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get cash-out account: %v", err)
+	}
+
+	key, err := idempotencyKey(ctx, request.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	unkeyed := proto.CloneOf(request)
+	unkeyed.IdempotencyKey = ""
+
+	return t.executeTransaction(ctx, key, unkeyed, &store.TransactionRequest{
+		FromAccountID:       request.AccountId,
+		ToAccountID:         cashOut,
+		TransactionType:     store.TransactionTypeWithdrawal,
+		Amount:              amount,
+		Currency:            currencyCode,
+		Description:         request.Description,
+		AllowSystemAccounts: true,
+	})
+}
+
+// validateCashRequest validates the fields shared by deposits and withdrawals
+func validateCashRequest(accountID, amount, currency string) (decimal.Decimal, error) {
+	if accountID == "" {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	amountDecimal, err := decimal.NewFromString(amount)
+	if err != nil {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+	}
+
+	if !amountDecimal.IsPositive() {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if currency == "" {
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "currency is required")
+	}
+
+	if err = validateMoney(amountDecimal, currency); err != nil {
+		return decimal.Zero, err
+	}
+
+	return amountDecimal, nil
+}
+
+// executeTransaction stores a transaction once per idempotency key and publishes
+// its event. unkeyed is the API request without its key, it is hashed to detect
+// a key that is reused for a different request.
+func (t *TransactionService) executeTransaction(
+	ctx context.Context,
+	key string,
+	unkeyed proto.Message,
+	transaction *store.TransactionRequest,
+) (*dbankv1.CreateTransactionResponse, error) {
+	// Replay the stored response if this key was seen before
+	hash, replayed, err := t.replayRequest(ctx, key, unkeyed)
+	if err != nil || replayed != nil {
+		return replayed, err
+	}
+
+	response, err := t.authorizeTransaction(ctx, key, hash, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := t.reserveLimits(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create transaction; the balance check happens inside the store
+	// while both account rows are locked
+	entries, err := t.transactionStore.CreateTransaction(ctx, transaction)
+	if err != nil {
+		release(ctx)
+
+		// A concurrent request with the same key committed first
+		if key != "" && status.Code(err) == codes.AlreadyExists {
+			if replayed, err := t.replayTransaction(ctx, key, hash); err != nil || replayed != nil {
+				return replayed, err
+			}
+		}
 		t.logger.ErrorContext(ctx, "failed to create transaction", "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to create transaction: %v", err)
+		return nil, wrapError(err, "failed to create transaction")
+	}
+
+	t.publishTransaction(ctx, transaction, response, entries)
+
+	return response, nil
+}
+
+// executeTransactions stores several transfers in one database transaction,
+// either all of them move money or none does. A transfer the risk rules send
+// to review fails them all, as it could be rejected after the others moved
+// money. A transfer whose idempotency key was seen before is replayed. With
+// the error the index of the transfer that failed is returned, -1 when the
+// error is not down to one of them.
+func (t *TransactionService) executeTransactions(
+	ctx context.Context,
+	requests []*dbankv1.CreateTransactionRequest,
+) ([]*dbankv1.CreateTransactionResponse, int, error) {
+	responses := make([]*dbankv1.CreateTransactionResponse, len(requests))
+
+	// Validate and assess every transfer before any of them is stored
+	var indexes []int
+	var transactions []*store.TransactionRequest
+	for i, request := range requests {
+		transaction, err := transferRequest(request)
+		if err != nil {
+			return nil, i, err
+		}
+
+		unkeyed := proto.CloneOf(request)
+		unkeyed.IdempotencyKey = ""
+
+		hash, replayed, err := t.replayRequest(ctx, request.IdempotencyKey, unkeyed)
+		if err != nil {
+			return nil, i, err
+		}
+		if replayed != nil {
+			responses[i] = replayed
+			continue
+		}
+
+		if responses[i], err = t.authorizeTransaction(ctx, request.IdempotencyKey, hash, transaction); err != nil {
+			return nil, i, err
+		}
+		if transaction.Status == store.TransactionStatusPendingReview {
+			return nil, i, status.Errorf(codes.FailedPrecondition,
+				"transaction was sent to review by the risk rules")
+		}
+
+		indexes = append(indexes, i)
+		transactions = append(transactions, transaction)
+	}
+
+	if len(transactions) == 0 {
+		return responses, -1, nil
+	}
+
+	releases := make([]func(context.Context), 0, len(transactions))
+	releaseAll := func() {
+		for _, release := range releases {
+			release(ctx)
+		}
+	}
+	for j, transaction := range transactions {
+		release, err := t.reserveLimits(ctx, transaction)
+		if err != nil {
+			releaseAll()
+			return nil, indexes[j], err
+		}
+		releases = append(releases, release)
+	}
+
+	entries, err := t.transactionStore.CreateTransactions(ctx, transactions)
+	if err != nil {
+		releaseAll()
+
+		failed := -1
+		for j, transaction := range transactions {
+			if transaction.Status == store.TransactionStatusFailed {
+				failed = indexes[j]
+				break
+			}
+		}
+		t.logger.ErrorContext(ctx, "failed to create transactions", "error", err)
+		return nil, failed, wrapError(err, "failed to create transaction")
+	}
+
+	for j, transaction := range transactions {
+		t.publishTransaction(ctx, transaction, responses[indexes[j]], entries[j])
+	}
+
+	return responses, -1, nil
+}
+
+// replayRequest hashes a keyed request and returns the stored response if
+// its key was seen before, an empty key is neither hashed nor replayed
+func (t *TransactionService) replayRequest(
+	ctx context.Context,
+	key string,
+	unkeyed proto.Message,
+) (string, *dbankv1.CreateTransactionResponse, error) {
+	if key == "" {
+		return "", nil, nil
+	}
+
+	hash, err := requestHash(unkeyed)
+	if err != nil {
+		t.logger.ErrorContext(ctx, "failed to hash request", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to hash request")
+	}
+
+	replayed, err := t.replayTransaction(ctx, key, hash)
+	return hash, replayed, err
+}
+
+// authorizeTransaction prepares a transaction, runs the risk rules on it and
+// returns its response, which is stored with the idempotency key
+func (t *TransactionService) authorizeTransaction(
+	ctx context.Context,
+	key, hash string,
+	transaction *store.TransactionRequest,
+) (*dbankv1.CreateTransactionResponse, error) {
+	// Check the currency, quote the conversion and compute the fee
+	if err := t.prepareTransaction(ctx, transaction); err != nil {
+		t.logger.ErrorContext(ctx, "failed to prepare transaction", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to create transaction: %v", err)
 	}
 
 	// Generate transaction ID
-	transactionID := request.FromAccountId + request.ToAccountId
+	transaction.TransactionID = idx.UUID4()
+	transaction.Status = store.TransactionStatusSuccess
+
+	// Run the risk rules on transfers between customer accounts, a transfer
+	// they send to review is stored without moving money
+	if t.riskEngine != nil && !transaction.AllowSystemAccounts && transaction.HoldID == "" {
+		assessment, err := t.riskEngine.Assess(ctx, transaction)
+		if err != nil {
+			t.logger.ErrorContext(ctx, "failed to assess transaction", "error", err)
+			return nil, status.Errorf(status.Code(err), "failed to assess transaction: %v", err)
+		}
+
+		switch assessment.Decision {
+		case store.RiskDecisionDeny:
+			denial := assessment.Denial()
+			return nil, status.Errorf(codes.PermissionDenied, "transaction denied by risk rule %s: %s",
+				denial.Rule, denial.Reason)
+		case store.RiskDecisionReview:
+			transaction.Status = store.TransactionStatusPendingReview
+		}
+	}
+
+	fee := decimal.Zero
+	if transaction.Fee != nil {
+		fee = transaction.Fee.Amount
+	}
+	toAmount, toCurrency := transaction.Amount, transaction.Currency
+	if conversion := transaction.Conversion; conversion != nil {
+		toAmount, toCurrency = conversion.ConvertedAmount, conversion.Rate.QuoteCurrency
+	}
 
 	// Create a transaction response
 	response := &dbankv1.CreateTransactionResponse{
-		Id:              transactionID,
-		FromAccountId:   request.FromAccountId,
-		ToAccountId:     request.ToAccountId,
-		TransactionType: request.TransactionType,
-		Amount:          request.Amount,
-		Currency:        request.Currency,
-		Description:     request.Description,
-		Status:          "success",
+		Id:              transaction.TransactionID,
+		FromAccountId:   transaction.FromAccountID,
+		ToAccountId:     transaction.ToAccountID,
+		TransactionType: transaction.TransactionType,
+		Amount:          transaction.Amount.String(),
+		Currency:        transaction.Currency,
+		Description:     transaction.Description,
+		Status:          transaction.Status,
+		ToAmount:        toAmount.String(),
+		ToCurrency:      toCurrency,
+		Fee:             fee.String(),
+		Money:           newMoney(transaction.Amount, transaction.Currency),
+		ToMoney:         newMoney(toAmount, toCurrency),
+		FeeMoney:        newMoney(fee, transaction.Currency),
+	}
+	if conversion := transaction.Conversion; conversion != nil {
+		response.FxRate = conversion.Rate.Rate.String()
+		response.FxSpread = conversion.Rate.Spread.String()
+		response.FxSnapshotId = conversion.Rate.SnapshotID
 	}
 
-	// Publish the transaction event to RabbitMQ
-	if t.rabbitmqClient != nil {
+	if key != "" {
+		body, err := protojson.Marshal(response)
+		if err != nil {
+			t.logger.ErrorContext(ctx, "failed to marshal response", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to marshal response")
+		}
+		transaction.IdempotencyKey = &store.IdempotencyKey{Key: key, RequestHash: hash, Response: body}
+	}
+
+	return response, nil
+}
+
+// reserveLimits counts a transfer against the limits of the sender and
+// returns the func that gives the reservation back when the transfer fails.
+// The rejection is returned as is to keep the reason of the limit that was hit.
+func (t *TransactionService) reserveLimits(
+	ctx context.Context,
+	transaction *store.TransactionRequest,
+) (func(context.Context), error) {
+	if t.limiter == nil {
+		return func(context.Context) {}, nil
+	}
+
+	release, err := t.limiter.Reserve(ctx, transaction)
+	if err != nil {
+		t.logger.WarnContext(ctx, "transaction rejected by transfer limits", "error", err,
+			"from_account_id", transaction.FromAccountID)
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// publishTransaction publishes the event of a stored transaction, a
+// transaction pending review is published once it is approved
+func (t *TransactionService) publishTransaction(
+	ctx context.Context,
+	transaction *store.TransactionRequest,
+	response *dbankv1.CreateTransactionResponse,
+	entries []*store.LedgerEntry,
+) {
+	if t.rabbitmqClient != nil && transaction.Status == store.TransactionStatusSuccess {
 		event := &amqpx.TransactionEvent{
-			TransactionID:   transactionID,
-			FromAccountID:   request.FromAccountId,
-			ToAccountID:     request.ToAccountId,
-			TransactionType: request.TransactionType,
-			Amount:          request.Amount,
-			Currency:        request.Currency,
-			Status:          "success",
-			Description:     request.Description,
+			TransactionID:   response.Id,
+			FromAccountID:   response.FromAccountId,
+			ToAccountID:     response.ToAccountId,
+			TransactionType: response.TransactionType,
+			Amount:          response.Amount,
+			Currency:        response.Currency,
+			Status:          response.Status,
+			Description:     response.Description,
 			Timestamp:       time.Now().Unix(),
+			Postings:        ledgerPostings(entries),
+		}
+		if transaction.Fee != nil {
+			event.Fee = response.Fee
+		}
+		if transaction.Conversion != nil {
+			event.ToAmount = response.ToAmount
+			event.ToCurrency = response.ToCurrency
 		}
 
 		if err := t.rabbitmqClient.PublishEvent(
@@ -150,12 +624,143 @@ func (t *TransactionService) CreateTransaction(
 			// Don't fail the transaction if event publishing fails
 		} else {
 			t.logger.InfoContext(ctx, "Published transaction success event",
-				"transaction_id", transactionID,
+				"transaction_id", response.Id,
 			)
 		}
 	}
 
-	return response, nil
+	t.publishOverdrawn(ctx, response.Id, entries)
+}
+
+// PublishPosted publishes the transaction.success event of a transaction the
+// system posted by itself, so that the ledger projection records its postings
+func (t *TransactionService) PublishPosted(ctx context.Context, posted *store.PostedTransaction) {
+	publishPosted(ctx, t.logger, t.rabbitmqClient, posted)
+}
+
+// publishPosted publishes the transaction.success event of a posted transaction
+func publishPosted(
+	ctx context.Context,
+	logger *slog.Logger,
+	rabbitmqClient *amqpx.RabbitMQClient,
+	posted *store.PostedTransaction,
+) {
+	if rabbitmqClient == nil {
+		return
+	}
+
+	transaction := posted.Transaction
+	event := &amqpx.TransactionEvent{
+		TransactionID:   transaction.TransactionID,
+		FromAccountID:   transaction.FromAccountID,
+		ToAccountID:     transaction.ToAccountID,
+		TransactionType: transaction.TransactionType,
+		Amount:          transaction.Amount.String(),
+		Currency:        transaction.Currency,
+		Status:          transaction.Status,
+		Description:     transaction.Description,
+		Timestamp:       time.Now().Unix(),
+		Postings:        ledgerPostings(posted.Entries),
+	}
+
+	if err := rabbitmqClient.PublishEvent(
+		ctx,
+		amqpx.TransactionExchange,
+		amqpx.TransactionSuccessRoute,
+		event,
+	); err != nil {
+		logger.WarnContext(ctx, "Failed to publish transaction event", "error", err,
+			"transaction_id", transaction.TransactionID)
+	}
+}
+
+// publishOverdrawn publishes an account.overdrawn event for every posting of
+// a transaction that took an account below zero
+func (t *TransactionService) publishOverdrawn(
+	ctx context.Context,
+	transactionID string,
+	entries []*store.LedgerEntry,
+) {
+	for _, entry := range entries {
+		if !entry.Overdrawn {
+			continue
+		}
+
+		t.logger.InfoContext(ctx, "Account overdrawn",
+			"account_id", entry.AccountID,
+			"transaction_id", transactionID,
+			"balance", entry.Balance,
+		)
+
+		if t.rabbitmqClient == nil {
+			continue
+		}
+
+		if err := t.rabbitmqClient.PublishEvent(
+			ctx,
+			amqpx.AccountExchange,
+			amqpx.AccountOverdrawnRoute,
+			&amqpx.AccountOverdrawnEvent{
+				AccountID:     entry.AccountID,
+				TransactionID: transactionID,
+				Balance:       entry.Balance.String(),
+				Currency:      entry.Currency,
+				Timestamp:     time.Now().Unix(),
+			},
+		); err != nil {
+			t.logger.WarnContext(ctx, "Failed to publish account overdrawn event", "error", err)
+		}
+	}
+}
+
+// ledgerPostings maps store ledger entries to their event representation
+func ledgerPostings(entries []*store.LedgerEntry) []amqpx.LedgerPosting {
+	postings := make([]amqpx.LedgerPosting, 0, len(entries))
+	for _, entry := range entries {
+		postings = append(postings, amqpx.LedgerPosting{
+			ID:        entry.ID,
+			AccountID: entry.AccountID,
+			EntryType: entry.EntryType,
+			Amount:    entry.Amount.String(),
+			Balance:   entry.Balance.String(),
+			Currency:  entry.Currency,
+		})
+	}
+	return postings
+}
+
+// replayTransaction returns the stored response for an idempotency key,
+// or nil if the key has not been used yet
+func (t *TransactionService) replayTransaction(
+	ctx context.Context,
+	key, hash string,
+) (*dbankv1.CreateTransactionResponse, error) {
+	stored, err := t.transactionStore.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		t.logger.ErrorContext(ctx, "failed to get idempotency key", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get idempotency key: %v", err)
+	}
+
+	if stored.RequestHash != hash {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"idempotency key %q was already used with a different request", key)
+	}
+
+	var response dbankv1.CreateTransactionResponse
+	if err := protojson.Unmarshal(stored.Response, &response); err != nil {
+		t.logger.ErrorContext(ctx, "failed to unmarshal stored response", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to unmarshal stored response")
+	}
+
+	t.logger.InfoContext(ctx, "Replaying transaction for idempotency key",
+		"idempotency_key", key,
+		"transaction_id", response.Id,
+	)
+
+	return &response, nil
 }
 
 // GetTransaction retrieves a transaction by ID
@@ -172,14 +777,365 @@ func (t *TransactionService) GetTransaction(
 	transaction, err := t.transactionStore.GetTransaction(ctx, request.Id)
 	if err != nil {
 		t.logger.ErrorContext(ctx, "failed to get transaction", "error", err)
-		return nil, status.Errorf(codes.Internal, "failed to get transaction: %v", err)
+		return nil, status.Errorf(status.Code(err), "failed to get transaction: %v", err)
 	}
 
 	if transaction == nil {
 		return nil, status.Errorf(codes.NotFound, "transaction not found")
 	}
 
-	return &dbankv1.GetTransactionResponse{
+	decisions, err := t.transactionStore.GetRiskDecisions(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get risk decisions: %v", err)
+	}
+
+	response := transactionResponse(transaction)
+	for _, decision := range decisions {
+		response.RiskDecisions = append(response.RiskDecisions, &dbankv1.RiskDecision{
+			Rule:     decision.Rule,
+			Decision: decision.Decision,
+			Reason:   decision.Reason,
+		})
+	}
+
+	return response, nil
+}
+
+// ReverseTransaction moves the money of a transaction back, fully or partially,
+// with a new linked transaction and marks the original as reversed or
+// partially_reversed
+func (t *TransactionService) ReverseTransaction(
+	ctx context.Context,
+	request *dbankv1.ReverseTransactionRequest,
+) (*dbankv1.ReverseTransactionResponse, error) {
+	t.logger.InfoContext(ctx, "Reversing transaction",
+		"id", request.Id,
+		"amount", request.Amount,
+		"money", request.Money,
+	)
+
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "transaction id is required")
+	}
+
+	if request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	amountString, currencyCode, err := requestAmount(request.Money, request.Amount, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var amount decimal.Decimal
+	if amountString != "" {
+		amount, err = decimal.NewFromString(amountString)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid amount format: %v", err)
+		}
+
+		if !amount.IsPositive() {
+			return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+		}
+	}
+
+	// an amount without a currency is in the currency of the transaction
+	if amountString != "" && currencyCode == "" {
+		original, err := t.transactionStore.GetTransaction(ctx, request.Id)
+		if err != nil {
+			return nil, status.Errorf(status.Code(err), "failed to get transaction: %v", err)
+		}
+		if original == nil {
+			return nil, status.Errorf(codes.NotFound, "transaction not found")
+		}
+		if err = validateMoney(amount, original.Currency); err != nil {
+			return nil, err
+		}
+	} else if currencyCode != "" {
+		if err = validateMoney(amount, currencyCode); err != nil {
+			return nil, err
+		}
+	}
+
+	reversal, entries, err := t.transactionStore.ReverseTransaction(ctx, &store.ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: request.Id,
+		Amount:                amount,
+		Currency:              currencyCode,
+		Reason:                request.Reason,
+	})
+	if err != nil {
+		t.logger.ErrorContext(ctx, "failed to reverse transaction", "error", err)
+		return nil, wrapError(err, "failed to reverse transaction")
+	}
+
+	// Publish the reversal so that the ledger projection records the compensating entries
+	if t.rabbitmqClient != nil {
+		event := &amqpx.TransactionEvent{
+			TransactionID:   reversal.TransactionID,
+			FromAccountID:   reversal.FromAccountID,
+			ToAccountID:     reversal.ToAccountID,
+			TransactionType: reversal.TransactionType,
+			Amount:          reversal.Amount.String(),
+			Currency:        reversal.Currency,
+			Status:          reversal.Status,
+			Description:     reversal.Description,
+			Timestamp:       time.Now().Unix(),
+			Postings:        ledgerPostings(entries),
+			ReversalOf:      reversal.ReversalOf,
+		}
+		if reversal.ToCurrency != reversal.Currency {
+			event.ToAmount = reversal.ToAmount.String()
+			event.ToCurrency = reversal.ToCurrency
+		}
+
+		if err := t.rabbitmqClient.PublishEvent(
+			ctx,
+			amqpx.TransactionExchange,
+			amqpx.TransactionReversedRoute,
+			event,
+		); err != nil {
+			t.logger.WarnContext(ctx, "Failed to publish transaction reversed event", "error", err)
+		} else {
+			t.logger.InfoContext(ctx, "Published transaction reversed event",
+				"transaction_id", reversal.TransactionID,
+				"reversal_of", reversal.ReversalOf,
+			)
+		}
+	}
+
+	t.publishOverdrawn(ctx, reversal.TransactionID, entries)
+
+	return &dbankv1.ReverseTransactionResponse{Reversal: transactionResponse(reversal)}, nil
+}
+
+// ApproveTransaction moves the money of a transaction held for review by a
+// risk rule and publishes it as a successful transaction
+func (t *TransactionService) ApproveTransaction(
+	ctx context.Context,
+	request *dbankv1.ApproveTransactionRequest,
+) (*dbankv1.GetTransactionResponse, error) {
+	t.logger.InfoContext(ctx, "Approving transaction",
+		"id", request.Id,
+		"actor", request.Actor,
+	)
+
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "transaction id is required")
+	}
+
+	entries, err := t.transactionStore.ApproveTransaction(ctx, &store.TransactionReview{
+		TransactionID: request.Id,
+		Reason:        request.Reason,
+		Actor:         request.Actor,
+	})
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to approve transaction: %v", err)
+	}
+
+	transaction, err := t.transactionStore.GetTransaction(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get transaction: %v", err)
+	}
+
+	if t.rabbitmqClient != nil {
+		event := &amqpx.TransactionEvent{
+			TransactionID:   transaction.TransactionID,
+			FromAccountID:   transaction.FromAccountID,
+			ToAccountID:     transaction.ToAccountID,
+			TransactionType: transaction.TransactionType,
+			Amount:          transaction.Amount.String(),
+			Currency:        transaction.Currency,
+			Status:          transaction.Status,
+			Description:     transaction.Description,
+			Timestamp:       time.Now().Unix(),
+			Postings:        ledgerPostings(entries),
+		}
+		if transaction.FeeAmount.IsPositive() {
+			event.Fee = transaction.FeeAmount.String()
+		}
+		if transaction.ToCurrency != transaction.Currency {
+			event.ToAmount = transaction.ToAmount.String()
+			event.ToCurrency = transaction.ToCurrency
+		}
+
+		if err := t.rabbitmqClient.PublishEvent(
+			ctx,
+			amqpx.TransactionExchange,
+			amqpx.TransactionSuccessRoute,
+			event,
+		); err != nil {
+			t.logger.WarnContext(ctx, "Failed to publish transaction event", "error", err)
+		} else {
+			t.logger.InfoContext(ctx, "Published transaction success event",
+				"transaction_id", transaction.TransactionID,
+			)
+		}
+	}
+
+	t.publishOverdrawn(ctx, transaction.TransactionID, entries)
+
+	return transactionResponse(transaction), nil
+}
+
+// RejectTransaction rejects a transaction held for review by a risk rule and
+// uncounts it from the transfer limits of its sender
+func (t *TransactionService) RejectTransaction(
+	ctx context.Context,
+	request *dbankv1.RejectTransactionRequest,
+) (*dbankv1.GetTransactionResponse, error) {
+	t.logger.InfoContext(ctx, "Rejecting transaction",
+		"id", request.Id,
+		"actor", request.Actor,
+	)
+
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "transaction id is required")
+	}
+
+	if request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required")
+	}
+
+	review := &store.TransactionReview{
+		TransactionID: request.Id,
+		Reason:        request.Reason,
+		Actor:         request.Actor,
+	}
+	if err := t.transactionStore.RejectTransaction(ctx, review); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to reject transaction: %v", err)
+	}
+
+	if t.limiter != nil {
+		t.limiter.Release(ctx, review.Request.FromAccountID, review.Request.TransactionID, review.Request.Amount)
+	}
+
+	transaction, err := t.transactionStore.GetTransaction(ctx, request.Id)
+	if err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to get transaction: %v", err)
+	}
+
+	return transactionResponse(transaction), nil
+}
+
+const (
+	defaultTransactionsPageSize = 20
+	maxTransactionsPageSize     = 100
+)
+
+// ListTransactions lists the transactions of an account, newest first
+func (t *TransactionService) ListTransactions(
+	ctx context.Context,
+	request *dbankv1.ListTransactionsRequest,
+) (*dbankv1.ListTransactionsResponse, error) {
+	t.logger.InfoContext(ctx, "Listing transactions", "account_id", request.AccountId)
+
+	if request.AccountId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "account_id is required")
+	}
+
+	filter := &store.TransactionFilter{
+		AccountID:       request.AccountId,
+		Direction:       request.Direction,
+		TransactionType: request.TransactionType,
+		Status:          request.Status,
+		Currency:        request.Currency,
+		Limit:           request.PageSize,
+		Cursor:          request.PageToken,
+	}
+
+	switch request.Direction {
+	case "", store.DirectionIncoming, store.DirectionOutgoing:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "direction must be %q or %q",
+			store.DirectionIncoming, store.DirectionOutgoing)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultTransactionsPageSize
+	}
+	if filter.Limit > maxTransactionsPageSize {
+		filter.Limit = maxTransactionsPageSize
+	}
+
+	var err error
+	if filter.MinAmount, err = filterAmount("min_amount", request.MinMoney, request.MinAmount, filter); err != nil {
+		return nil, err
+	}
+	if filter.MaxAmount, err = filterAmount("max_amount", request.MaxMoney, request.MaxAmount, filter); err != nil {
+		return nil, err
+	}
+
+	if request.CreatedFrom != "" {
+		createdFrom, err := time.Parse(time.RFC3339, request.CreatedFrom)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid created_from format: %v", err)
+		}
+		filter.CreatedFrom = createdFrom
+	}
+
+	if request.CreatedTo != "" {
+		createdTo, err := time.Parse(time.RFC3339, request.CreatedTo)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid created_to format: %v", err)
+		}
+		filter.CreatedTo = createdTo
+	}
+
+	transactions, nextPageToken, err := t.transactionStore.ListTransactions(ctx, filter)
+	if err != nil {
+		t.logger.ErrorContext(ctx, "failed to list transactions", "error", err)
+		return nil, status.Errorf(status.Code(err), "failed to list transactions: %v", err)
+	}
+
+	response := &dbankv1.ListTransactionsResponse{
+		Transactions:  make([]*dbankv1.GetTransactionResponse, 0, len(transactions)),
+		NextPageToken: nextPageToken,
+	}
+	for _, transaction := range transactions {
+		response.Transactions = append(response.Transactions, transactionResponse(transaction))
+	}
+
+	return response, nil
+}
+
+// filterAmount returns an amount bound of ListTransactions from its money, or
+// from the amount field it replaces, nil when neither is set. The currency of
+// the money filters on the currency like the currency field of the request.
+func filterAmount(
+	field string,
+	money *dbankv1.Money,
+	amount string,
+	filter *store.TransactionFilter,
+) (*decimal.Decimal, error) {
+	amount, currencyCode, err := requestAmount(money, amount, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == "" {
+		return nil, nil
+	}
+
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s format: %v", field, err)
+	}
+
+	if currencyCode != "" {
+		if filter.Currency != "" && filter.Currency != currencyCode {
+			return nil, status.Errorf(codes.InvalidArgument, "%s currency %s does not match currency %s",
+				field, currencyCode, filter.Currency)
+		}
+		filter.Currency = currencyCode
+	}
+
+	return &value, nil
+}
+
+// transactionResponse maps a stored transaction to its API representation
+func transactionResponse(transaction *store.Transaction) *dbankv1.GetTransactionResponse {
+	response := &dbankv1.GetTransactionResponse{
 		Id:              transaction.TransactionID,
 		FromAccountId:   transaction.FromAccountID,
 		ToAccountId:     transaction.ToAccountID,
@@ -188,6 +1144,27 @@ func (t *TransactionService) GetTransaction(
 		Currency:        transaction.Currency,
 		Description:     transaction.Description,
 		Status:          transaction.Status,
-		CreatedAt:       transaction.CreatedAt,
-	}, nil
+		CreatedAt:       transaction.CreatedAt.Format(time.RFC3339),
+		ReversalOf:      transaction.ReversalOf,
+		ToAmount:        transaction.ToAmount.String(),
+		ToCurrency:      transaction.ToCurrency,
+		Fee:             transaction.FeeAmount.String(),
+		Money:           newMoney(transaction.Amount, transaction.Currency),
+		ToMoney:         newMoney(transaction.ToAmount, transaction.ToCurrency),
+		FeeMoney:        newMoney(transaction.FeeAmount, transaction.Currency),
+	}
+	if transaction.FXRateID != "" {
+		response.FxRate = transaction.FXRate.String()
+		response.FxSpread = transaction.FXSpread.String()
+		response.FxSnapshotId = transaction.FXSnapshotID
+	}
+	return response
+}
+
+// wrapError prefixes the message of a store error with what failed, keeping
+// its code and details such as the reason of an insufficient funds error
+func wrapError(err error, message string) error {
+	st := status.Convert(err).Proto()
+	st.Message = message + ": " + err.Error()
+	return status.FromProto(st).Err()
 }
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+)
+
+func TestWrapError(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "insufficient balance").WithDetails(&errdetails.ErrorInfo{
+		Reason: store.ReasonInsufficientFunds,
+		Domain: store.ErrorDomain,
+	})
+	if err != nil {
+		t.Fatalf("WithDetails() error = %v", err)
+	}
+
+	err = wrapError(st.Err(), "failed to create transaction")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %s, want %s", status.Code(err), codes.InvalidArgument)
+	}
+	if !strings.HasPrefix(status.Convert(err).Message(), "failed to create transaction: ") {
+		t.Errorf("message = %q, want it prefixed", status.Convert(err).Message())
+	}
+	if !store.IsInsufficientFunds(err) {
+		t.Errorf("IsInsufficientFunds() of the wrapped error = false, want true")
+	}
+}
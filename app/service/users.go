@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/app/store"
+	dbankv1 "github.com/amjadjibon/dbank/gen/go/dbank/v1"
+	"github.com/amjadjibon/dbank/pkg/passw"
+)
+
+// UserService manages the customers of the bank, their accounts are opened
+// with AccountService.OpenAccount
+type UserService struct {
+	logger    *slog.Logger
+	userStore *store.Store
+	dbankv1.UnimplementedUserServiceServer
+}
+
+// NewUserService creates a new user service
+func NewUserService(
+	logger *slog.Logger,
+	userStore *store.Store,
+) *UserService {
+	return &UserService{
+		logger:    logger,
+		userStore: userStore,
+	}
+}
+
+// Ensure Service implements the UserServiceServer interface
+var _ dbankv1.UserServiceServer = (*UserService)(nil)
+
+// CreateUser creates a user without accounts
+func (u *UserService) CreateUser(
+	ctx context.Context,
+	request *dbankv1.CreateUserRequest,
+) (*dbankv1.User, error) {
+	if request.Username == "" || request.Email == "" || request.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "username, email, and password are required")
+	}
+
+	hashedPassword, err := passw.HashPassword(request.Password)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to hash password", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	user := &store.User{
+		ID:       uuid.New().String(),
+		Username: request.Username,
+		Email:    request.Email,
+		Password: hashedPassword,
+	}
+	if err = u.userStore.CreateUser(ctx, user); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to create user: %v", err)
+	}
+
+	return userResponse(user), nil
+}
+
+// GetUser returns a user by its id
+func (u *UserService) GetUser(
+	ctx context.Context,
+	request *dbankv1.GetUserRequest,
+) (*dbankv1.User, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	}
+
+	user, err := u.userStore.GetUser(ctx, request.Id)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", "error", err, "id", request.Id)
+		return nil, status.Errorf(status.Code(err), "failed to get user: %v", err)
+	}
+
+	return userResponse(user), nil
+}
+
+// UpdateUser changes the username and email of a user, empty fields are
+// left unchanged
+func (u *UserService) UpdateUser(
+	ctx context.Context,
+	request *dbankv1.UpdateUserRequest,
+) (*dbankv1.User, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	}
+
+	user, err := u.userStore.GetUser(ctx, request.Id)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user for update", "error", err, "id", request.Id)
+		return nil, status.Errorf(status.Code(err), "failed to get user: %v", err)
+	}
+
+	if request.Username != "" {
+		user.Username = request.Username
+	}
+	if request.Email != "" {
+		user.Email = request.Email
+	}
+
+	if err = u.userStore.UpdateUser(ctx, user); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to update user: %v", err)
+	}
+
+	return userResponse(user), nil
+}
+
+// ChangePassword replaces the password of a user once the current password
+// is verified
+func (u *UserService) ChangePassword(
+	ctx context.Context,
+	request *dbankv1.ChangePasswordRequest,
+) (*dbankv1.ChangePasswordResponse, error) {
+	if request.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	}
+
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "current and new password are required")
+	}
+
+	user, err := u.userStore.GetUser(ctx, request.Id)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user for password change", "error", err, "id", request.Id)
+		return nil, status.Errorf(status.Code(err), "failed to get user: %v", err)
+	}
+
+	err = passw.VerifyPassword([]byte(user.Password), []byte(request.CurrentPassword))
+	if err != nil {
+		if errors.Is(err, passw.ErrMismatchedHashAndPassword) {
+			return nil, status.Errorf(codes.PermissionDenied, "current password is incorrect")
+		}
+		u.logger.ErrorContext(ctx, "failed to verify password", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to verify password")
+	}
+
+	hashedPassword, err := passw.HashPassword(request.NewPassword)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to hash password", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	if err = u.userStore.SetPassword(ctx, user.ID, hashedPassword); err != nil {
+		return nil, status.Errorf(status.Code(err), "failed to change password: %v", err)
+	}
+
+	return &dbankv1.ChangePasswordResponse{
+		Id:      user.ID,
+		Message: "Password successfully changed",
+	}, nil
+}
+
+// userResponse maps a stored user to its API representation, without the
+// password hash
+func userResponse(user *store.User) *dbankv1.User {
+	return &dbankv1.User{
+		Id:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		KycTier:   user.KYCTier,
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+	}
+}
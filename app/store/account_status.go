@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// Account statuses. Pending accounts are not opened yet and can only be
+// funded, debit blocked and dormant accounts can receive but not send,
+// frozen and closed accounts can do neither.
+const (
+	AccountStatusPending      = "pending"
+	AccountStatusActive       = "active"
+	AccountStatusFrozen       = "frozen"
+	AccountStatusDebitBlocked = "debit_blocked"
+	AccountStatusDormant      = "dormant"
+	AccountStatusClosed       = "closed"
+)
+
+// AccountStatuses are the supported account statuses
+var AccountStatuses = []string{
+	AccountStatusPending,
+	AccountStatusActive,
+	AccountStatusFrozen,
+	AccountStatusDebitBlocked,
+	AccountStatusDormant,
+	AccountStatusClosed,
+}
+
+// accountTransitions are the statuses an account may move to from each
+// status. Closed is final.
+var accountTransitions = map[string][]string{
+	AccountStatusPending:      {AccountStatusActive, AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusActive:       {AccountStatusFrozen, AccountStatusDebitBlocked, AccountStatusDormant, AccountStatusClosed},
+	AccountStatusFrozen:       {AccountStatusActive, AccountStatusClosed},
+	AccountStatusDebitBlocked: {AccountStatusActive, AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusDormant:      {AccountStatusActive, AccountStatusFrozen, AccountStatusClosed},
+}
+
+// AuditActionAccountStatusChanged is the audit log action of a change of
+// the status of an account
+const AuditActionAccountStatusChanged = "account.status_changed"
+
+// CanChangeAccountStatus reports whether an account may move from one status to another
+func CanChangeAccountStatus(from, to string) bool {
+	return slices.Contains(accountTransitions[from], to)
+}
+
+// canSend reports whether money may leave the account
+func (a *lockedAccount) canSend() bool {
+	return a.SystemCode != "" || a.Status == AccountStatusActive
+}
+
+// canReceive reports whether money may enter the account
+func (a *lockedAccount) canReceive() bool {
+	switch a.Status {
+	case AccountStatusPending, AccountStatusActive, AccountStatusDebitBlocked, AccountStatusDormant:
+		return true
+	default:
+		return a.SystemCode != ""
+	}
+}
+
+// checkAccountStatuses checks that the status of the sender of a transfer
+// lets it send and the status of the receiver lets it receive
+func checkAccountStatuses(fromAccount, toAccount *lockedAccount) error {
+	if !fromAccount.canSend() {
+		return status.Errorf(codes.FailedPrecondition, "account %s is %s and cannot send money",
+			fromAccount.ID, fromAccount.Status)
+	}
+
+	if !toAccount.canReceive() {
+		return status.Errorf(codes.FailedPrecondition, "account %s is %s and cannot receive money",
+			toAccount.ID, toAccount.Status)
+	}
+
+	return nil
+}
+
+// AccountStatusChange is a change of the status of an account.
+// PreviousStatus and AuditLogID are set when it is made.
+type AccountStatusChange struct {
+	AccountID      string `json:"account_id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	Reason         string `json:"reason"`
+	Actor          string `json:"actor,omitempty"`
+	AuditLogID     string `json:"-"`
+}
+
+// FreezeAccount stops all money movements of an account
+func (s *Store) FreezeAccount(
+	ctx context.Context,
+	change *AccountStatusChange,
+) error {
+	change.Status = AccountStatusFrozen
+	return s.setAccountStatus(ctx, change, nil)
+}
+
+// UnfreezeAccount makes a frozen account active again
+func (s *Store) UnfreezeAccount(
+	ctx context.Context,
+	change *AccountStatusChange,
+) error {
+	change.Status = AccountStatusActive
+	return s.setAccountStatus(ctx, change, func(account *lockedAccount) error {
+		if account.Status != AccountStatusFrozen {
+			return status.Errorf(codes.FailedPrecondition, "cannot unfreeze a %s account", account.Status)
+		}
+		return nil
+	})
+}
+
+// setAccountStatus makes a status change in its own database transaction
+func (s *Store) setAccountStatus(
+	ctx context.Context,
+	change *AccountStatusChange,
+	check func(account *lockedAccount) error,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		return s.changeAccountStatus(ctx, tx, change, check)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to change account status", "error", err,
+			"account_id", change.AccountID, "status", change.Status)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "account status changed",
+		"account_id", change.AccountID,
+		"previous_status", change.PreviousStatus,
+		"status", change.Status,
+	)
+	return nil
+}
+
+// changeAccountStatus locks an account, checks that it may move to the new
+// status, and check when it is set, then changes it and records the change
+// in the audit log of the account owner
+func (s *Store) changeAccountStatus(
+	ctx context.Context,
+	tx pgx.Tx,
+	change *AccountStatusChange,
+	check func(account *lockedAccount) error,
+) error {
+	accounts, err := s.lockAccounts(ctx, tx, change.AccountID)
+	if err != nil {
+		return err
+	}
+
+	account := accounts[change.AccountID]
+	if account.SystemCode != "" {
+		return status.Errorf(codes.PermissionDenied, "the status of internal accounts cannot be changed")
+	}
+
+	if account.Status == change.Status {
+		return status.Errorf(codes.FailedPrecondition, "account is already %s", account.Status)
+	}
+
+	if !CanChangeAccountStatus(account.Status, change.Status) {
+		return status.Errorf(codes.FailedPrecondition, "cannot change a %s account to %s", account.Status, change.Status)
+	}
+
+	if check != nil {
+		if err = check(account); err != nil {
+			return err
+		}
+	}
+	change.PreviousStatus = account.Status
+
+	sql, args, err := s.db.Builder.
+		Update("dbank_accounts").
+		Set("status", change.Status).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("pk = ?", account.PK).
+		Suffix("RETURNING user_pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var userPK int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&userPK); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update account status", "error", err)
+		return status.Errorf(codes.Internal, "failed to update account status")
+	}
+	account.Status = change.Status
+
+	change.AuditLogID, err = s.writeAuditLog(ctx, tx, userPK, AuditActionAccountStatusChanged, change)
+	return err
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestCheckAccountStatuses(t *testing.T) {
+	tests := []struct {
+		from, to string
+		code     codes.Code
+	}{
+		{AccountStatusActive, AccountStatusActive, codes.OK},
+		{AccountStatusActive, AccountStatusPending, codes.OK},
+		{AccountStatusActive, AccountStatusDebitBlocked, codes.OK},
+		{AccountStatusActive, AccountStatusDormant, codes.OK},
+		{AccountStatusActive, AccountStatusFrozen, codes.FailedPrecondition},
+		{AccountStatusActive, AccountStatusClosed, codes.FailedPrecondition},
+		{AccountStatusPending, AccountStatusActive, codes.FailedPrecondition},
+		{AccountStatusDebitBlocked, AccountStatusActive, codes.FailedPrecondition},
+		{AccountStatusDormant, AccountStatusActive, codes.FailedPrecondition},
+		{AccountStatusFrozen, AccountStatusActive, codes.FailedPrecondition},
+		{AccountStatusClosed, AccountStatusActive, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			err := checkAccountStatuses(&lockedAccount{Status: tt.from}, &lockedAccount{Status: tt.to})
+			if status.Code(err) != tt.code {
+				t.Errorf("checkAccountStatuses() error = %v, want %v", err, tt.code)
+			}
+		})
+	}
+
+	// internal accounts are the other side of deposits and withdrawals
+	system := &lockedAccount{Status: AccountStatusActive, SystemCode: SystemAccountCashIn}
+	if err := checkAccountStatuses(system, &lockedAccount{Status: AccountStatusFrozen}); err == nil {
+		t.Errorf("checkAccountStatuses() deposit into a frozen account error = nil")
+	}
+}
+
+func TestCanChangeAccountStatus(t *testing.T) {
+	for _, from := range AccountStatuses {
+		if CanChangeAccountStatus(from, from) {
+			t.Errorf("CanChangeAccountStatus(%s, %s) = true", from, from)
+		}
+		if CanChangeAccountStatus(AccountStatusClosed, from) {
+			t.Errorf("CanChangeAccountStatus(closed, %s) = true, closed is final", from)
+		}
+	}
+
+	if !CanChangeAccountStatus(AccountStatusFrozen, AccountStatusActive) {
+		t.Errorf("CanChangeAccountStatus(frozen, active) = false")
+	}
+	if CanChangeAccountStatus(AccountStatusFrozen, AccountStatusDormant) {
+		t.Errorf("CanChangeAccountStatus(frozen, dormant) = true")
+	}
+}
+
+func TestStore_AccountStatus(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "20", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	transfer := func(from, to string) error {
+		_, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: TransactionTypeTransfer,
+			Amount:          decimal.NewFromInt(5),
+			Currency:        "USD",
+			Status:          TransactionStatusSuccess,
+		})
+		return err
+	}
+
+	change := &AccountStatusChange{AccountID: to, Reason: "suspicious activity", Actor: "ops"}
+	if err := s.FreezeAccount(ctx, change); err != nil {
+		t.Fatalf("FreezeAccount() error = %v", err)
+	}
+	if change.PreviousStatus != AccountStatusActive || change.AuditLogID == "" {
+		t.Errorf("FreezeAccount() = %+v, want a previous status of active and an audit log", change)
+	}
+
+	var action string
+	err := s.db.Pool.QueryRow(ctx, "SELECT action FROM dbank_audit_logs WHERE id = $1", change.AuditLogID).Scan(&action)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if action != AuditActionAccountStatusChanged {
+		t.Errorf("audit log action = %s, want %s", action, AuditActionAccountStatusChanged)
+	}
+
+	if err = transfer(from, to); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateTransaction() to a frozen account error = %v, want FailedPrecondition", err)
+	}
+	if err = transfer(to, from); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateTransaction() from a frozen account error = %v, want FailedPrecondition", err)
+	}
+	if err = s.FreezeAccount(ctx, &AccountStatusChange{AccountID: to}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("FreezeAccount() of a frozen account error = %v, want FailedPrecondition", err)
+	}
+
+	if err = s.UnfreezeAccount(ctx, &AccountStatusChange{AccountID: to, Reason: "cleared"}); err != nil {
+		t.Fatalf("UnfreezeAccount() error = %v", err)
+	}
+	if err = transfer(from, to); err != nil {
+		t.Errorf("CreateTransaction() to an unfrozen account error = %v", err)
+	}
+	if err = s.UnfreezeAccount(ctx, &AccountStatusChange{AccountID: to}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UnfreezeAccount() of an active account error = %v, want FailedPrecondition", err)
+	}
+
+	if err = s.FreezeAccount(ctx, &AccountStatusChange{AccountID: to, Reason: "again"}); err != nil {
+		t.Fatalf("FreezeAccount() again error = %v", err)
+	}
+	if err = s.CloseAccount(ctx, &AccountClosure{AccountStatusChange: AccountStatusChange{AccountID: to}}); err != nil {
+		t.Fatalf("CloseAccount() of a frozen account error = %v", err)
+	}
+	if err = s.UnfreezeAccount(ctx, &AccountStatusChange{AccountID: to}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UnfreezeAccount() of a closed account error = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestStore_AccountStatus_ReversalsAndAdjustments(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "20", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	transfer := &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   from,
+		ToAccountID:     to,
+		TransactionType: TransactionTypeTransfer,
+		Amount:          decimal.NewFromInt(5),
+		Currency:        "USD",
+		Status:          TransactionStatusSuccess,
+	}
+	if _, err := s.CreateTransaction(ctx, transfer); err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+
+	if err := s.FreezeAccount(ctx, &AccountStatusChange{AccountID: to, Reason: "investigation"}); err != nil {
+		t.Fatalf("FreezeAccount() error = %v", err)
+	}
+
+	_, _, err := s.ReverseTransaction(ctx, &ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: transfer.TransactionID,
+		Reason:                "customer request",
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReverseTransaction() out of a frozen account error = %v, want FailedPrecondition", err)
+	}
+
+	account, err := s.GetAccount(ctx, to)
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	balance := decimal.NewFromInt(100)
+	_, err = s.UpdateAccount(ctx, &UpdateAccountRequest{
+		ID:          to,
+		AccountName: account.AccountName,
+		AccountType: account.AccountType,
+		Balance:     &balance,
+		Currency:    account.Currency,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpdateAccount() balance of a frozen account error = %v, want FailedPrecondition", err)
+	}
+	if got := accountBalance(t, s, to); !got.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("frozen account balance = %s, want 5", got)
+	}
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// writeAuditLog records an operation on the data of a user in dbank_audit_logs,
+// inside the transaction that performs it. It returns the id of the entry.
+func (s *Store) writeAuditLog(
+	ctx context.Context,
+	tx pgx.Tx,
+	userPK int,
+	action string,
+	data any,
+) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to marshal audit data", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to marshal audit data")
+	}
+
+	id := idx.UUID4()
+	sql, args, err := s.db.Builder.
+		Insert("dbank_audit_logs").
+		Columns("id", "user_pk", "action", "data").
+		Values(id, userPK, action, body).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert audit log", "error", err, "action", action)
+		return "", status.Errorf(codes.Internal, "failed to insert audit log")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,351 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// Processing modes of a batch
+const (
+	// BatchModeAllOrNothing executes every row in one database transaction,
+	// no row moves money when one is invalid or fails
+	BatchModeAllOrNothing = "all_or_nothing"
+	// BatchModeBestEffort executes every valid row and keeps the ones that succeed
+	BatchModeBestEffort = "best_effort"
+)
+
+// BatchModes are the supported batch processing modes
+var BatchModes = []string{BatchModeAllOrNothing, BatchModeBestEffort}
+
+// Statuses of a batch
+const (
+	BatchStatusPending    = "pending"
+	BatchStatusProcessing = "processing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusFailed     = "failed"
+)
+
+// Statuses of a batch row. A row that moved money has the status of its
+// transaction, success or pending_review.
+const (
+	BatchRowPending = "pending"
+	BatchRowInvalid = "invalid"
+	BatchRowFailed  = "failed"
+	BatchRowSkipped = "skipped"
+)
+
+// Batch is an uploaded payment file of transfers from one account
+type Batch struct {
+	pk            int
+	ID            string      `json:"id"`
+	FromAccountID string      `json:"from_account_id"`
+	Mode          string      `json:"mode"`
+	Format        string      `json:"format"`
+	FileName      string      `json:"file_name"`
+	Status        string      `json:"status"`
+	Succeeded     int         `json:"succeeded"`
+	InReview      int         `json:"in_review"`
+	Failed        int         `json:"failed"`
+	Error         string      `json:"error"`
+	CreatedAt     time.Time   `json:"created_at"`
+	CompletedAt   *time.Time  `json:"completed_at"`
+	Rows          []*BatchRow `json:"rows"`
+}
+
+// BatchRow is a payment of a batch as written in the file and the result of
+// its transfer
+type BatchRow struct {
+	Number        int    `json:"row_number"`
+	ToAccountID   string `json:"to_account_id"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+	Description   string `json:"description"`
+	Reference     string `json:"reference"`
+	Status        string `json:"status"`
+	TransactionID string `json:"transaction_id"`
+	Error         string `json:"error"`
+}
+
+// tally counts the rows of a batch by their result
+func (b *Batch) tally() {
+	b.Succeeded, b.InReview, b.Failed = 0, 0, 0
+	for _, row := range b.Rows {
+		switch row.Status {
+		case TransactionStatusSuccess:
+			b.Succeeded++
+		case TransactionStatusPendingReview:
+			b.InReview++
+		case BatchRowPending:
+		default:
+			b.Failed++
+		}
+	}
+}
+
+// batchColumns are the columns scanned by scanBatch
+var batchColumns = []string{
+	"pk", "id", "from_account_id", "mode", "format", "file_name", "status",
+	"succeeded", "in_review", "failed", "error", "created_at", "completed_at",
+}
+
+func scanBatch(row pgx.Row) (*Batch, error) {
+	var batch Batch
+	err := row.Scan(
+		&batch.pk,
+		&batch.ID,
+		&batch.FromAccountID,
+		&batch.Mode,
+		&batch.Format,
+		&batch.FileName,
+		&batch.Status,
+		&batch.Succeeded,
+		&batch.InReview,
+		&batch.Failed,
+		&batch.Error,
+		&batch.CreatedAt,
+		&batch.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// CreateBatch stores a batch and its rows for the batch worker
+func (s *Store) CreateBatch(
+	ctx context.Context,
+	batch *Batch,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Insert("dbank_batches").
+			Columns("id", "from_account_id", "mode", "format", "file_name", "status", "total_rows").
+			Values(batch.ID, batch.FromAccountID, batch.Mode, batch.Format, batch.FileName,
+				BatchStatusPending, len(batch.Rows)).
+			Suffix("RETURNING pk, status, created_at").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&batch.pk, &batch.Status, &batch.CreatedAt); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert batch", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert batch")
+		}
+
+		builder := s.db.Builder.
+			Insert("dbank_batch_rows").
+			Columns(
+				"batch_pk", "row_number", "to_account_id", "amount", "currency",
+				"description", "reference", "status", "error",
+			)
+		for _, row := range batch.Rows {
+			builder = builder.Values(batch.pk, row.Number, row.ToAccountID, row.Amount, row.Currency,
+				row.Description, row.Reference, row.Status, row.Error)
+		}
+
+		sql, args, err = builder.ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert batch rows", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert batch rows")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create batch", "error", err)
+		return err
+	}
+
+	batch.tally()
+	return nil
+}
+
+// GetBatch returns a batch and the results of its rows
+func (s *Store) GetBatch(
+	ctx context.Context,
+	id string,
+) (*Batch, error) {
+	sql, args, err := s.db.Builder.
+		Select(batchColumns...).
+		From("dbank_batches").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	batch, err := scanBatch(s.db.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "batch not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query batch", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query batch")
+	}
+
+	if batch.Rows, err = s.batchRows(ctx, batch.pk); err != nil {
+		return nil, err
+	}
+
+	// the counts of a batch are only stored when it ends
+	if batch.Status == BatchStatusPending || batch.Status == BatchStatusProcessing {
+		batch.tally()
+	}
+
+	return batch, nil
+}
+
+// batchRows returns the rows of a batch in file order
+func (s *Store) batchRows(
+	ctx context.Context,
+	batchPK int,
+) ([]*BatchRow, error) {
+	sql, args, err := s.db.Builder.
+		Select(
+			"row_number", "to_account_id", "amount", "currency", "description", "reference",
+			"status", "COALESCE(transaction_id::text, '')", "error",
+		).
+		From("dbank_batch_rows").
+		Where("batch_pk = ?", batchPK).
+		OrderBy("row_number").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query batch rows", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query batch rows")
+	}
+
+	batchRows, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[BatchRow])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan batch rows", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan batch rows")
+	}
+
+	return batchRows, nil
+}
+
+// ClaimBatch marks the oldest pending batch as processing and returns it,
+// or nil when there is none. A batch left processing for longer than
+// staleAfter, by a worker that stopped, is claimed again.
+func (s *Store) ClaimBatch(
+	ctx context.Context,
+	staleAfter time.Duration,
+) (*Batch, error) {
+	sql, args, err := s.db.Builder.
+		Update("dbank_batches").
+		Set("status", BatchStatusProcessing).
+		Set("claimed_at", time.Now()).
+		Where(`pk = (
+			SELECT pk FROM dbank_batches
+			WHERE status = ? OR (status = ? AND claimed_at < ?)
+			ORDER BY pk
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)`, BatchStatusPending, BatchStatusProcessing, time.Now().Add(-staleAfter)).
+		Suffix("RETURNING " + strings.Join(batchColumns, ", ")).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	batch, err := scanBatch(s.db.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to claim batch", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to claim batch")
+	}
+
+	if batch.Rows, err = s.batchRows(ctx, batch.pk); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// SaveBatchRow writes the result of a row of a claimed batch
+func (s *Store) SaveBatchRow(
+	ctx context.Context,
+	batch *Batch,
+	row *BatchRow,
+) error {
+	sql, args, err := s.db.Builder.
+		Update("dbank_batch_rows").
+		Set("status", row.Status).
+		Set("transaction_id", nullString(row.TransactionID)).
+		Set("error", row.Error).
+		Set("updated_at", time.Now()).
+		Where("batch_pk = ?", batch.pk).
+		Where("row_number = ?", row.Number).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = s.db.Pool.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save batch row", "error", err)
+		return status.Errorf(codes.Internal, "failed to save batch row")
+	}
+
+	return nil
+}
+
+// CompleteBatch ends a claimed batch with a status, completed or failed,
+// and stores the counts of its rows
+func (s *Store) CompleteBatch(
+	ctx context.Context,
+	batch *Batch,
+	batchStatus string,
+	batchError string,
+) error {
+	batch.tally()
+	completedAt := time.Now()
+
+	sql, args, err := s.db.Builder.
+		Update("dbank_batches").
+		Set("status", batchStatus).
+		Set("succeeded", batch.Succeeded).
+		Set("in_review", batch.InReview).
+		Set("failed", batch.Failed).
+		Set("error", batchError).
+		Set("completed_at", completedAt).
+		Where("pk = ?", batch.pk).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = s.db.Pool.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to complete batch", "error", err)
+		return status.Errorf(codes.Internal, "failed to complete batch")
+	}
+
+	batch.Status, batch.Error, batch.CompletedAt = batchStatus, batchError, &completedAt
+	return nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_Batch(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "100", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	batch := &Batch{
+		ID:            idx.UUID4(),
+		FromAccountID: from,
+		Mode:          BatchModeBestEffort,
+		Format:        "csv",
+		FileName:      "payroll.csv",
+		Rows: []*BatchRow{
+			{Number: 1, ToAccountID: to, Amount: "10", Currency: "USD", Status: BatchRowPending},
+			{Number: 2, ToAccountID: to, Amount: "abc", Currency: "USD", Status: BatchRowInvalid, Error: "invalid amount"},
+		},
+	}
+	if err := s.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if batch.Status != BatchStatusPending || batch.Failed != 1 {
+		t.Errorf("CreateBatch() = %+v, want pending with one failed row", batch)
+	}
+
+	// other batches may be pending in the test database
+	var claimed *Batch
+	for claimed == nil || claimed.ID != batch.ID {
+		var err error
+		if claimed, err = s.ClaimBatch(ctx, time.Hour); err != nil || claimed == nil {
+			t.Fatalf("ClaimBatch() = %v, %v, want the created batch", claimed, err)
+		}
+	}
+	if claimed.Status != BatchStatusProcessing || len(claimed.Rows) != 2 {
+		t.Fatalf("ClaimBatch() = %+v, want processing with 2 rows", claimed)
+	}
+
+	// a processing batch is only claimed again once it is stale
+	if again, err := s.ClaimBatch(ctx, time.Hour); err != nil || (again != nil && again.ID == batch.ID) {
+		t.Errorf("ClaimBatch() again = %v, %v, want another batch or none", again, err)
+	}
+
+	row := claimed.Rows[0]
+	row.Status, row.TransactionID = TransactionStatusSuccess, idx.UUID4()
+	if err := s.SaveBatchRow(ctx, claimed, row); err != nil {
+		t.Fatalf("SaveBatchRow() error = %v", err)
+	}
+	if err := s.CompleteBatch(ctx, claimed, BatchStatusCompleted, ""); err != nil {
+		t.Fatalf("CompleteBatch() error = %v", err)
+	}
+
+	got, err := s.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+	if got.Status != BatchStatusCompleted || got.Succeeded != 1 || got.Failed != 1 || got.CompletedAt == nil {
+		t.Errorf("GetBatch() = %+v, want completed with one succeeded and one failed row", got)
+	}
+	if got.Rows[0].TransactionID != row.TransactionID || got.Rows[1].Error != "invalid amount" {
+		t.Errorf("GetBatch() rows = %+v, %+v", got.Rows[0], got.Rows[1])
+	}
+
+	if _, err = s.GetBatch(ctx, idx.UUID4()); status.Code(err) != codes.NotFound {
+		t.Errorf("GetBatch() unknown error = %v, want NotFound", err)
+	}
+}
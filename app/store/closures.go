@@ -0,0 +1,242 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// TransactionTypeSweep moves the remaining balance of an account that is closed
+const TransactionTypeSweep = "sweep"
+
+// AccountClosure closes an account. Its remaining balance is swept to
+// SweepToAccountID, or to the suspense account when it is empty.
+// Interest, Sweep, Entries, OpenedAt and ClosedAt are set by CloseAccount,
+// Interest only when accrued interest was paid, Sweep and Entries only when
+// there was a balance to sweep.
+type AccountClosure struct {
+	AccountStatusChange
+	SweepToAccountID string
+
+	Interest *PostedTransaction
+	Sweep    *TransactionRequest
+	Entries  []*LedgerEntry
+	OpenedAt time.Time
+	ClosedAt time.Time
+}
+
+// CloseAccount closes an account for good. An account with authorized holds,
+// active or paused scheduled transfers or a balance below zero is refused.
+// Interest accrued but not yet posted is paid first, then the remaining
+// balance is swept in the same database transaction as the status change,
+// so a closed account never holds money.
+func (s *Store) CloseAccount(
+	ctx context.Context,
+	closure *AccountClosure,
+) error {
+	closure.Status = AccountStatusClosed
+
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		// the sweep account is locked together with the closed one, in
+		// the same order as transfers lock them
+		currency, err := s.accountCurrency(ctx, tx, closure.AccountID)
+		if err != nil {
+			return err
+		}
+
+		sweepToID := closure.SweepToAccountID
+		if sweepToID == "" {
+			if sweepToID, err = s.systemAccountID(ctx, tx, SystemAccountSuspense, currency); err != nil {
+				return err
+			}
+		}
+		if sweepToID == closure.AccountID {
+			return status.Errorf(codes.InvalidArgument, "the balance cannot be swept to the account that is closed")
+		}
+
+		expenseID, err := s.systemAccountID(ctx, tx, SystemAccountInterestExpense, currency)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := s.lockAccounts(ctx, tx, closure.AccountID, sweepToID, expenseID)
+		if err != nil {
+			return err
+		}
+
+		account, sweepTo := accounts[closure.AccountID], accounts[sweepToID]
+		if err = s.checkClosure(ctx, tx, account); err != nil {
+			return err
+		}
+
+		// unposted accruals are paid up to the day of the closure, the
+		// month-end run skips closed accounts
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if _, closure.Interest, err = s.postAccruedInterest(ctx, tx, account, accounts[expenseID], today); err != nil {
+			return err
+		}
+
+		if account.Balance.IsPositive() {
+			// frozen money must not reach the customer by way of a closure
+			if closure.SweepToAccountID != "" && account.Status == AccountStatusFrozen {
+				return status.Errorf(codes.FailedPrecondition,
+					"the balance of a frozen account can only be swept to the suspense account")
+			}
+			if closure.SweepToAccountID != "" && sweepTo.SystemCode != "" {
+				return status.Errorf(codes.PermissionDenied, "the balance cannot be swept to an internal account")
+			}
+			if !sweepTo.canReceive() {
+				return status.Errorf(codes.FailedPrecondition, "account %s is %s and cannot receive money",
+					sweepTo.ID, sweepTo.Status)
+			}
+			if sweepTo.Currency != account.Currency {
+				return status.Errorf(codes.InvalidArgument, "account %s holds %s, the balance of the closed account is in %s",
+					sweepTo.ID, sweepTo.Currency, account.Currency)
+			}
+
+			closure.Sweep = &TransactionRequest{
+				TransactionID:       idx.UUID4(),
+				FromAccountID:       account.ID,
+				ToAccountID:         sweepTo.ID,
+				TransactionType:     TransactionTypeSweep,
+				Amount:              account.Balance,
+				Currency:            account.Currency,
+				Description:         "Account closure",
+				Status:              TransactionStatusSuccess,
+				AllowSystemAccounts: true,
+			}
+			if closure.Entries, err = s.recordTransaction(ctx, tx, closure.Sweep, account, sweepTo); err != nil {
+				return err
+			}
+		}
+
+		if err = s.changeAccountStatus(ctx, tx, &closure.AccountStatusChange, nil); err != nil {
+			return err
+		}
+
+		sql, args, err := s.db.Builder.
+			Update("dbank_accounts").
+			// after the postings of the closure, which are stamped when written
+			Set("closed_at", squirrel.Expr("clock_timestamp()")).
+			Where("pk = ?", account.PK).
+			Suffix("RETURNING created_at, closed_at").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&closure.OpenedAt, &closure.ClosedAt); err != nil {
+			s.logger.ErrorContext(ctx, "failed to close account", "error", err)
+			return status.Errorf(codes.Internal, "failed to close account")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to close account", "error", err, "account_id", closure.AccountID)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "account closed",
+		"account_id", closure.AccountID,
+		"previous_status", closure.PreviousStatus,
+		"swept", closure.Sweep != nil,
+	)
+	return nil
+}
+
+// checkClosure checks that a locked account can be closed: it is a customer
+// account in a status that may be closed, with nothing held, nothing
+// scheduled and a balance that is not below zero
+func (s *Store) checkClosure(
+	ctx context.Context,
+	tx pgx.Tx,
+	account *lockedAccount,
+) error {
+	if account.SystemCode != "" {
+		return status.Errorf(codes.PermissionDenied, "internal accounts cannot be closed")
+	}
+
+	if !CanChangeAccountStatus(account.Status, AccountStatusClosed) {
+		return status.Errorf(codes.FailedPrecondition, "cannot close a %s account", account.Status)
+	}
+
+	if _, err := s.releaseExpiredHolds(ctx, tx, account); err != nil {
+		return err
+	}
+	if account.HeldBalance.IsPositive() {
+		return status.Errorf(codes.FailedPrecondition,
+			"account has %s %s held, its holds must be captured or voided before it is closed",
+			account.HeldBalance, account.Currency)
+	}
+
+	sql, args, err := s.db.Builder.
+		Select("count(*)").
+		From("dbank_scheduled_transfers").
+		Where(squirrel.Or{
+			squirrel.Eq{"from_account_id": account.ID},
+			squirrel.Eq{"to_account_id": account.ID},
+		}).
+		Where(squirrel.Eq{"status": []string{ScheduleStatusActive, ScheduleStatusPaused}}).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var scheduled int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&scheduled); err != nil {
+		s.logger.ErrorContext(ctx, "failed to count scheduled transfers", "error", err)
+		return status.Errorf(codes.Internal, "failed to count scheduled transfers")
+	}
+	if scheduled > 0 {
+		return status.Errorf(codes.FailedPrecondition,
+			"account has %d scheduled transfers, they must be cancelled before it is closed", scheduled)
+	}
+
+	if account.Balance.IsNegative() {
+		return status.Errorf(codes.FailedPrecondition, "account is overdrawn by %s %s, it must be repaid before it is closed",
+			account.Balance.Neg(), account.Currency)
+	}
+
+	return nil
+}
+
+// accountCurrency returns the currency of an account without locking it
+func (s *Store) accountCurrency(
+	ctx context.Context,
+	tx pgx.Tx,
+	id string,
+) (string, error) {
+	sql, args, err := s.db.Builder.
+		Select("currency").
+		From("dbank_accounts").
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var currency string
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&currency); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", status.Errorf(codes.NotFound, "account %s not found", id)
+		}
+		s.logger.ErrorContext(ctx, "failed to query account currency", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to query account currency")
+	}
+
+	return currency, nil
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_CloseAccount(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "40", "USD")
+	sweepTo := createTestAccount(t, s, "0", "USD")
+	euros := createTestAccount(t, s, "0", "EUR")
+
+	closure := func(sweepToAccountID string) *AccountClosure {
+		return &AccountClosure{
+			AccountStatusChange: AccountStatusChange{AccountID: account, Reason: "customer request", Actor: "ops"},
+			SweepToAccountID:    sweepToAccountID,
+		}
+	}
+
+	hold := &Hold{
+		AccountID:   account,
+		ToAccountID: sweepTo,
+		Amount:      decimal.NewFromInt(10),
+		Currency:    "USD",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	if err := s.AuthorizeHold(ctx, hold); err != nil {
+		t.Fatalf("AuthorizeHold() error = %v", err)
+	}
+	if err := s.CloseAccount(ctx, closure(sweepTo)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CloseAccount() with a hold error = %v, want FailedPrecondition", err)
+	}
+	if _, err := s.VoidHold(ctx, hold.ID); err != nil {
+		t.Fatalf("VoidHold() error = %v", err)
+	}
+
+	if err := s.CloseAccount(ctx, closure(euros)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CloseAccount() sweeping to another currency error = %v, want InvalidArgument", err)
+	}
+	if err := s.CloseAccount(ctx, closure(account)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CloseAccount() sweeping to itself error = %v, want InvalidArgument", err)
+	}
+
+	closed := closure(sweepTo)
+	if err := s.CloseAccount(ctx, closed); err != nil {
+		t.Fatalf("CloseAccount() error = %v", err)
+	}
+	if closed.Sweep == nil || !closed.Sweep.Amount.Equal(decimal.NewFromInt(40)) || len(closed.Entries) != 2 {
+		t.Fatalf("CloseAccount() sweep = %+v, entries = %d, want 40 with 2 postings", closed.Sweep, len(closed.Entries))
+	}
+	if closed.PreviousStatus != AccountStatusActive || closed.AuditLogID == "" || closed.ClosedAt.IsZero() {
+		t.Errorf("CloseAccount() = %+v, want a previous status, audit log and closing time", closed)
+	}
+	if got := accountBalance(t, s, account); !got.IsZero() {
+		t.Errorf("closed account balance = %s, want 0", got)
+	}
+	if got := accountBalance(t, s, sweepTo); !got.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("sweep account balance = %s, want 40", got)
+	}
+
+	// the closed account and its history stay readable
+	statement, err := s.GetStatement(ctx, account, closed.OpenedAt, closed.ClosedAt.Add(time.Microsecond))
+	if err != nil {
+		t.Fatalf("GetStatement() of a closed account error = %v", err)
+	}
+	if len(statement.Entries) != 2 || !statement.ClosingBalance.IsZero() {
+		t.Errorf("final statement = %d entries closing at %s, want the opening deposit and the sweep closing at 0",
+			len(statement.Entries), statement.ClosingBalance)
+	}
+
+	if err = s.CloseAccount(ctx, closure("")); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CloseAccount() of a closed account error = %v, want FailedPrecondition", err)
+	}
+	_, err = s.CreateTransaction(ctx, &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   sweepTo,
+		ToAccountID:     account,
+		TransactionType: TransactionTypeTransfer,
+		Amount:          decimal.NewFromInt(1),
+		Currency:        "USD",
+		Status:          TransactionStatusSuccess,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateTransaction() to a closed account error = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestStore_CloseAccount_Suspense(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "15.5", "USD")
+	suspense, err := s.SystemAccountID(ctx, SystemAccountSuspense, "USD")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+	before := accountBalance(t, s, suspense)
+
+	closure := &AccountClosure{AccountStatusChange: AccountStatusChange{AccountID: account, Reason: "dormant"}}
+	if err = s.CloseAccount(ctx, closure); err != nil {
+		t.Fatalf("CloseAccount() error = %v", err)
+	}
+	if closure.Sweep == nil || closure.Sweep.ToAccountID != suspense {
+		t.Fatalf("CloseAccount() sweep = %+v, want a sweep to the suspense account", closure.Sweep)
+	}
+	if got := accountBalance(t, s, suspense).Sub(before); !got.Equal(decimal.RequireFromString("15.5")) {
+		t.Errorf("suspense account received %s, want 15.5", got)
+	}
+}
+
+func TestStore_CloseAccount_Interest(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "10", "USD")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	_, err := s.db.Pool.Exec(ctx, `INSERT INTO dbank_interest_accruals
+		(id, account_pk, accrual_date, balance, currency, day_count, day_fraction, tiers, amount)
+		SELECT $2, pk, $3, 10, 'USD', 'ACT/365', 0.0027397260, '[]', 0.2500001 FROM dbank_accounts WHERE id = $1`,
+		account, idx.UUID4(), yesterday)
+	if err != nil {
+		t.Fatalf("failed to insert accrual: %v", err)
+	}
+
+	closure := &AccountClosure{AccountStatusChange: AccountStatusChange{AccountID: account, Reason: "moved abroad"}}
+	if err = s.CloseAccount(ctx, closure); err != nil {
+		t.Fatalf("CloseAccount() error = %v", err)
+	}
+	if closure.Sweep == nil || !closure.Sweep.Amount.Equal(decimal.RequireFromString("10.25")) {
+		t.Fatalf("CloseAccount() sweep = %+v, want the balance and the accrued interest, 10.25", closure.Sweep)
+	}
+	if got := accountBalance(t, s, account); !got.IsZero() {
+		t.Errorf("closed account balance = %s, want 0", got)
+	}
+
+	var unposted int
+	err = s.db.Pool.QueryRow(ctx, `SELECT count(*) FROM dbank_interest_accruals
+		WHERE posting_pk IS NULL AND account_pk = (SELECT pk FROM dbank_accounts WHERE id = $1)`, account).Scan(&unposted)
+	if err != nil {
+		t.Fatalf("failed to count accruals: %v", err)
+	}
+	if unposted != 0 {
+		t.Errorf("unposted accruals after closure = %d, want 0", unposted)
+	}
+}
+
+func TestStore_CloseAccount_ConcurrentAdjustment(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// the closure sweeps to the suspense account, which the adjustment
+	// posts against, both have to lock the two accounts in the same order
+	var wg sync.WaitGroup
+	for range 10 {
+		account := createTestAccount(t, s, "40", "USD")
+		balance := decimal.NewFromInt(10)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := s.CloseAccount(ctx, &AccountClosure{
+				AccountStatusChange: AccountStatusChange{AccountID: account, Reason: "customer request", Actor: "ops"},
+			})
+			if status.Code(err) == codes.Internal {
+				t.Errorf("CloseAccount() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := s.UpdateAccount(ctx, &UpdateAccountRequest{
+				ID:          account,
+				AccountName: "test",
+				AccountType: "checking",
+				Balance:     &balance,
+				Currency:    "USD",
+			})
+			if status.Code(err) == codes.Internal {
+				t.Errorf("UpdateAccount() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/currency"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// SystemAccountFeeIncome is credited with the fees charged on transactions
+const SystemAccountFeeIncome = "fee_income"
+
+// FeeTransactionTypeConversion is the transaction type of the fee schedules
+// of transfers converted into the currency of the receiving account
+const FeeTransactionTypeConversion = "conversion"
+
+// Types of fee schedules
+const (
+	// FeeTypeFlat charges FlatAmount
+	FeeTypeFlat = "flat"
+	// FeeTypePercentage charges Rate of the amount
+	FeeTypePercentage = "percentage"
+	// FeeTypeTiered charges the flat amount and rate of the tier of the amount
+	FeeTypeTiered = "tiered"
+)
+
+// FeeTypes are the supported fee schedule types
+var FeeTypes = []string{FeeTypeFlat, FeeTypePercentage, FeeTypeTiered}
+
+// FeeSchedule is the fee charged to the sender of transactions of
+// TransactionType in Currency from accounts of AccountType. An empty
+// AccountType applies to the account types without a schedule of their own.
+// MinFee and MaxFee bound the fee of every type, zero leaves it unbounded.
+type FeeSchedule struct {
+	ID              string          `json:"id"`
+	TransactionType string          `json:"transaction_type"`
+	AccountType     string          `json:"account_type"`
+	Currency        string          `json:"currency"`
+	FeeType         string          `json:"fee_type"`
+	FlatAmount      decimal.Decimal `json:"flat_amount"`
+	Rate            decimal.Decimal `json:"rate"`
+	MinFee          decimal.Decimal `json:"min_fee"`
+	MaxFee          decimal.Decimal `json:"max_fee"`
+	Tiers           []*FeeTier      `json:"tiers"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// FeeTier applies to amounts from MinAmount up to the MinAmount of the next
+// tier and charges FlatAmount plus Rate of the amount
+type FeeTier struct {
+	MinAmount  decimal.Decimal `json:"min_amount"`
+	FlatAmount decimal.Decimal `json:"flat_amount"`
+	Rate       decimal.Decimal `json:"rate"`
+}
+
+// Fee is the fee of a transaction request and the schedule it comes from,
+// in the currency of the request
+type Fee struct {
+	ScheduleID string          `json:"schedule_id"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+// Compute returns the fee on an amount, rounded to the minor units of the
+// currency of the schedule
+func (f *FeeSchedule) Compute(amount decimal.Decimal) decimal.Decimal {
+	var fee decimal.Decimal
+	switch f.FeeType {
+	case FeeTypeFlat:
+		fee = f.FlatAmount
+	case FeeTypePercentage:
+		fee = amount.Mul(f.Rate)
+	case FeeTypeTiered:
+		var tier *FeeTier
+		for _, t := range f.Tiers {
+			if t.MinAmount.LessThanOrEqual(amount) && (tier == nil || t.MinAmount.GreaterThan(tier.MinAmount)) {
+				tier = t
+			}
+		}
+		if tier != nil {
+			fee = tier.FlatAmount.Add(amount.Mul(tier.Rate))
+		}
+	}
+
+	if fee.LessThan(f.MinFee) {
+		fee = f.MinFee
+	}
+	if f.MaxFee.IsPositive() && fee.GreaterThan(f.MaxFee) {
+		fee = f.MaxFee
+	}
+
+	return currency.Get(f.Currency).Round(fee)
+}
+
+// feeAmount returns the fee of the request, zero when it has none
+func (r *TransactionRequest) feeAmount() decimal.Decimal {
+	if r.Fee == nil {
+		return decimal.Zero
+	}
+	return r.Fee.Amount
+}
+
+// SetFeeSchedule creates or replaces the fee schedule of a transaction type,
+// account type and currency
+func (s *Store) SetFeeSchedule(
+	ctx context.Context,
+	schedule *FeeSchedule,
+) error {
+	tiers := schedule.Tiers
+	if tiers == nil {
+		tiers = []*FeeTier{}
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_fee_schedules").
+		Columns(
+			"id", "transaction_type", "account_type", "currency", "fee_type",
+			"flat_amount", "rate", "min_fee", "max_fee", "tiers",
+		).
+		Values(
+			idx.UUID4(), schedule.TransactionType, schedule.AccountType, schedule.Currency, schedule.FeeType,
+			schedule.FlatAmount, schedule.Rate, schedule.MinFee, schedule.MaxFee, tiers,
+		).
+		Suffix(`ON CONFLICT (transaction_type, account_type, currency) DO UPDATE SET
+			fee_type = EXCLUDED.fee_type,
+			flat_amount = EXCLUDED.flat_amount,
+			rate = EXCLUDED.rate,
+			min_fee = EXCLUDED.min_fee,
+			max_fee = EXCLUDED.max_fee,
+			tiers = EXCLUDED.tiers,
+			updated_at = now()`).
+		Suffix("RETURNING id, updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&schedule.ID, &schedule.UpdatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save fee schedule", "error", err)
+		return status.Errorf(codes.Internal, "failed to save fee schedule")
+	}
+
+	return nil
+}
+
+// GetFeeSchedule returns the schedule that applies to a transaction type,
+// account type and currency, or nil when the transaction is free
+func (s *Store) GetFeeSchedule(
+	ctx context.Context,
+	transactionType, accountType, currency string,
+) (*FeeSchedule, error) {
+	sql, args, err := s.db.Builder.
+		Select(
+			"id", "transaction_type", "account_type", "currency", "fee_type",
+			"flat_amount", "rate", "min_fee", "max_fee", "tiers", "updated_at",
+		).
+		From("dbank_fee_schedules").
+		Where("transaction_type = ?", transactionType).
+		Where("account_type IN (?, '')", accountType).
+		Where("currency = ?", currency).
+		OrderBy("account_type DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var schedule FeeSchedule
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&schedule.ID,
+		&schedule.TransactionType,
+		&schedule.AccountType,
+		&schedule.Currency,
+		&schedule.FeeType,
+		&schedule.FlatAmount,
+		&schedule.Rate,
+		&schedule.MinFee,
+		&schedule.MaxFee,
+		&schedule.Tiers,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to query fee schedule", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query fee schedule")
+	}
+
+	return &schedule, nil
+}
+
+// PrepareFee computes the fee of a transaction request from the schedule of
+// its type, sending account and currency, see feeTransactionTypes. Money
+// sent from internal accounts is not charged.
+func (s *Store) PrepareFee(
+	ctx context.Context,
+	request *TransactionRequest,
+) error {
+	request.Fee = nil
+
+	sql, args, err := s.db.Builder.
+		Select("account_type", "system_code IS NOT NULL").
+		From("dbank_accounts").
+		Where("id = ?", request.FromAccountID).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var accountType string
+	var internal bool
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&accountType, &internal); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "account %s not found", request.FromAccountID)
+		}
+		s.logger.ErrorContext(ctx, "failed to query account", "error", err)
+		return status.Errorf(codes.Internal, "failed to query account")
+	}
+
+	if internal {
+		return nil
+	}
+
+	var schedule *FeeSchedule
+	for _, transactionType := range feeTransactionTypes(request) {
+		if schedule, err = s.GetFeeSchedule(ctx, transactionType, accountType, request.Currency); err != nil {
+			return err
+		}
+		if schedule != nil {
+			break
+		}
+	}
+	if schedule == nil {
+		return nil
+	}
+
+	if amount := schedule.Compute(request.Amount); amount.IsPositive() {
+		request.Fee = &Fee{ScheduleID: schedule.ID, Amount: amount}
+	}
+
+	return nil
+}
+
+// feeTransactionTypes are the transaction types whose fee schedule applies
+// to a request, in order of precedence. Converted transfers are charged by
+// the conversion schedule first. The type of a transfer between customer
+// accounts is chosen by the client, so a type without a schedule is charged
+// like a transfer and cannot be used to avoid the fee.
+func feeTransactionTypes(request *TransactionRequest) []string {
+	var types []string
+	if request.Conversion != nil {
+		types = append(types, FeeTransactionTypeConversion)
+	}
+	types = append(types, request.TransactionType)
+	if !request.AllowSystemAccounts && request.HoldID == "" {
+		types = append(types, TransactionTypeTransfer)
+	}
+	return slices.Compact(types)
+}
+
+// postFee moves a fee from the locked account of the payer to the fee income
+// account, which is locked after the customer accounts. A negative fee refunds it.
+func (s *Store) postFee(
+	ctx context.Context,
+	tx pgx.Tx,
+	transactionPK int,
+	transactionID string,
+	fee decimal.Decimal,
+	payer *lockedAccount,
+) ([]*LedgerEntry, error) {
+	feeIncomeID, err := s.systemAccountID(ctx, tx, SystemAccountFeeIncome, payer.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.lockAccounts(ctx, tx, feeIncomeID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*LedgerEntry, 0, 2)
+	for _, posting := range []struct {
+		account *lockedAccount
+		amount  decimal.Decimal
+	}{
+		{payer, fee.Neg()},
+		{accounts[feeIncomeID], fee},
+	} {
+		entry, err := s.postEntry(ctx, tx, posting.account, transactionPK, transactionID,
+			posting.amount, payer.Currency)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestFeeSchedule_Compute(t *testing.T) {
+	d := decimal.RequireFromString
+
+	tests := []struct {
+		name     string
+		schedule *FeeSchedule
+		amount   string
+		want     string
+	}{
+		{
+			name:     "flat",
+			schedule: &FeeSchedule{FeeType: FeeTypeFlat, FlatAmount: d("1.50")},
+			amount:   "1000",
+			want:     "1.5",
+		},
+		{
+			name:     "percentage",
+			schedule: &FeeSchedule{FeeType: FeeTypePercentage, Rate: d("0.015")},
+			amount:   "10.33",
+			want:     "0.15",
+		},
+		{
+			name:     "percentage below the minimum",
+			schedule: &FeeSchedule{FeeType: FeeTypePercentage, Rate: d("0.01"), MinFee: d("0.5"), MaxFee: d("5")},
+			amount:   "10",
+			want:     "0.5",
+		},
+		{
+			name:     "percentage above the maximum",
+			schedule: &FeeSchedule{FeeType: FeeTypePercentage, Rate: d("0.01"), MinFee: d("0.5"), MaxFee: d("5")},
+			amount:   "1000",
+			want:     "5",
+		},
+		{
+			name: "tiered",
+			schedule: &FeeSchedule{FeeType: FeeTypeTiered, Tiers: []*FeeTier{
+				{MinAmount: d("1000"), FlatAmount: d("2"), Rate: d("0.001")},
+				{MinAmount: d("0"), FlatAmount: d("1")},
+			}},
+			amount: "999.99",
+			want:   "1",
+		},
+		{
+			name: "tiered from the tier minimum",
+			schedule: &FeeSchedule{FeeType: FeeTypeTiered, Tiers: []*FeeTier{
+				{MinAmount: d("0"), FlatAmount: d("1")},
+				{MinAmount: d("1000"), FlatAmount: d("2"), Rate: d("0.001")},
+			}},
+			amount: "2000",
+			want:   "4",
+		},
+		{
+			name: "below every tier",
+			schedule: &FeeSchedule{FeeType: FeeTypeTiered, Tiers: []*FeeTier{
+				{MinAmount: d("100"), FlatAmount: d("1")},
+			}},
+			amount: "50",
+			want:   "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Compute(d(tt.amount)); !got.Equal(d(tt.want)) {
+				t.Errorf("Compute(%s) = %s, want %s", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_TransactionFee(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	transactionType := "instant-" + idx.UUID4()
+	err := s.SetFeeSchedule(ctx, &FeeSchedule{
+		TransactionType: transactionType,
+		Currency:        "USD",
+		FeeType:         FeeTypeFlat,
+		FlatAmount:      decimal.NewFromInt(2),
+	})
+	if err != nil {
+		t.Fatalf("SetFeeSchedule() error = %v", err)
+	}
+
+	from := createTestAccount(t, s, "100", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+	feeIncome, err := s.SystemAccountID(ctx, SystemAccountFeeIncome, "USD")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+	income := accountBalance(t, s, feeIncome)
+
+	transfer := func(amount string) (*TransactionRequest, error) {
+		request := &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: transactionType,
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        "USD",
+			Status:          TransactionStatusSuccess,
+		}
+		if err := s.PrepareFee(ctx, request); err != nil {
+			return nil, err
+		}
+		_, err := s.CreateTransaction(ctx, request)
+		return request, err
+	}
+
+	// the fee is checked together with the amount
+	if _, err = transfer("99"); !IsInsufficientFunds(err) {
+		t.Errorf("CreateTransaction() over the balance with the fee error = %v, want insufficient funds", err)
+	}
+
+	request, err := transfer("50")
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(48)) {
+		t.Errorf("sender balance = %s, want 48", got)
+	}
+	if got := accountBalance(t, s, feeIncome).Sub(income); !got.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("fee income = %s, want 2", got)
+	}
+
+	stored, err := s.GetTransaction(ctx, request.TransactionID)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if !stored.FeeAmount.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("fee amount = %s, want 2", stored.FeeAmount)
+	}
+
+	// a full reversal refunds the fee
+	_, _, err = s.ReverseTransaction(ctx, &ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: request.TransactionID,
+		Reason:                "mistake",
+	})
+	if err != nil {
+		t.Fatalf("ReverseTransaction() error = %v", err)
+	}
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("sender balance after reversal = %s, want 100", got)
+	}
+	if got := accountBalance(t, s, feeIncome); !got.Equal(income) {
+		t.Errorf("fee income after reversal = %s, want %s", got, income)
+	}
+}
+
+func TestFeeTransactionTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *TransactionRequest
+		want    []string
+	}{
+		{
+			name:    "transfer",
+			request: &TransactionRequest{TransactionType: "instant"},
+			want:    []string{"instant", TransactionTypeTransfer},
+		},
+		{
+			name:    "converted transfer",
+			request: &TransactionRequest{TransactionType: TransactionTypeTransfer, Conversion: &Conversion{}},
+			want:    []string{FeeTransactionTypeConversion, TransactionTypeTransfer},
+		},
+		{
+			name:    "withdrawal",
+			request: &TransactionRequest{TransactionType: TransactionTypeWithdrawal, AllowSystemAccounts: true},
+			want:    []string{TransactionTypeWithdrawal},
+		},
+		{
+			name:    "capture",
+			request: &TransactionRequest{TransactionType: TransactionTypeCapture, HoldID: idx.UUID4()},
+			want:    []string{TransactionTypeCapture},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feeTransactionTypes(tt.request); !slices.Equal(got, tt.want) {
+				t.Errorf("feeTransactionTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_PrepareFee_Fallback(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// no other test charges HUF transfers
+	for transactionType, amount := range map[string]int64{TransactionTypeTransfer: 3, FeeTransactionTypeConversion: 5} {
+		err := s.SetFeeSchedule(ctx, &FeeSchedule{
+			TransactionType: transactionType,
+			Currency:        "HUF",
+			FeeType:         FeeTypeFlat,
+			FlatAmount:      decimal.NewFromInt(amount),
+		})
+		if err != nil {
+			t.Fatalf("SetFeeSchedule() error = %v", err)
+		}
+	}
+
+	from := createTestAccount(t, s, "100", "HUF")
+	request := &TransactionRequest{
+		FromAccountID:   from,
+		TransactionType: "free-" + idx.UUID4(),
+		Amount:          decimal.NewFromInt(50),
+		Currency:        "HUF",
+	}
+	if err := s.PrepareFee(ctx, request); err != nil {
+		t.Fatalf("PrepareFee() error = %v", err)
+	}
+	if !request.feeAmount().Equal(decimal.NewFromInt(3)) {
+		t.Errorf("PrepareFee() of a type without a schedule = %s, want the transfer fee 3", request.feeAmount())
+	}
+
+	request.Conversion = &Conversion{}
+	if err := s.PrepareFee(ctx, request); err != nil {
+		t.Fatalf("PrepareFee() error = %v", err)
+	}
+	if !request.feeAmount().Equal(decimal.NewFromInt(5)) {
+		t.Errorf("PrepareFee() of a converted transfer = %s, want the conversion fee 5", request.feeAmount())
+	}
+}
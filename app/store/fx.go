@@ -0,0 +1,548 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/currency"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// Codes of the internal accounts that take the other side of currency conversions
+const (
+	// SystemAccountFXPosition holds the bank's position in a currency: it is
+	// credited with the money sold by customers and debited with the money bought
+	SystemAccountFXPosition = "fx_position"
+	// SystemAccountFXPnL is credited with the spread and the rounding leftover of conversions
+	SystemAccountFXPnL = "fx_pnl"
+)
+
+const (
+	// fxRatePlaces is the precision of the rates in dbank_fx_rates
+	fxRatePlaces = 10
+	// ledgerPlaces is the precision of the amounts in dbank_ledgers
+	ledgerPlaces = 6
+)
+
+// FXRate is the price of one unit of BaseCurrency in QuoteCurrency from EffectiveAt on.
+// Spread is the fraction of a converted amount that the bank keeps.
+// Source names the provider the rate came from and SnapshotID the stored
+// refresh it belongs to. Stale rates were older than the provider's maximum
+// age when they were fetched.
+type FXRate struct {
+	ID            string          `json:"id"`
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	Spread        decimal.Decimal `json:"spread"`
+	EffectiveAt   time.Time       `json:"effective_at"`
+	Source        string          `json:"source,omitempty"`
+	SnapshotID    string          `json:"snapshot_id,omitempty"`
+	Stale         bool            `json:"stale,omitempty"`
+}
+
+// FXSourceManual is the source of rates stored directly with CreateFXRate
+const FXSourceManual = "manual"
+
+// Conversion is an amount of the base currency converted at an FX rate.
+// MidAmount is the amount at the rate itself and ConvertedAmount is what
+// the receiver gets after the spread, rounded down to the minor units
+// of the quote currency.
+type Conversion struct {
+	Rate            *FXRate         `json:"rate"`
+	Amount          decimal.Decimal `json:"amount"`
+	MidAmount       decimal.Decimal `json:"mid_amount"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount"`
+}
+
+// PnL is the part of the mid amount the receiver does not get: the spread
+// and the rounding leftover, both of which are posted to the FX P&L account
+func (c *Conversion) PnL() decimal.Decimal {
+	return c.MidAmount.Sub(c.ConvertedAmount)
+}
+
+// Convert converts an amount of the base currency into the quote currency
+func (r *FXRate) Convert(amount decimal.Decimal) *Conversion {
+	mid := amount.Mul(r.Rate).Round(ledgerPlaces)
+	return &Conversion{
+		Rate:            r,
+		Amount:          amount,
+		MidAmount:       mid,
+		ConvertedAmount: currency.Get(r.QuoteCurrency).Truncate(mid.Mul(decimal.NewFromInt(1).Sub(r.Spread))),
+	}
+}
+
+// inverse returns the rate of the opposite currency pair
+func (r *FXRate) inverse() *FXRate {
+	return &FXRate{
+		ID:            r.ID,
+		BaseCurrency:  r.QuoteCurrency,
+		QuoteCurrency: r.BaseCurrency,
+		Rate:          decimal.NewFromInt(1).DivRound(r.Rate, fxRatePlaces),
+		Spread:        r.Spread,
+		EffectiveAt:   r.EffectiveAt,
+		Source:        r.Source,
+		SnapshotID:    r.SnapshotID,
+		Stale:         r.Stale,
+	}
+}
+
+// CreateFXRate stores a single rate in a snapshot of its own,
+// it applies to conversions from its EffectiveAt on
+func (s *Store) CreateFXRate(
+	ctx context.Context,
+	rate *FXRate,
+) error {
+	source := rate.Source
+	if source == "" {
+		source = FXSourceManual
+	}
+
+	_, err := s.CreateFXSnapshot(ctx, source, []*FXRate{rate})
+	return err
+}
+
+// CreateFXSnapshot stores the rates fetched by one refresh of a rate provider
+// and returns the snapshot id. Every rate gets a new id and the snapshot id.
+func (s *Store) CreateFXSnapshot(
+	ctx context.Context,
+	source string,
+	rates []*FXRate,
+) (string, error) {
+	snapshotID := idx.UUID4()
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Insert("dbank_fx_snapshots").
+			Columns("id", "source").
+			Values(snapshotID, source).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert fx snapshot", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert fx snapshot")
+		}
+
+		for _, rate := range rates {
+			rate.ID = idx.UUID4()
+			if rate.Source == "" {
+				rate.Source = source
+			}
+			rate.SnapshotID = snapshotID
+
+			sql, args, err = s.db.Builder.
+				Insert("dbank_fx_rates").
+				Columns(
+					"id", "base_currency", "quote_currency", "rate", "spread", "effective_at",
+					"snapshot_id", "source", "stale",
+				).
+				Values(
+					rate.ID, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.Spread, rate.EffectiveAt,
+					rate.SnapshotID, rate.Source, rate.Stale,
+				).
+				ToSql()
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+				return status.Errorf(codes.Internal, "failed to build SQL query")
+			}
+
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				s.logger.ErrorContext(ctx, "failed to insert fx rate", "error", err,
+					"base_currency", rate.BaseCurrency, "quote_currency", rate.QuoteCurrency)
+				return status.Errorf(codes.Internal, "failed to insert fx rate")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create fx snapshot", "error", err, "source", source)
+		return "", err
+	}
+
+	return snapshotID, nil
+}
+
+// LatestFXSnapshot returns the id and the rates of the latest snapshot of
+// source, the id is empty when source has not stored a snapshot yet
+func (s *Store) LatestFXSnapshot(
+	ctx context.Context,
+	source string,
+) (string, []*FXRate, error) {
+	sql, args, err := s.db.Builder.
+		Select("id").
+		From("dbank_fx_snapshots").
+		Where("source = ?", source).
+		OrderBy("pk DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var snapshotID string
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&snapshotID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to query fx snapshot", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to query fx snapshot")
+	}
+
+	sql, args, err = s.db.Builder.
+		Select(
+			"id", "base_currency", "quote_currency", "rate", "spread", "effective_at",
+			"source", "snapshot_id::text", "stale",
+		).
+		From("dbank_fx_rates").
+		Where("snapshot_id = ?", snapshotID).
+		OrderBy("pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query fx rates", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to query fx rates")
+	}
+
+	rates, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[FXRate])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan fx rates", "error", err)
+		return "", nil, status.Errorf(codes.Internal, "failed to scan fx rates")
+	}
+
+	return snapshotID, rates, nil
+}
+
+// SetManualFXRate stores a rate entered by an administrator. It is picked up
+// by the admin rate provider on the next refresh.
+func (s *Store) SetManualFXRate(
+	ctx context.Context,
+	rate *FXRate,
+) error {
+	if rate.ID == "" {
+		rate.ID = idx.UUID4()
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_fx_manual_rates").
+		Columns("id", "base_currency", "quote_currency", "rate", "spread", "effective_at").
+		Values(rate.ID, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.Spread, rate.EffectiveAt).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = s.db.Pool.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert manual fx rate", "error", err)
+		return status.Errorf(codes.Internal, "failed to insert manual fx rate")
+	}
+
+	return nil
+}
+
+// ManualFXRates returns the latest effective admin-entered rate of every currency pair
+func (s *Store) ManualFXRates(ctx context.Context) ([]*FXRate, error) {
+	sql, args, err := s.db.Builder.
+		Select("DISTINCT ON (base_currency, quote_currency) id",
+			"base_currency", "quote_currency", "rate", "spread", "effective_at").
+		From("dbank_fx_manual_rates").
+		Where("effective_at <= now()").
+		OrderBy("base_currency", "quote_currency", "effective_at DESC", "pk DESC").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query manual fx rates", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query manual fx rates")
+	}
+	defer rows.Close()
+
+	var rates []*FXRate
+	for rows.Next() {
+		var rate FXRate
+		err = rows.Scan(
+			&rate.ID,
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.Spread,
+			&rate.EffectiveAt,
+		)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan manual fx rate", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to scan manual fx rate")
+		}
+		rates = append(rates, &rate)
+	}
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query manual fx rates", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query manual fx rates")
+	}
+
+	return rates, nil
+}
+
+// GetFXRate returns the latest rate from base to quote that is effective at the
+// given time. A rate stored for the opposite pair is inverted.
+func (s *Store) GetFXRate(
+	ctx context.Context,
+	base, quote string,
+	at time.Time,
+) (*FXRate, error) {
+	sql, args, err := s.db.Builder.
+		Select(
+			"id", "base_currency", "quote_currency", "rate", "spread", "effective_at",
+			"COALESCE(snapshot_id::text, '')", "source", "stale",
+		).
+		From("dbank_fx_rates").
+		Where("((base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?))",
+			base, quote, quote, base).
+		Where("effective_at <= ?", at).
+		OrderBy("effective_at DESC", "pk DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var rate FXRate
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.Spread,
+		&rate.EffectiveAt,
+		&rate.SnapshotID,
+		&rate.Source,
+		&rate.Stale,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no fx rate from %s to %s", base, quote)
+		}
+		s.logger.ErrorContext(ctx, "failed to query fx rate", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query fx rate")
+	}
+
+	if rate.BaseCurrency != base {
+		return rate.inverse(), nil
+	}
+
+	return &rate, nil
+}
+
+// ConvertAmount converts an amount at the current rate from one currency to another
+func (s *Store) ConvertAmount(
+	ctx context.Context,
+	from, to string,
+	amount decimal.Decimal,
+) (*Conversion, error) {
+	rate, err := s.GetFXRate(ctx, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := rate.Convert(amount)
+	if !conversion.ConvertedAmount.IsPositive() {
+		return nil, status.Errorf(codes.InvalidArgument, "amount is too small to convert from %s to %s", from, to)
+	}
+
+	return conversion, nil
+}
+
+// PrepareConversion checks the currency of a transaction request against its
+// accounts and quotes the conversion of the amount when the receiving account
+// holds a different currency. The amount is always in the sender's currency.
+func (s *Store) PrepareConversion(
+	ctx context.Context,
+	request *TransactionRequest,
+) error {
+	from, to, err := s.transferCurrencies(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	request.Conversion = nil
+	if from == to {
+		return nil
+	}
+
+	request.Conversion, err = s.ConvertAmount(ctx, from, to, request.Amount)
+	return err
+}
+
+// CheckTransferCurrency checks that both accounts of a request exist and that
+// its currency is the currency of the sending account
+func (s *Store) CheckTransferCurrency(
+	ctx context.Context,
+	request *TransactionRequest,
+) error {
+	_, _, err := s.transferCurrencies(ctx, request)
+	return err
+}
+
+// transferCurrencies returns the currencies of the two accounts of a request
+// after checking the request currency against the sending account
+func (s *Store) transferCurrencies(
+	ctx context.Context,
+	request *TransactionRequest,
+) (string, string, error) {
+	sql, args, err := s.db.Builder.
+		Select("id", "currency").
+		From("dbank_accounts").
+		Where("id IN (?, ?)", request.FromAccountID, request.ToAccountID).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query account currencies", "error", err)
+		return "", "", status.Errorf(codes.Internal, "failed to query account currencies")
+	}
+	defer rows.Close()
+
+	currencies := make(map[string]string, 2)
+	for rows.Next() {
+		var id, currency string
+		if err = rows.Scan(&id, &currency); err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan account currency", "error", err)
+			return "", "", status.Errorf(codes.Internal, "failed to scan account currency")
+		}
+		currencies[id] = currency
+	}
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query account currencies", "error", err)
+		return "", "", status.Errorf(codes.Internal, "failed to query account currencies")
+	}
+
+	for _, id := range []string{request.FromAccountID, request.ToAccountID} {
+		if _, ok := currencies[id]; !ok {
+			return "", "", status.Errorf(codes.NotFound, "account %s not found", id)
+		}
+	}
+
+	from, to := currencies[request.FromAccountID], currencies[request.ToAccountID]
+	if request.Currency != from {
+		return "", "", status.Errorf(codes.InvalidArgument, "currency %s does not match the currency %s of account %s",
+			request.Currency, from, request.FromAccountID)
+	}
+
+	return from, to, nil
+}
+
+// checkConversion validates the currency and the quoted conversion of a
+// request against the two locked accounts
+func checkConversion(request *TransactionRequest, fromAccount, toAccount *lockedAccount) error {
+	if request.Currency != fromAccount.Currency {
+		return status.Errorf(codes.InvalidArgument, "currency %s does not match the currency %s of account %s",
+			request.Currency, fromAccount.Currency, fromAccount.ID)
+	}
+
+	conversion := request.Conversion
+	if fromAccount.Currency == toAccount.Currency {
+		if conversion != nil {
+			return status.Errorf(codes.InvalidArgument, "transfers within %s are not converted", fromAccount.Currency)
+		}
+		return nil
+	}
+
+	if conversion == nil ||
+		conversion.Rate.BaseCurrency != fromAccount.Currency ||
+		conversion.Rate.QuoteCurrency != toAccount.Currency ||
+		!conversion.Amount.Equal(request.Amount) {
+		return status.Errorf(codes.FailedPrecondition, "transfer from %s to %s needs a conversion quote",
+			fromAccount.Currency, toAccount.Currency)
+	}
+
+	return nil
+}
+
+// postConversion posts a converted transfer. The sender's money goes to the FX
+// position in its currency, the receiver is paid from the FX position in the
+// other currency and the spread and rounding leftover go to FX P&L, so the
+// postings balance in both currencies.
+// The internal accounts are locked after the two customer accounts, in the
+// same order as every other transfer, so conversions cannot deadlock.
+func (s *Store) postConversion(
+	ctx context.Context,
+	tx pgx.Tx,
+	request *TransactionRequest,
+	transactionPK int,
+	fromAccount, toAccount *lockedAccount,
+) ([]*LedgerEntry, error) {
+	conversion := request.Conversion
+
+	soldID, err := s.systemAccountID(ctx, tx, SystemAccountFXPosition, fromAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	boughtID, err := s.systemAccountID(ctx, tx, SystemAccountFXPosition, toAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	pnlID, err := s.systemAccountID(ctx, tx, SystemAccountFXPnL, toAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.lockAccounts(ctx, tx, soldID, boughtID, pnlID)
+	if err != nil {
+		return nil, err
+	}
+
+	postings := []struct {
+		account *lockedAccount
+		amount  decimal.Decimal
+	}{
+		{fromAccount, conversion.Amount.Neg()},
+		{accounts[soldID], conversion.Amount},
+		{accounts[boughtID], conversion.MidAmount.Neg()},
+		{toAccount, conversion.ConvertedAmount},
+		{accounts[pnlID], conversion.PnL()},
+	}
+
+	entries := make([]*LedgerEntry, 0, len(postings))
+	for _, posting := range postings {
+		if posting.amount.IsZero() {
+			continue
+		}
+
+		entry, err := s.postEntry(ctx, tx, posting.account, transactionPK, request.TransactionID,
+			posting.amount, posting.account.Currency)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestFXRate_Convert(t *testing.T) {
+	tests := []struct {
+		name          string
+		rate          string
+		spread        string
+		amount        string
+		wantMid       string
+		wantConverted string
+		wantPnL       string
+	}{
+		{name: "no spread", rate: "0.9", spread: "0", amount: "100", wantMid: "90", wantConverted: "90", wantPnL: "0"},
+		{name: "spread", rate: "0.9", spread: "0.01", amount: "100", wantMid: "90", wantConverted: "89.1", wantPnL: "0.9"},
+		{
+			name: "rounding leftover", rate: "1.0833333333", spread: "0", amount: "10",
+			wantMid: "10.833333", wantConverted: "10.83", wantPnL: "0.003333",
+		},
+		{
+			name: "spread and rounding", rate: "151.37", spread: "0.005", amount: "12.34",
+			wantMid: "1867.9058", wantConverted: "1858.56", wantPnL: "9.3458",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := &FXRate{
+				BaseCurrency:  "USD",
+				QuoteCurrency: "EUR",
+				Rate:          decimal.RequireFromString(tt.rate),
+				Spread:        decimal.RequireFromString(tt.spread),
+			}
+
+			got := rate.Convert(decimal.RequireFromString(tt.amount))
+			if !got.MidAmount.Equal(decimal.RequireFromString(tt.wantMid)) {
+				t.Errorf("MidAmount = %s, want %s", got.MidAmount, tt.wantMid)
+			}
+			if !got.ConvertedAmount.Equal(decimal.RequireFromString(tt.wantConverted)) {
+				t.Errorf("ConvertedAmount = %s, want %s", got.ConvertedAmount, tt.wantConverted)
+			}
+			if !got.PnL().Equal(decimal.RequireFromString(tt.wantPnL)) {
+				t.Errorf("PnL() = %s, want %s", got.PnL(), tt.wantPnL)
+			}
+		})
+	}
+}
+
+func TestStore_ConvertedTransfer(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	err := s.CreateFXRate(ctx, &FXRate{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "GBP",
+		Rate:          decimal.RequireFromString("0.8333333333"),
+		Spread:        decimal.RequireFromString("0.01"),
+		EffectiveAt:   time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateFXRate() error = %v", err)
+	}
+
+	eur := createTestAccount(t, s, "100", "EUR")
+	gbp := createTestAccount(t, s, "0", "GBP")
+
+	pnl, err := s.SystemAccountID(ctx, SystemAccountFXPnL, "GBP")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+	pnlBefore := accountBalance(t, s, pnl)
+
+	request := func(from, to, currency, amount string) *TransactionRequest {
+		return &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: TransactionTypeTransfer,
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        currency,
+			Status:          TransactionStatusSuccess,
+		}
+	}
+
+	mismatched := request(eur, createTestAccount(t, s, "0", "EUR"), "USD", "10")
+	if err = s.PrepareConversion(ctx, mismatched); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("PrepareConversion() with a mismatched currency error = %v, want InvalidArgument", err)
+	}
+	if _, err = s.CreateTransaction(ctx, mismatched); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateTransaction() with a mismatched currency error = %v, want InvalidArgument", err)
+	}
+
+	unquoted := request(eur, gbp, "EUR", "10")
+	if _, err = s.CreateTransaction(ctx, unquoted); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateTransaction() without a quote error = %v, want FailedPrecondition", err)
+	}
+
+	transfer := request(eur, gbp, "EUR", "10")
+	if err = s.PrepareConversion(ctx, transfer); err != nil {
+		t.Fatalf("PrepareConversion() error = %v", err)
+	}
+	entries, err := s.CreateTransaction(ctx, transfer)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	if len(entries) != 5 {
+		t.Errorf("CreateTransaction() posted %d entries, want 5", len(entries))
+	}
+
+	// 10 EUR at 0.8333333333 is 8.333333 GBP, the receiver gets 8.24 after
+	// the spread and rounding down, the bank keeps 0.093333
+	if got := accountBalance(t, s, eur); !got.Equal(decimal.NewFromInt(90)) {
+		t.Errorf("EUR balance = %s, want 90", got)
+	}
+	if got := accountBalance(t, s, gbp); !got.Equal(decimal.RequireFromString("8.24")) {
+		t.Errorf("GBP balance = %s, want 8.24", got)
+	}
+	if got := accountBalance(t, s, pnl).Sub(pnlBefore); !got.Equal(decimal.RequireFromString("0.093333")) {
+		t.Errorf("FX P&L change = %s, want 0.093333", got)
+	}
+
+	stored, err := s.GetTransaction(ctx, transfer.TransactionID)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if stored.ToCurrency != "GBP" || !stored.ToAmount.Equal(decimal.RequireFromString("8.24")) ||
+		stored.FXRateID != transfer.Conversion.Rate.ID || !stored.FXSpread.Equal(decimal.RequireFromString("0.01")) ||
+		stored.FXSnapshotID == "" || stored.FXSnapshotID != transfer.Conversion.Rate.SnapshotID {
+		t.Errorf("GetTransaction() = %+v, want 8.24 GBP at the quoted rate", stored)
+	}
+
+	_, _, err = s.ReverseTransaction(ctx, &ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: transfer.TransactionID,
+		Amount:                decimal.NewFromInt(5),
+		Reason:                "partial",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("partial ReverseTransaction() error = %v, want InvalidArgument", err)
+	}
+
+	reversal, _, err := s.ReverseTransaction(ctx, &ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: transfer.TransactionID,
+		Reason:                "mistake",
+	})
+	if err != nil {
+		t.Fatalf("ReverseTransaction() error = %v", err)
+	}
+	if reversal.Currency != "GBP" || !reversal.Amount.Equal(decimal.RequireFromString("8.24")) {
+		t.Errorf("ReverseTransaction() = %+v, want 8.24 GBP moved back", reversal)
+	}
+	if got := accountBalance(t, s, eur); !got.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("EUR balance after reversal = %s, want 100", got)
+	}
+	if got := accountBalance(t, s, gbp); !got.IsZero() {
+		t.Errorf("GBP balance after reversal = %s, want 0", got)
+	}
+	if got := accountBalance(t, s, pnl); !got.Equal(pnlBefore) {
+		t.Errorf("FX P&L after reversal = %s, want %s", got, pnlBefore)
+	}
+}
+
+func TestStore_GetFXRate_Inverse(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	err := s.CreateFXRate(ctx, &FXRate{
+		BaseCurrency:  "CHF",
+		QuoteCurrency: "SEK",
+		Rate:          decimal.RequireFromString("12.5"),
+		Spread:        decimal.Zero,
+		EffectiveAt:   time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateFXRate() error = %v", err)
+	}
+
+	rate, err := s.GetFXRate(ctx, "SEK", "CHF", time.Now())
+	if err != nil {
+		t.Fatalf("GetFXRate() error = %v", err)
+	}
+	if rate.BaseCurrency != "SEK" || rate.QuoteCurrency != "CHF" || !rate.Rate.Equal(decimal.RequireFromString("0.08")) {
+		t.Errorf("GetFXRate() = %+v, want SEK/CHF at 0.08", rate)
+	}
+
+	if _, err = s.GetFXRate(ctx, "CHF", "XXX", time.Now()); status.Code(err) != codes.NotFound {
+		t.Errorf("GetFXRate() for an unknown pair error = %v, want NotFound", err)
+	}
+}
+
+func TestStore_FXSnapshots(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	effectiveAt := time.Now().Add(-time.Second)
+	snapshotID, err := s.CreateFXSnapshot(ctx, "composite", []*FXRate{
+		{
+			BaseCurrency: "NOK", QuoteCurrency: "DKK", Rate: decimal.RequireFromString("0.64"),
+			EffectiveAt: effectiveAt, Source: "file", Stale: true,
+		},
+		{BaseCurrency: "NOK", QuoteCurrency: "PLN", Rate: decimal.RequireFromString("0.37"), EffectiveAt: effectiveAt},
+	})
+	if err != nil {
+		t.Fatalf("CreateFXSnapshot() error = %v", err)
+	}
+
+	latestID, latest, err := s.LatestFXSnapshot(ctx, "composite")
+	if err != nil {
+		t.Fatalf("LatestFXSnapshot() error = %v", err)
+	}
+	if latestID != snapshotID || len(latest) != 2 {
+		t.Errorf("LatestFXSnapshot() = %s with %d rates, want %s with 2 rates", latestID, len(latest), snapshotID)
+	}
+
+	rate, err := s.GetFXRate(ctx, "NOK", "DKK", time.Now())
+	if err != nil {
+		t.Fatalf("GetFXRate() error = %v", err)
+	}
+	if rate.SnapshotID != snapshotID || rate.Source != "file" || !rate.Stale {
+		t.Errorf("GetFXRate() = %+v, want the stale file rate of snapshot %s", rate, snapshotID)
+	}
+
+	rate, err = s.GetFXRate(ctx, "NOK", "PLN", time.Now())
+	if err != nil {
+		t.Fatalf("GetFXRate() error = %v", err)
+	}
+	if rate.SnapshotID != snapshotID || rate.Source != "composite" || rate.Stale {
+		t.Errorf("GetFXRate() = %+v, want the fresh rate of snapshot %s", rate, snapshotID)
+	}
+
+	for _, value := range []string{"0.1", "0.2"} {
+		err = s.SetManualFXRate(ctx, &FXRate{
+			BaseCurrency: "NOK", QuoteCurrency: "ISK", Rate: decimal.RequireFromString(value), EffectiveAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("SetManualFXRate() error = %v", err)
+		}
+	}
+	err = s.SetManualFXRate(ctx, &FXRate{
+		BaseCurrency: "NOK", QuoteCurrency: "ISK", Rate: decimal.RequireFromString("0.3"),
+		EffectiveAt: time.Now().AddDate(100, 0, 0),
+	})
+	if err != nil {
+		t.Fatalf("SetManualFXRate() error = %v", err)
+	}
+
+	rates, err := s.ManualFXRates(ctx)
+	if err != nil {
+		t.Fatalf("ManualFXRates() error = %v", err)
+	}
+	var found bool
+	for _, rate := range rates {
+		if rate.BaseCurrency == "NOK" && rate.QuoteCurrency == "ISK" {
+			found = true
+			if !rate.Rate.Equal(decimal.RequireFromString("0.2")) {
+				t.Errorf("ManualFXRates() NOK/ISK = %s, want the latest effective rate 0.2", rate.Rate)
+			}
+		}
+	}
+	if !found {
+		t.Error("ManualFXRates() has no NOK/ISK rate")
+	}
+}
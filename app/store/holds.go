@@ -0,0 +1,466 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+const (
+	HoldStatusAuthorized = "authorized"
+	HoldStatusCaptured   = "captured"
+	HoldStatusVoided     = "voided"
+	HoldStatusExpired    = "expired"
+)
+
+// TransactionTypeCapture is the type of the transactions that capture a hold
+const TransactionTypeCapture = "capture"
+
+// expireHoldsBatch is the number of accounts ExpireHolds releases per call
+const expireHoldsBatch = 100
+
+// Hold reserves Amount on an account for a later payment to ToAccountID.
+// While it is authorized the amount counts towards the held balance of the
+// account and cannot be spent. It is captured, fully or partially, voided,
+// or expires at ExpiresAt, and each of them releases the whole amount.
+type Hold struct {
+	ID             string          `json:"id"`
+	AccountID      string          `json:"account_id"`
+	ToAccountID    string          `json:"to_account_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Description    string          `json:"description"`
+	Status         string          `json:"status"`
+	CapturedAmount decimal.Decimal `json:"captured_amount"`
+	TransactionID  string          `json:"transaction_id,omitempty"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// holdColumns are the columns scanned by scanHold, from dbank_holds h
+// joined with the held account a
+var holdColumns = []string{
+	"h.id", "a.id", "h.to_account_id", "h.amount", "h.currency", "h.description", "h.status",
+	"COALESCE(h.captured_amount, 0)", "COALESCE(h.transaction_id::text, '')",
+	"h.expires_at", "h.created_at", "h.updated_at",
+}
+
+func scanHold(row pgx.Row) (*Hold, error) {
+	var hold Hold
+	err := row.Scan(
+		&hold.ID,
+		&hold.AccountID,
+		&hold.ToAccountID,
+		&hold.Amount,
+		&hold.Currency,
+		&hold.Description,
+		&hold.Status,
+		&hold.CapturedAmount,
+		&hold.TransactionID,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// AuthorizeHold reserves the amount of hold on its account if the available
+// balance covers it. The caller sets ExpiresAt.
+func (s *Store) AuthorizeHold(
+	ctx context.Context,
+	hold *Hold,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		accounts, err := s.lockAccounts(ctx, tx, hold.AccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[hold.AccountID]
+		if account.SystemCode != "" || accounts[hold.ToAccountID].SystemCode != "" {
+			return status.Errorf(codes.PermissionDenied, "internal accounts cannot take part in holds")
+		}
+
+		if err = checkAccountStatuses(account, accounts[hold.ToAccountID]); err != nil {
+			return err
+		}
+
+		if hold.Currency != account.Currency {
+			return status.Errorf(codes.InvalidArgument, "currency %s does not match the currency %s of account %s",
+				hold.Currency, account.Currency, account.ID)
+		}
+
+		if _, err = s.releaseExpiredHolds(ctx, tx, account); err != nil {
+			return err
+		}
+
+		if account.available().LessThan(hold.Amount) {
+			return insufficientFundsError()
+		}
+
+		hold.ID = idx.UUID4()
+		hold.Status = HoldStatusAuthorized
+
+		sql, args, err := s.db.Builder.
+			Insert("dbank_holds").
+			Columns("id", "account_pk", "to_account_id", "amount", "currency", "description", "status", "expires_at").
+			Values(hold.ID, account.PK, hold.ToAccountID, hold.Amount, hold.Currency, hold.Description,
+				hold.Status, hold.ExpiresAt).
+			Suffix("RETURNING created_at, updated_at").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&hold.CreatedAt, &hold.UpdatedAt); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert hold", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert hold")
+		}
+
+		return s.adjustHeldBalance(ctx, tx, account, hold.Amount)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to authorize hold", "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "hold authorized", "hold_id", hold.ID, "account_id", hold.AccountID)
+	return nil
+}
+
+// GetHold returns a hold by id
+func (s *Store) GetHold(
+	ctx context.Context,
+	id string,
+) (*Hold, error) {
+	sql, args, err := s.db.Builder.
+		Select(holdColumns...).
+		From("dbank_holds h").
+		Join("dbank_accounts a ON a.pk = h.account_pk").
+		Where("h.id = ?", id).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	hold, err := scanHold(s.db.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "hold not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query hold", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query hold")
+	}
+
+	return hold, nil
+}
+
+// lockHold locks a hold for the lifetime of tx. Its account must have been
+// locked first, in the same order as every other balance change.
+func (s *Store) lockHold(
+	ctx context.Context,
+	tx pgx.Tx,
+	id string,
+) (*Hold, error) {
+	sql, args, err := s.db.Builder.
+		Select(holdColumns...).
+		From("dbank_holds h").
+		Join("dbank_accounts a ON a.pk = h.account_pk").
+		Where("h.id = ?", id).
+		Suffix("FOR UPDATE OF h").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	hold, err := scanHold(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "hold not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to lock hold", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to lock hold")
+	}
+
+	return hold, nil
+}
+
+// VoidHold cancels an authorized hold and releases its amount
+func (s *Store) VoidHold(
+	ctx context.Context,
+	id string,
+) (*Hold, error) {
+	hold, err := s.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		accounts, err := s.lockAccounts(ctx, tx, hold.AccountID)
+		if err != nil {
+			return err
+		}
+		account := accounts[hold.AccountID]
+
+		if _, err = s.releaseExpiredHolds(ctx, tx, account); err != nil {
+			return err
+		}
+
+		if hold, err = s.lockHold(ctx, tx, id); err != nil {
+			return err
+		}
+
+		if hold.Status != HoldStatusAuthorized {
+			return errHoldNotAuthorized("void", hold.Status)
+		}
+
+		hold.Status = HoldStatusVoided
+		if err = s.saveHold(ctx, tx, hold); err != nil {
+			return err
+		}
+
+		return s.adjustHeldBalance(ctx, tx, account, hold.Amount.Neg())
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to void hold", "error", err, "id", id)
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// captureHold settles the hold of a transaction request on its locked sender.
+// The hold must be authorized for the same accounts and currency and cover
+// the amount. The whole hold is released, a partial capture frees the rest.
+func (s *Store) captureHold(
+	ctx context.Context,
+	tx pgx.Tx,
+	request *TransactionRequest,
+	fromAccount, toAccount *lockedAccount,
+) error {
+	hold, err := s.lockHold(ctx, tx, request.HoldID)
+	if err != nil {
+		return err
+	}
+
+	if hold.AccountID != fromAccount.ID || hold.ToAccountID != toAccount.ID {
+		return status.Errorf(codes.InvalidArgument, "hold %s is not for a transfer from %s to %s",
+			hold.ID, fromAccount.ID, toAccount.ID)
+	}
+
+	// expired holds were released by releaseExpiredHolds before
+	if hold.Status != HoldStatusAuthorized {
+		return errHoldNotAuthorized("capture", hold.Status)
+	}
+
+	if request.Currency != hold.Currency {
+		return status.Errorf(codes.InvalidArgument, "currency %s does not match the hold currency %s",
+			request.Currency, hold.Currency)
+	}
+
+	if request.Amount.GreaterThan(hold.Amount) {
+		return status.Errorf(codes.InvalidArgument, "capture amount exceeds the held amount %s", hold.Amount)
+	}
+
+	hold.Status = HoldStatusCaptured
+	hold.CapturedAmount = request.Amount
+	hold.TransactionID = request.TransactionID
+	if err = s.saveHold(ctx, tx, hold); err != nil {
+		return err
+	}
+
+	return s.adjustHeldBalance(ctx, tx, fromAccount, hold.Amount.Neg())
+}
+
+func errHoldNotAuthorized(action, holdStatus string) error {
+	return status.Errorf(codes.FailedPrecondition, "cannot %s a hold that is %s", action, holdStatus)
+}
+
+// saveHold writes the status and capture of a locked hold
+func (s *Store) saveHold(
+	ctx context.Context,
+	tx pgx.Tx,
+	hold *Hold,
+) error {
+	var capturedAmount, transactionID any
+	if hold.Status == HoldStatusCaptured {
+		capturedAmount, transactionID = hold.CapturedAmount, hold.TransactionID
+	}
+
+	sql, args, err := s.db.Builder.
+		Update("dbank_holds").
+		Set("status", hold.Status).
+		Set("captured_amount", capturedAmount).
+		Set("transaction_id", transactionID).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("id = ?", hold.ID).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&hold.UpdatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update hold", "error", err, "id", hold.ID)
+		return status.Errorf(codes.Internal, "failed to update hold")
+	}
+
+	return nil
+}
+
+// adjustHeldBalance applies a relative change to the held balance of an
+// account that was previously locked by lockAccounts
+func (s *Store) adjustHeldBalance(
+	ctx context.Context,
+	tx pgx.Tx,
+	account *lockedAccount,
+	delta decimal.Decimal,
+) error {
+	sql, args, err := s.db.Builder.
+		Update("dbank_accounts").
+		Set("held_balance", squirrel.Expr("held_balance + ?", delta)).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("pk = ?", account.PK).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update held balance", "error", err, "id", account.ID)
+		return status.Errorf(codes.Internal, "failed to update held balance")
+	}
+
+	account.HeldBalance = account.HeldBalance.Add(delta)
+	return nil
+}
+
+// releaseExpiredHolds expires the authorized holds of a locked account whose
+// time is up and releases their amount. It returns the number of holds.
+func (s *Store) releaseExpiredHolds(
+	ctx context.Context,
+	tx pgx.Tx,
+	account *lockedAccount,
+) (int, error) {
+	if !account.HeldBalance.IsPositive() {
+		return 0, nil
+	}
+
+	sql, args, err := s.db.Builder.
+		Update("dbank_holds").
+		Set("status", HoldStatusExpired).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("account_pk = ?", account.PK).
+		Where("status = ?", HoldStatusAuthorized).
+		Where("expires_at <= now()").
+		Suffix("RETURNING amount").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to expire holds", "error", err, "account_id", account.ID)
+		return 0, status.Errorf(codes.Internal, "failed to expire holds")
+	}
+
+	var (
+		released = decimal.Zero
+		count    int
+	)
+	for rows.Next() {
+		var amount decimal.Decimal
+		if err = rows.Scan(&amount); err != nil {
+			rows.Close()
+			s.logger.ErrorContext(ctx, "failed to scan expired hold", "error", err)
+			return 0, status.Errorf(codes.Internal, "failed to scan expired hold")
+		}
+		released = released.Add(amount)
+		count++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to expire holds", "error", err, "account_id", account.ID)
+		return 0, status.Errorf(codes.Internal, "failed to expire holds")
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	return count, s.adjustHeldBalance(ctx, tx, account, released.Neg())
+}
+
+// ExpireHolds releases the holds whose time is up, for up to
+// expireHoldsBatch accounts, and returns the number of expired holds.
+// Transfers and authorizations release the expired holds of the sender
+// themselves, this keeps the available balance of idle accounts current.
+func (s *Store) ExpireHolds(ctx context.Context) (int, error) {
+	sql, args, err := s.db.Builder.
+		Select("DISTINCT a.id").
+		From("dbank_holds h").
+		Join("dbank_accounts a ON a.pk = h.account_pk").
+		Where("h.status = ?", HoldStatusAuthorized).
+		Where("h.expires_at <= now()").
+		Limit(expireHoldsBatch).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query expired holds", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to query expired holds")
+	}
+
+	accountIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan expired holds", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to scan expired holds")
+	}
+
+	var expired int
+	for _, accountID := range accountIDs {
+		// counted once the transaction commits, a rolled back one
+		// expired nothing
+		var count int
+		err = dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+			accounts, err := s.lockAccounts(ctx, tx, accountID)
+			if err != nil {
+				return err
+			}
+
+			count, err = s.releaseExpiredHolds(ctx, tx, accounts[accountID])
+			return err
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to expire holds", "error", err, "account_id", accountID)
+			return expired, err
+		}
+		expired += count
+	}
+
+	return expired, nil
+}
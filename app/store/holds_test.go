@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// heldBalance reads the held and available balance of an account straight from the table
+func heldBalance(t *testing.T, s *Store, accountID string) (decimal.Decimal, decimal.Decimal) {
+	t.Helper()
+
+	var held, available decimal.Decimal
+	err := s.db.Pool.QueryRow(context.Background(),
+		"SELECT held_balance, available_balance FROM dbank_accounts WHERE id = $1", accountID,
+	).Scan(&held, &available)
+	if err != nil {
+		t.Fatalf("failed to read held balance: %v", err)
+	}
+	return held, available
+}
+
+func TestStore_Holds(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "100", "USD")
+	merchant := createTestAccount(t, s, "0", "USD")
+
+	authorize := func(amount string, ttl time.Duration) (*Hold, error) {
+		hold := &Hold{
+			AccountID:   from,
+			ToAccountID: merchant,
+			Amount:      decimal.RequireFromString(amount),
+			Currency:    "USD",
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		return hold, s.AuthorizeHold(ctx, hold)
+	}
+	transfer := func(amount, holdID string) error {
+		_, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     merchant,
+			TransactionType: TransactionTypeCapture,
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        "USD",
+			Status:          TransactionStatusSuccess,
+			HoldID:          holdID,
+		})
+		return err
+	}
+	checkBalances := func(balance, held, available string) {
+		t.Helper()
+		gotHeld, gotAvailable := heldBalance(t, s, from)
+		if got := accountBalance(t, s, from); !got.Equal(decimal.RequireFromString(balance)) {
+			t.Errorf("balance = %s, want %s", got, balance)
+		}
+		if !gotHeld.Equal(decimal.RequireFromString(held)) || !gotAvailable.Equal(decimal.RequireFromString(available)) {
+			t.Errorf("held, available = %s, %s, want %s, %s", gotHeld, gotAvailable, held, available)
+		}
+	}
+
+	hold, err := authorize("60", time.Hour)
+	if err != nil {
+		t.Fatalf("AuthorizeHold() error = %v", err)
+	}
+	checkBalances("100", "60", "40")
+
+	// held money cannot be spent or held again
+	if err = transfer("50", ""); !IsInsufficientFunds(err) {
+		t.Errorf("CreateTransaction() over the available balance error = %v, want insufficient funds", err)
+	}
+	if _, err = authorize("50", time.Hour); !IsInsufficientFunds(err) {
+		t.Errorf("AuthorizeHold() over the available balance error = %v, want insufficient funds", err)
+	}
+
+	// a partial capture pays the amount and releases the whole hold
+	if err = transfer("70", hold.ID); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("capture over the held amount error = %v, want InvalidArgument", err)
+	}
+	if err = transfer("45", hold.ID); err != nil {
+		t.Fatalf("capture error = %v", err)
+	}
+	checkBalances("55", "0", "55")
+
+	captured, err := s.GetHold(ctx, hold.ID)
+	if err != nil {
+		t.Fatalf("GetHold() error = %v", err)
+	}
+	if captured.Status != HoldStatusCaptured || !captured.CapturedAmount.Equal(decimal.NewFromInt(45)) ||
+		captured.TransactionID == "" {
+		t.Errorf("captured hold = %+v, want 45 captured", captured)
+	}
+	if err = transfer("1", hold.ID); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second capture error = %v, want FailedPrecondition", err)
+	}
+
+	// a voided hold is released and cannot be captured
+	hold, err = authorize("20", time.Hour)
+	if err != nil {
+		t.Fatalf("AuthorizeHold() error = %v", err)
+	}
+	if _, err = s.VoidHold(ctx, hold.ID); err != nil {
+		t.Fatalf("VoidHold() error = %v", err)
+	}
+	checkBalances("55", "0", "55")
+	if err = transfer("20", hold.ID); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("capture of a voided hold error = %v, want FailedPrecondition", err)
+	}
+
+	// an expired hold stops counting and is released by ExpireHolds
+	hold, err = authorize("30", time.Millisecond)
+	if err != nil {
+		t.Fatalf("AuthorizeHold() error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err = s.ExpireHolds(ctx); err != nil {
+		t.Fatalf("ExpireHolds() error = %v", err)
+	}
+	checkBalances("55", "0", "55")
+
+	expired, err := s.GetHold(ctx, hold.ID)
+	if err != nil {
+		t.Fatalf("GetHold() error = %v", err)
+	}
+	if expired.Status != HoldStatusExpired {
+		t.Errorf("hold status = %s, want %s", expired.Status, HoldStatusExpired)
+	}
+	if _, err = s.VoidHold(ctx, hold.ID); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("VoidHold() of an expired hold error = %v, want FailedPrecondition", err)
+	}
+}
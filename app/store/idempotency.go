@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IdempotencyKey is a client supplied key together with a hash of the request
+// it was first used with and the response that request produced
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	Response    []byte    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GetIdempotencyKey returns a stored idempotency key or a NotFound error
+func (s *Store) GetIdempotencyKey(
+	ctx context.Context,
+	key string,
+) (*IdempotencyKey, error) {
+	sql, args, err := s.db.Builder.
+		Select("key", "request_hash", "response", "created_at").
+		From("dbank_idempotency_keys").
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var idempotencyKey IdempotencyKey
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&idempotencyKey.Key,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.Response,
+		&idempotencyKey.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "idempotency key not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query idempotency key", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query idempotency key")
+	}
+
+	return &idempotencyKey, nil
+}
+
+// saveIdempotencyKey stores the key inside the caller's transaction.
+// If another transaction already stored the same key an AlreadyExists error is
+// returned so that the caller rolls back instead of applying the request twice.
+func (s *Store) saveIdempotencyKey(
+	ctx context.Context,
+	tx pgx.Tx,
+	key *IdempotencyKey,
+) error {
+	sql, args, err := s.db.Builder.
+		Insert("dbank_idempotency_keys").
+		Columns("key", "request_hash", "response").
+		Values(key.Key, key.RequestHash, key.Response).
+		Suffix("ON CONFLICT (key) DO NOTHING").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to save idempotency key", "error", err)
+		return status.Errorf(codes.Internal, "failed to save idempotency key")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return status.Errorf(codes.AlreadyExists, "idempotency key already used")
+	}
+
+	return nil
+}
@@ -0,0 +1,713 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/currency"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// SystemAccountInterestExpense is debited with the interest paid to customers
+const SystemAccountInterestExpense = "interest_expense"
+
+// TransactionTypeInterest is the monthly posting of accrued interest
+const TransactionTypeInterest = "interest"
+
+// Day-count conventions of interest plans, they set the fraction of a year
+// a day accrues interest for
+const (
+	// DayCountACT365 counts every day as 1/365 of a year
+	DayCountACT365 = "ACT/365"
+	// DayCountACT360 counts every day as 1/360 of a year
+	DayCountACT360 = "ACT/360"
+	// DayCount30360 counts every month as 30 days of a 360 day year: the
+	// 31st accrues nothing and the last day of February makes up the rest
+	DayCount30360 = "30/360"
+)
+
+// DayCounts are the supported day-count conventions
+var DayCounts = []string{DayCountACT365, DayCountACT360, DayCount30360}
+
+// accrualPlaces is the precision of the amounts in dbank_interest_accruals
+const accrualPlaces = 10
+
+// InterestPlan is how accounts of AccountType earn interest.
+// Tiers are banded: each tier pays its rate on the part of the balance
+// between its MinBalance and the MinBalance of the next tier.
+type InterestPlan struct {
+	AccountType string          `json:"account_type"`
+	DayCount    string          `json:"day_count"`
+	Tiers       []*InterestTier `json:"tiers"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// InterestTier is a band of an interest plan. AnnualRate is a fraction,
+// 0.025 pays 2.5% a year.
+type InterestTier struct {
+	MinBalance decimal.Decimal `json:"min_balance"`
+	AnnualRate decimal.Decimal `json:"annual_rate"`
+}
+
+// TierAccrual is the interest a tier earned on its band of a balance
+type TierAccrual struct {
+	MinBalance decimal.Decimal `json:"min_balance"`
+	AnnualRate decimal.Decimal `json:"annual_rate"`
+	Balance    decimal.Decimal `json:"balance"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+// InterestAccrual is the interest an account earned on the end-of-day
+// balance of Date, with the share of every tier of the plan
+type InterestAccrual struct {
+	Date        time.Time       `json:"date"`
+	Balance     decimal.Decimal `json:"balance"`
+	DayCount    string          `json:"day_count"`
+	DayFraction decimal.Decimal `json:"day_fraction"`
+	Tiers       []*TierAccrual  `json:"tiers"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// InterestRun is the outcome of RunInterest for a date: the number of
+// accruals and postings it stored. A rerun of the same date stores none.
+type InterestRun struct {
+	Date     time.Time `json:"date"`
+	Accruals int       `json:"accruals"`
+	Postings int       `json:"postings"`
+
+	// Payments are the interest transactions of the postings that paid money
+	Payments []*PostedTransaction `json:"-"`
+}
+
+// PostedTransaction is a transaction the system posted by itself, such as
+// an interest payment, with its ledger entries
+type PostedTransaction struct {
+	Transaction *TransactionRequest
+	Entries     []*LedgerEntry
+}
+
+// dayFraction returns the fraction of a year the day date accrues interest for
+func dayFraction(dayCount string, date time.Time) (decimal.Decimal, error) {
+	switch dayCount {
+	case DayCountACT365:
+		return decimal.NewFromInt(1).Div(decimal.NewFromInt(365)), nil
+	case DayCountACT360:
+		return decimal.NewFromInt(1).Div(decimal.NewFromInt(360)), nil
+	case DayCount30360:
+		return decimal.NewFromInt(accrualDays30360(date)).Div(decimal.NewFromInt(360)), nil
+	default:
+		return decimal.Zero, status.Errorf(codes.InvalidArgument, "unsupported day count %q", dayCount)
+	}
+}
+
+// accrualDays30360 returns the days the day date accrues for by the 30/360 bond
+// basis. The 31st accrues nothing, the 30th accrues its day up to the 1st of
+// the next month and the last day of February makes up the rest of its month.
+func accrualDays30360(date time.Time) int64 {
+	if date.Day() == 31 {
+		return 0
+	}
+
+	next := date.AddDate(0, 0, 1)
+	if next.Day() == 31 {
+		next = next.AddDate(0, 0, 1)
+	}
+	return days30360(date, next)
+}
+
+// days30360 returns the days between from and to by the 30/360 bond basis
+func days30360(from, to time.Time) int64 {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+}
+
+// Accrue returns the interest a balance earns on the day date, balances
+// that are not positive earn nothing
+func (p *InterestPlan) Accrue(balance decimal.Decimal, date time.Time) (*InterestAccrual, error) {
+	fraction, err := dayFraction(p.DayCount, date)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers := slices.Clone(p.Tiers)
+	slices.SortFunc(tiers, func(a, b *InterestTier) int {
+		return a.MinBalance.Cmp(b.MinBalance)
+	})
+
+	accrual := &InterestAccrual{
+		Date:        date,
+		Balance:     balance,
+		DayCount:    p.DayCount,
+		DayFraction: fraction.Round(accrualPlaces),
+		Tiers:       make([]*TierAccrual, 0, len(tiers)),
+		Amount:      decimal.Zero,
+	}
+	for i, tier := range tiers {
+		if balance.LessThanOrEqual(tier.MinBalance) {
+			break
+		}
+
+		band := balance
+		if i+1 < len(tiers) && band.GreaterThan(tiers[i+1].MinBalance) {
+			band = tiers[i+1].MinBalance
+		}
+		band = band.Sub(tier.MinBalance)
+
+		amount := band.Mul(tier.AnnualRate).Mul(fraction).Round(accrualPlaces)
+		accrual.Tiers = append(accrual.Tiers, &TierAccrual{
+			MinBalance: tier.MinBalance,
+			AnnualRate: tier.AnnualRate,
+			Balance:    band,
+			Amount:     amount,
+		})
+		accrual.Amount = accrual.Amount.Add(amount)
+	}
+
+	return accrual, nil
+}
+
+// SetInterestPlan creates or replaces the interest plan of an account type
+func (s *Store) SetInterestPlan(
+	ctx context.Context,
+	plan *InterestPlan,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Insert("dbank_interest_plans").
+			Columns("account_type", "day_count").
+			Values(plan.AccountType, plan.DayCount).
+			Suffix("ON CONFLICT (account_type) DO UPDATE SET day_count = EXCLUDED.day_count, updated_at = now()").
+			Suffix("RETURNING pk, updated_at").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var planPK int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&planPK, &plan.UpdatedAt); err != nil {
+			s.logger.ErrorContext(ctx, "failed to save interest plan", "error", err)
+			return status.Errorf(codes.Internal, "failed to save interest plan")
+		}
+
+		sql, args, err = s.db.Builder.
+			Delete("dbank_interest_tiers").
+			Where("plan_pk = ?", planPK).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to delete interest tiers", "error", err)
+			return status.Errorf(codes.Internal, "failed to delete interest tiers")
+		}
+
+		insert := s.db.Builder.
+			Insert("dbank_interest_tiers").
+			Columns("plan_pk", "min_balance", "annual_rate")
+		for _, tier := range plan.Tiers {
+			insert = insert.Values(planPK, tier.MinBalance, tier.AnnualRate)
+		}
+
+		sql, args, err = insert.ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert interest tiers", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert interest tiers")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to set interest plan", "error", err, "account_type", plan.AccountType)
+		return err
+	}
+
+	return nil
+}
+
+// GetInterestPlans returns the interest plans by account type
+func (s *Store) GetInterestPlans(ctx context.Context) (map[string]*InterestPlan, error) {
+	sql, args, err := s.db.Builder.
+		Select("p.account_type", "p.day_count", "p.updated_at", "t.min_balance", "t.annual_rate").
+		From("dbank_interest_plans p").
+		Join("dbank_interest_tiers t ON t.plan_pk = p.pk").
+		OrderBy("p.account_type", "t.min_balance").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query interest plans", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query interest plans")
+	}
+	defer rows.Close()
+
+	plans := make(map[string]*InterestPlan)
+	for rows.Next() {
+		var plan InterestPlan
+		var tier InterestTier
+		err = rows.Scan(&plan.AccountType, &plan.DayCount, &plan.UpdatedAt, &tier.MinBalance, &tier.AnnualRate)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan interest plan", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to scan interest plan")
+		}
+
+		if _, ok := plans[plan.AccountType]; !ok {
+			plans[plan.AccountType] = &plan
+		}
+		plans[plan.AccountType].Tiers = append(plans[plan.AccountType].Tiers, &tier)
+	}
+
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to iterate interest plans", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to iterate interest plans")
+	}
+
+	return plans, nil
+}
+
+// RunInterest accrues the interest of the day date on the end-of-day balance
+// of every account with an interest plan and, on the last day of a month,
+// posts the accrued interest from the interest expense account.
+// Accounts that already accrued for date or were posted for the month are
+// skipped, so that a rerun for the same date changes nothing.
+func (s *Store) RunInterest(ctx context.Context, date time.Time) (*InterestRun, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	dayEnd := date.AddDate(0, 0, 1)
+	if dayEnd.After(time.Now()) {
+		return nil, status.Errorf(codes.InvalidArgument, "interest can only be run for days that have ended")
+	}
+
+	plans, err := s.GetInterestPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &InterestRun{Date: date}
+	accounts, err := s.interestAccounts(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		accrued, err := s.accrueInterest(ctx, account, plans[account.AccountType], date)
+		if err != nil {
+			return nil, err
+		}
+		if accrued {
+			run.Accruals++
+		}
+	}
+
+	// the last day of the month
+	if dayEnd.Day() == 1 {
+		accountIDs, err := s.unpostedInterestAccounts(ctx, date)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, accountID := range accountIDs {
+			posted, payment, err := s.postInterest(ctx, accountID, date)
+			if err != nil {
+				return nil, err
+			}
+			if posted {
+				run.Postings++
+			}
+			if payment != nil {
+				run.Payments = append(run.Payments, payment)
+			}
+		}
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_interest_runs").
+		Columns("run_date", "accruals", "postings").
+		Values(date, run.Accruals, run.Postings).
+		Suffix(`ON CONFLICT (run_date) DO UPDATE SET
+			accruals = dbank_interest_runs.accruals + EXCLUDED.accruals,
+			postings = dbank_interest_runs.postings + EXCLUDED.postings,
+			completed_at = now()`).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = s.db.Pool.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record interest run", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to record interest run")
+	}
+
+	return run, nil
+}
+
+// LastInterestRun returns the latest date RunInterest completed, ok is
+// false when it has never run
+func (s *Store) LastInterestRun(ctx context.Context) (date time.Time, ok bool, err error) {
+	sql, args, err := s.db.Builder.
+		Select("run_date").
+		From("dbank_interest_runs").
+		OrderBy("run_date DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return time.Time{}, false, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&date)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, false, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to query interest runs", "error", err)
+		return time.Time{}, false, status.Errorf(codes.Internal, "failed to query interest runs")
+	}
+
+	return date, true, nil
+}
+
+// interestAccount is a customer account whose account type has an interest plan
+type interestAccount struct {
+	PK          int
+	ID          string
+	AccountType string
+	Currency    string
+}
+
+// interestAccounts returns the accounts that are due an accrual for date
+func (s *Store) interestAccounts(ctx context.Context, date time.Time) ([]*interestAccount, error) {
+	sql, args, err := s.db.Builder.
+		Select("a.pk", "a.id", "a.account_type", "a.currency").
+		From("dbank_accounts a").
+		Join("dbank_interest_plans p ON p.account_type = a.account_type").
+		Where("a.system_code IS NULL").
+		Where("a.deleted_at IS NULL").
+		Where("a.status <> ?", AccountStatusClosed).
+		Where("a.created_at < ?", date.AddDate(0, 0, 1)).
+		Where(squirrel.Expr(
+			"NOT EXISTS (SELECT 1 FROM dbank_interest_accruals i WHERE i.account_pk = a.pk AND i.accrual_date = ?)", date,
+		)).
+		OrderBy("a.pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query interest accounts", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query interest accounts")
+	}
+
+	accounts, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[interestAccount])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan interest accounts", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan interest accounts")
+	}
+
+	return accounts, nil
+}
+
+// accrueInterest stores the accrual of an account for date, it returns
+// false when another run stored it first
+func (s *Store) accrueInterest(
+	ctx context.Context,
+	account *interestAccount,
+	plan *InterestPlan,
+	date time.Time,
+) (bool, error) {
+	var accrued bool
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select("balance").
+			From("dbank_accounts").
+			Where("pk = ?", account.PK).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var current decimal.Decimal
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&current); err != nil {
+			s.logger.ErrorContext(ctx, "failed to query account", "error", err)
+			return status.Errorf(codes.Internal, "failed to query account")
+		}
+
+		balance, err := s.balanceAt(ctx, tx, account.PK, current, date.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+
+		accrual, err := plan.Accrue(balance, date)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = s.db.Builder.
+			Insert("dbank_interest_accruals").
+			Columns(
+				"id", "account_pk", "accrual_date", "balance", "currency",
+				"day_count", "day_fraction", "tiers", "amount",
+			).
+			Values(
+				idx.UUID4(), account.PK, date, accrual.Balance, account.Currency,
+				accrual.DayCount, accrual.DayFraction, accrual.Tiers, accrual.Amount,
+			).
+			Suffix("ON CONFLICT (account_pk, accrual_date) DO NOTHING").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert interest accrual", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert interest accrual")
+		}
+
+		accrued = tag.RowsAffected() > 0
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to accrue interest", "error", err, "account_id", account.ID)
+		return false, err
+	}
+
+	return accrued, nil
+}
+
+// unpostedInterestAccounts returns the open accounts with accruals up to
+// periodEnd that have not been posted, closed accounts were posted when
+// they were closed
+func (s *Store) unpostedInterestAccounts(ctx context.Context, periodEnd time.Time) ([]string, error) {
+	sql, args, err := s.db.Builder.
+		Select("DISTINCT a.id").
+		From("dbank_interest_accruals i").
+		Join("dbank_accounts a ON a.pk = i.account_pk").
+		Where("i.posting_pk IS NULL").
+		Where("i.accrual_date <= ?", periodEnd).
+		Where("a.deleted_at IS NULL").
+		Where("a.status <> ?", AccountStatusClosed).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query interest accruals", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query interest accruals")
+	}
+
+	accountIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan interest accruals", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan interest accruals")
+	}
+
+	return accountIDs, nil
+}
+
+// postInterest pays the unposted accruals of an account up to periodEnd in
+// its own database transaction. It returns false when the account was
+// already posted for periodEnd, has been closed or cannot receive money; the
+// accruals of a frozen account wait for the next posting after it is unfrozen.
+// The payment is nil when the posting paid nothing.
+func (s *Store) postInterest(
+	ctx context.Context,
+	accountID string,
+	periodEnd time.Time,
+) (bool, *PostedTransaction, error) {
+	var posted bool
+	var payment *PostedTransaction
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		currency, err := s.accountCurrency(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		expenseID, err := s.systemAccountID(ctx, tx, SystemAccountInterestExpense, currency)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := s.lockAccounts(ctx, tx, accountID, expenseID)
+		if err != nil {
+			return err
+		}
+
+		// a closed account was paid by its closure, a frozen one waits
+		if !accounts[accountID].canReceive() {
+			return nil
+		}
+
+		posted, payment, err = s.postAccruedInterest(ctx, tx, accounts[accountID], accounts[expenseID], periodEnd)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to post interest", "error", err, "account_id", accountID)
+		return false, nil, err
+	}
+
+	return posted, payment, nil
+}
+
+// postAccruedInterest pays the unposted accruals of a locked account up to
+// periodEnd, plus the carry of its previous posting, in whole minor units of
+// its currency from the locked interest expense account. It returns false
+// when the account was already posted for periodEnd or had nothing accrued,
+// and the payment when the posting paid money.
+func (s *Store) postAccruedInterest(
+	ctx context.Context,
+	tx pgx.Tx,
+	account, expense *lockedAccount,
+	periodEnd time.Time,
+) (bool, *PostedTransaction, error) {
+	sql, args, err := s.db.Builder.
+		Select("period_end", "carry").
+		From("dbank_interest_postings").
+		Where("account_pk = ?", account.PK).
+		OrderBy("period_end DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var lastPeriodEnd time.Time
+	carry := decimal.Zero
+	err = tx.QueryRow(ctx, sql, args...).Scan(&lastPeriodEnd, &carry)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "failed to query interest postings", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to query interest postings")
+	}
+	if err == nil && !lastPeriodEnd.Before(periodEnd) {
+		return false, nil, nil
+	}
+
+	sql, args, err = s.db.Builder.
+		Select("pk", "amount").
+		From("dbank_interest_accruals").
+		Where("account_pk = ?", account.PK).
+		Where("posting_pk IS NULL").
+		Where("accrual_date <= ?", periodEnd).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query interest accruals", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to query interest accruals")
+	}
+
+	var accrualPKs []int
+	accrued := carry
+	var pk int
+	var amount decimal.Decimal
+	_, err = pgx.ForEachRow(rows, []any{&pk, &amount}, func() error {
+		accrualPKs = append(accrualPKs, pk)
+		accrued = accrued.Add(amount)
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan interest accruals", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to scan interest accruals")
+	}
+	if len(accrualPKs) == 0 {
+		return false, nil, nil
+	}
+
+	paid := currency.Get(account.Currency).Truncate(accrued)
+	var transactionID any
+	var payment *PostedTransaction
+	if paid.IsPositive() {
+		request := &TransactionRequest{
+			TransactionID:       idx.UUID4(),
+			FromAccountID:       expense.ID,
+			ToAccountID:         account.ID,
+			TransactionType:     TransactionTypeInterest,
+			Amount:              paid,
+			Currency:            account.Currency,
+			Description:         fmt.Sprintf("Interest to %s", periodEnd.Format(time.DateOnly)),
+			Status:              TransactionStatusSuccess,
+			AllowSystemAccounts: true,
+		}
+		entries, err := s.recordTransaction(ctx, tx, request, expense, account)
+		if err != nil {
+			return false, nil, err
+		}
+		transactionID = request.TransactionID
+		payment = &PostedTransaction{Transaction: request, Entries: entries}
+	}
+
+	sql, args, err = s.db.Builder.
+		Insert("dbank_interest_postings").
+		Columns("id", "account_pk", "period_end", "accrued", "amount", "carry", "transaction_id").
+		Values(idx.UUID4(), account.PK, periodEnd, accrued, paid, accrued.Sub(paid), transactionID).
+		Suffix("RETURNING pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var postingPK int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&postingPK); err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert interest posting", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to insert interest posting")
+	}
+
+	sql, args, err = s.db.Builder.
+		Update("dbank_interest_accruals").
+		Set("posting_pk", postingPK).
+		Where(squirrel.Eq{"pk": accrualPKs}).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update interest accruals", "error", err)
+		return false, nil, status.Errorf(codes.Internal, "failed to update interest accruals")
+	}
+
+	return true, payment, nil
+}
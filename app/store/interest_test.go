@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestDays30360_monthsHaveThirtyDays(t *testing.T) {
+	for _, month := range []time.Time{
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		var days int64
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			days += accrualDays30360(day)
+		}
+		if days != 30 {
+			t.Errorf("30/360 days in %s = %d, want 30", month.Format("2006-01"), days)
+		}
+	}
+}
+
+func TestDayDays30360(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want int64
+	}{
+		{time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, time.January, 30, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), 3},
+		{time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2026, time.April, 30, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		if got := accrualDays30360(tt.date); got != tt.want {
+			t.Errorf("accrualDays30360(%s) = %d, want %d", tt.date.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestInterestPlan_Accrue(t *testing.T) {
+	plan := &InterestPlan{
+		DayCount: DayCountACT360,
+		Tiers: []*InterestTier{
+			{MinBalance: decimal.NewFromInt(5000), AnnualRate: decimal.RequireFromString("0.03")},
+			{MinBalance: decimal.Zero, AnnualRate: decimal.RequireFromString("0.01")},
+			{MinBalance: decimal.NewFromInt(1000), AnnualRate: decimal.RequireFromString("0.02")},
+		},
+	}
+	date := time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		balance string
+		amount  string
+		tiers   int
+	}{
+		{name: "first tier", balance: "500", amount: "0.0138888889", tiers: 1},
+		{name: "all tiers", balance: "6000", amount: "0.3333333333", tiers: 3},
+		{name: "tier boundary", balance: "1000", amount: "0.0277777778", tiers: 1},
+		{name: "zero", balance: "0", amount: "0", tiers: 0},
+		{name: "overdrawn", balance: "-50", amount: "0", tiers: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accrual, err := plan.Accrue(decimal.RequireFromString(tt.balance), date)
+			if err != nil {
+				t.Fatalf("Accrue() error = %v", err)
+			}
+			if !accrual.Amount.Equal(decimal.RequireFromString(tt.amount)) {
+				t.Errorf("amount = %s, want %s", accrual.Amount, tt.amount)
+			}
+			if len(accrual.Tiers) != tt.tiers {
+				t.Errorf("tiers = %d, want %d", len(accrual.Tiers), tt.tiers)
+			}
+		})
+	}
+
+	plan.DayCount = "ACT/ACT"
+	if _, err := plan.Accrue(decimal.NewFromInt(100), date); err == nil {
+		t.Error("Accrue() with an unsupported day count error = nil")
+	}
+}
+
+func TestStore_RunInterest(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// 1000 at 3.65% ACT/365 earns 0.10 a day
+	accountType := "savings-" + idx.UUID4()
+	err := s.SetInterestPlan(ctx, &InterestPlan{
+		AccountType: accountType,
+		DayCount:    DayCountACT365,
+		Tiers:       []*InterestTier{{MinBalance: decimal.Zero, AnnualRate: decimal.RequireFromString("0.0365")}},
+	})
+	if err != nil {
+		t.Fatalf("SetInterestPlan() error = %v", err)
+	}
+
+	// the account and its opening deposit date from before last month
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	opened := monthStart.Add(-time.Hour)
+
+	accountID := createTestAccount(t, s, "1000", "USD")
+	_, err = s.db.Pool.Exec(ctx,
+		"UPDATE dbank_accounts SET account_type = $2, created_at = $3 WHERE id = $1", accountID, accountType, opened)
+	if err != nil {
+		t.Fatalf("failed to backdate account: %v", err)
+	}
+	_, err = s.db.Pool.Exec(ctx,
+		"UPDATE dbank_ledgers SET created_at = $2 WHERE account_pk = (SELECT pk FROM dbank_accounts WHERE id = $1)",
+		accountID, opened)
+	if err != nil {
+		t.Fatalf("failed to backdate ledger: %v", err)
+	}
+
+	var days int64
+	for day := monthStart; !day.After(monthEnd); day = day.AddDate(0, 0, 1) {
+		if _, err = s.RunInterest(ctx, day); err != nil {
+			t.Fatalf("RunInterest(%s) error = %v", day.Format(time.DateOnly), err)
+		}
+		days++
+	}
+
+	want := decimal.NewFromInt(1000).Add(decimal.RequireFromString("0.1").Mul(decimal.NewFromInt(days)))
+	if got := accountBalance(t, s, accountID); !got.Equal(want) {
+		t.Errorf("balance after month end = %s, want %s", got, want)
+	}
+
+	// running a day again accrues and posts nothing
+	for _, day := range []time.Time{monthStart, monthEnd} {
+		if _, err = s.RunInterest(ctx, day); err != nil {
+			t.Fatalf("RunInterest(%s) rerun error = %v", day.Format(time.DateOnly), err)
+		}
+	}
+	if got := accountBalance(t, s, accountID); !got.Equal(want) {
+		t.Errorf("balance after rerun = %s, want %s", got, want)
+	}
+
+	var accruals, unposted int
+	err = s.db.Pool.QueryRow(ctx, `SELECT count(*), count(*) FILTER (WHERE posting_pk IS NULL)
+		FROM dbank_interest_accruals WHERE account_pk = (SELECT pk FROM dbank_accounts WHERE id = $1)`,
+		accountID).Scan(&accruals, &unposted)
+	if err != nil {
+		t.Fatalf("failed to count accruals: %v", err)
+	}
+	if int64(accruals) != days || unposted != 0 {
+		t.Errorf("accruals, unposted = %d, %d, want %d, 0", accruals, unposted, days)
+	}
+
+	if _, err = s.RunInterest(ctx, now); err == nil {
+		t.Error("RunInterest() for today error = nil, want an error")
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+const (
+	EntryTypeDebit  = "debit"
+	EntryTypeCredit = "credit"
+)
+
+// LedgerEntry is a single posting in dbank_ledgers.
+// Amount is signed: debits are negative and credits are positive.
+// Balance is the running balance of the account right after the posting.
+type LedgerEntry struct {
+	ID            string          `json:"id"`
+	AccountID     string          `json:"account_id"`
+	TransactionID string          `json:"transaction_id"`
+	EntryType     string          `json:"entry_type"`
+	Amount        decimal.Decimal `json:"amount"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Description   string          `json:"description,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+
+	// Overdrawn is set when the posting took a customer account from zero
+	// or above to below zero
+	Overdrawn bool `json:"overdrawn,omitempty"`
+}
+
+// postEntry changes the balance of a locked account by amount and writes the
+// matching ledger posting, so that every balance change has a posting
+func (s *Store) postEntry(
+	ctx context.Context,
+	tx pgx.Tx,
+	account *lockedAccount,
+	transactionPK int,
+	transactionID string,
+	amount decimal.Decimal,
+	currency string,
+) (*LedgerEntry, error) {
+	wasOverdrawn := account.Balance.IsNegative()
+	if err := s.adjustBalance(ctx, tx, account, amount); err != nil {
+		return nil, err
+	}
+
+	entry := &LedgerEntry{
+		ID:            idx.UUID4(),
+		AccountID:     account.ID,
+		TransactionID: transactionID,
+		EntryType:     EntryTypeCredit,
+		Amount:        amount,
+		Balance:       account.Balance,
+		Currency:      currency,
+		Overdrawn:     account.SystemCode == "" && !wasOverdrawn && account.Balance.IsNegative(),
+	}
+	if amount.IsNegative() {
+		entry.EntryType = EntryTypeDebit
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_ledgers").
+		Columns("id", "account_pk", "transaction_pk", "entry_type", "amount", "balance", "currency").
+		Values(entry.ID, account.PK, transactionPK, entry.EntryType, entry.Amount, entry.Balance, entry.Currency).
+		Suffix("RETURNING created_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build ledger SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&entry.CreatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert ledger entry", "error", err, "account_id", account.ID)
+		return nil, status.Errorf(codes.Internal, "failed to insert ledger entry")
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// Scopes of transfer limits, from the most to the least specific
+const (
+	LimitScopeAccount     = "account"
+	LimitScopeAccountType = "account_type"
+	LimitScopeKYCTier     = "kyc_tier"
+)
+
+// LimitScopes are the supported limit scopes, most specific first
+var LimitScopes = []string{LimitScopeAccount, LimitScopeAccountType, LimitScopeKYCTier}
+
+// AuditActionKYCTierChanged is the audit log action of SetKYCTier
+const AuditActionKYCTierChanged = "user.kyc_tier_changed"
+
+// TransferLimit limits the outgoing transfers in Currency of the accounts in
+// a scope: the account with the id ScopeValue, the accounts of the account type
+// ScopeValue or the accounts of users of the KYC tier ScopeValue.
+// A limit that is not set is left to the less specific scopes.
+type TransferLimit struct {
+	ID                string              `json:"id"`
+	Scope             string              `json:"scope"`
+	ScopeValue        string              `json:"scope_value"`
+	Currency          string              `json:"currency"`
+	PerTransactionMax decimal.NullDecimal `json:"per_transaction_max"`
+	DailyAmount       decimal.NullDecimal `json:"daily_amount"`
+	MonthlyAmount     decimal.NullDecimal `json:"monthly_amount"`
+	DailyCount        *int                `json:"daily_count"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+}
+
+// AccountLimits are the limits that apply to an account, each taken from the
+// most specific scope that sets it
+type AccountLimits struct {
+	AccountID         string
+	Currency          string
+	PerTransactionMax decimal.NullDecimal
+	DailyAmount       decimal.NullDecimal
+	MonthlyAmount     decimal.NullDecimal
+	DailyCount        *int
+}
+
+// OutgoingTransfer is a transfer that counts towards the limits of its sender
+type OutgoingTransfer struct {
+	TransactionID string
+	Amount        decimal.Decimal
+	CreatedAt     time.Time
+}
+
+// KYCTierChange is an admin change of the KYC tier of a user.
+// PreviousTier and AuditLogID are set by SetKYCTier.
+type KYCTierChange struct {
+	UserID       string `json:"user_id"`
+	Tier         string `json:"tier"`
+	PreviousTier string `json:"previous_tier"`
+	Reason       string `json:"reason"`
+	Actor        string `json:"actor,omitempty"`
+	AuditLogID   string `json:"-"`
+}
+
+// resolveLimits merges limits into the limits of an account, limits is
+// ordered from the most to the least specific scope
+func resolveLimits(accountID, currency string, limits []*TransferLimit) *AccountLimits {
+	resolved := &AccountLimits{AccountID: accountID, Currency: currency}
+	for _, limit := range limits {
+		if !resolved.PerTransactionMax.Valid {
+			resolved.PerTransactionMax = limit.PerTransactionMax
+		}
+		if !resolved.DailyAmount.Valid {
+			resolved.DailyAmount = limit.DailyAmount
+		}
+		if !resolved.MonthlyAmount.Valid {
+			resolved.MonthlyAmount = limit.MonthlyAmount
+		}
+		if resolved.DailyCount == nil {
+			resolved.DailyCount = limit.DailyCount
+		}
+	}
+	return resolved
+}
+
+// SetTransferLimit creates or replaces the limit of a scope and currency
+func (s *Store) SetTransferLimit(
+	ctx context.Context,
+	limit *TransferLimit,
+) error {
+	if limit.Scope == LimitScopeAccount {
+		sql, args, err := s.db.Builder.
+			Select("1").
+			From("dbank_accounts").
+			Where("id = ?", limit.ScopeValue).
+			Where("system_code IS NULL").
+			Where("deleted_at IS NULL").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var exists int
+		if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&exists); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "account %s not found", limit.ScopeValue)
+			}
+			s.logger.ErrorContext(ctx, "failed to query account", "error", err)
+			return status.Errorf(codes.Internal, "failed to query account")
+		}
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_transfer_limits").
+		Columns(
+			"id", "scope", "scope_value", "currency",
+			"per_transaction_max", "daily_amount", "monthly_amount", "daily_count",
+		).
+		Values(
+			idx.UUID4(), limit.Scope, limit.ScopeValue, limit.Currency,
+			limit.PerTransactionMax, limit.DailyAmount, limit.MonthlyAmount, limit.DailyCount,
+		).
+		Suffix(`ON CONFLICT (scope, scope_value, currency) DO UPDATE SET
+			per_transaction_max = EXCLUDED.per_transaction_max,
+			daily_amount = EXCLUDED.daily_amount,
+			monthly_amount = EXCLUDED.monthly_amount,
+			daily_count = EXCLUDED.daily_count,
+			updated_at = now()`).
+		Suffix("RETURNING id, updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&limit.ID, &limit.UpdatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save transfer limit", "error", err)
+		return status.Errorf(codes.Internal, "failed to save transfer limit")
+	}
+
+	return nil
+}
+
+// GetAccountLimits returns the limits on the outgoing transfers of an account
+// in a currency, or nil when none apply. Internal accounts have no limits.
+func (s *Store) GetAccountLimits(
+	ctx context.Context,
+	accountID string,
+	currency string,
+) (*AccountLimits, error) {
+	sql, args, err := s.db.Builder.
+		Select(
+			"l.id", "l.scope", "l.scope_value", "l.currency",
+			"l.per_transaction_max", "l.daily_amount", "l.monthly_amount", "l.daily_count", "l.updated_at",
+		).
+		From("dbank_accounts a").
+		Join("dbank_users u ON u.pk = a.user_pk").
+		Join(`dbank_transfer_limits l ON
+			(l.scope = 'account' AND l.scope_value = a.id::text) OR
+			(l.scope = 'account_type' AND l.scope_value = a.account_type) OR
+			(l.scope = 'kyc_tier' AND l.scope_value = u.kyc_tier)`).
+		Where("a.id = ?", accountID).
+		Where("a.system_code IS NULL").
+		Where("l.currency = ?", currency).
+		OrderBy("CASE l.scope WHEN 'account' THEN 0 WHEN 'account_type' THEN 1 ELSE 2 END").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query transfer limits", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query transfer limits")
+	}
+
+	limits, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[TransferLimit])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan transfer limits", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan transfer limits")
+	}
+
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	return resolveLimits(accountID, currency, limits), nil
+}
+
+// OutgoingTransfers returns the transfers an account sent since a time that
+// count towards its limits, reversals and rejected transfers do not count
+func (s *Store) OutgoingTransfers(
+	ctx context.Context,
+	accountID string,
+	since time.Time,
+) ([]*OutgoingTransfer, error) {
+	sql, args, err := s.db.Builder.
+		Select("id", "amount", "created_at").
+		From("dbank_transactions").
+		Where("from_account_id = ?", accountID).
+		Where("created_at >= ?", since).
+		Where(squirrel.Eq{"status": outgoingStatuses}).
+		Where("transaction_type <> ?", TransactionTypeReversal).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query outgoing transfers", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query outgoing transfers")
+	}
+
+	transfers, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[OutgoingTransfer])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan outgoing transfers", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan outgoing transfers")
+	}
+
+	return transfers, nil
+}
+
+// SetKYCTier changes the KYC tier of a user and records the change in the
+// audit log of the user
+func (s *Store) SetKYCTier(
+	ctx context.Context,
+	change *KYCTierChange,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select("pk", "kyc_tier").
+			From("dbank_users").
+			Where("id = ?", change.UserID).
+			Where("deleted_at IS NULL").
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var userPK int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&userPK, &change.PreviousTier); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "user not found")
+			}
+			s.logger.ErrorContext(ctx, "failed to lock user", "error", err)
+			return status.Errorf(codes.Internal, "failed to lock user")
+		}
+
+		sql, args, err = s.db.Builder.
+			Update("dbank_users").
+			Set("kyc_tier", change.Tier).
+			Set("updated_at", squirrel.Expr("now()")).
+			Where("pk = ?", userPK).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to update kyc tier", "error", err)
+			return status.Errorf(codes.Internal, "failed to update kyc tier")
+		}
+
+		change.AuditLogID, err = s.writeAuditLog(ctx, tx, userPK, AuditActionKYCTierChanged, change)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to set kyc tier", "error", err, "user_id", change.UserID)
+		return err
+	}
+
+	return nil
+}
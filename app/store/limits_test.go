@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestResolveLimits(t *testing.T) {
+	d := func(value string) decimal.NullDecimal {
+		return decimal.NewNullDecimal(decimal.RequireFromString(value))
+	}
+	count := func(value int) *int { return &value }
+
+	resolved := resolveLimits("account", "USD", []*TransferLimit{
+		{Scope: LimitScopeAccount, PerTransactionMax: d("100")},
+		{Scope: LimitScopeAccountType, PerTransactionMax: d("500"), DailyAmount: d("1000")},
+		{Scope: LimitScopeKYCTier, DailyAmount: d("2000"), MonthlyAmount: d("10000"), DailyCount: count(5)},
+	})
+
+	for _, tt := range []struct {
+		name string
+		got  decimal.NullDecimal
+		want string
+	}{
+		{"per_transaction_max", resolved.PerTransactionMax, "100"},
+		{"daily_amount", resolved.DailyAmount, "1000"},
+		{"monthly_amount", resolved.MonthlyAmount, "10000"},
+	} {
+		if !tt.got.Valid || !tt.got.Decimal.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("%s = %v, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+	if resolved.DailyCount == nil || *resolved.DailyCount != 5 {
+		t.Errorf("daily_count = %v, want 5", resolved.DailyCount)
+	}
+
+	if resolved := resolveLimits("account", "USD", []*TransferLimit{{DailyCount: count(0)}}); resolved.DailyAmount.Valid {
+		t.Errorf("daily_amount = %v, want unset", resolved.DailyAmount)
+	}
+}
+
+func TestStore_AccountLimits(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "1000", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	var userID string
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT u.id FROM dbank_users u JOIN dbank_accounts a ON a.user_pk = u.pk WHERE a.id = $1", from,
+	).Scan(&userID)
+	if err != nil {
+		t.Fatalf("failed to read user: %v", err)
+	}
+
+	if limits, err := s.GetAccountLimits(ctx, from, "USD"); err != nil || limits != nil {
+		t.Fatalf("GetAccountLimits() without limits = %v, %v, want nil", limits, err)
+	}
+
+	tier := "tier-" + idx.UUID4()
+	change := &KYCTierChange{UserID: userID, Tier: tier, Reason: "verified"}
+	if err = s.SetKYCTier(ctx, change); err != nil {
+		t.Fatalf("SetKYCTier() error = %v", err)
+	}
+	if change.PreviousTier != "basic" || change.AuditLogID == "" {
+		t.Errorf("SetKYCTier() previous tier = %q, audit log = %q", change.PreviousTier, change.AuditLogID)
+	}
+
+	dailyCount := 3
+	for _, limit := range []*TransferLimit{
+		{
+			Scope: LimitScopeKYCTier, ScopeValue: tier, Currency: "USD",
+			DailyAmount: decimal.NewNullDecimal(decimal.NewFromInt(500)),
+			DailyCount:  &dailyCount,
+		},
+		{
+			Scope: LimitScopeAccount, ScopeValue: from, Currency: "USD",
+			PerTransactionMax: decimal.NewNullDecimal(decimal.NewFromInt(100)),
+			DailyAmount:       decimal.NewNullDecimal(decimal.NewFromInt(200)),
+		},
+	} {
+		if err = s.SetTransferLimit(ctx, limit); err != nil {
+			t.Fatalf("SetTransferLimit() error = %v", err)
+		}
+	}
+
+	limits, err := s.GetAccountLimits(ctx, from, "USD")
+	if err != nil {
+		t.Fatalf("GetAccountLimits() error = %v", err)
+	}
+	if !limits.PerTransactionMax.Decimal.Equal(decimal.NewFromInt(100)) ||
+		!limits.DailyAmount.Decimal.Equal(decimal.NewFromInt(200)) ||
+		limits.MonthlyAmount.Valid ||
+		limits.DailyCount == nil || *limits.DailyCount != 3 {
+		t.Errorf("GetAccountLimits() = %+v, want per transaction 100, daily 200 and 3 transfers", limits)
+	}
+
+	request := &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   from,
+		ToAccountID:     to,
+		TransactionType: "transfer",
+		Amount:          decimal.NewFromInt(40),
+		Currency:        "USD",
+		Status:          TransactionStatusSuccess,
+	}
+	if _, err = s.CreateTransaction(ctx, request); err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+
+	transfers, err := s.OutgoingTransfers(ctx, from, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("OutgoingTransfers() error = %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].TransactionID != request.TransactionID ||
+		!transfers[0].Amount.Equal(request.Amount) {
+		t.Errorf("OutgoingTransfers() = %+v, want the transfer of 40", transfers)
+	}
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// AuditActionOverdraftLimitChanged is the audit log action of SetOverdraftLimit
+const AuditActionOverdraftLimitChanged = "account.overdraft_limit_changed"
+
+// OverdraftLimitChange is an admin change of the overdraft limit of an account.
+// PreviousLimit and AuditLogID are set by SetOverdraftLimit.
+type OverdraftLimitChange struct {
+	AccountID     string          `json:"account_id"`
+	Limit         decimal.Decimal `json:"limit"`
+	PreviousLimit decimal.Decimal `json:"previous_limit"`
+	Reason        string          `json:"reason"`
+	Actor         string          `json:"actor,omitempty"`
+	AuditLogID    string          `json:"-"`
+}
+
+// SetOverdraftLimit changes how far below zero an account may go and records
+// the change in the audit log of the account owner. Lowering the limit below
+// the current overdraft is allowed, it only blocks further debits.
+func (s *Store) SetOverdraftLimit(
+	ctx context.Context,
+	change *OverdraftLimitChange,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		accounts, err := s.lockAccounts(ctx, tx, change.AccountID)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[change.AccountID]
+		if account.SystemCode != "" {
+			return status.Errorf(codes.PermissionDenied, "internal accounts have no overdraft limit")
+		}
+		change.PreviousLimit = account.OverdraftLimit
+
+		sql, args, err := s.db.Builder.
+			Update("dbank_accounts").
+			Set("overdraft_limit", change.Limit).
+			Set("updated_at", squirrel.Expr("now()")).
+			Where("pk = ?", account.PK).
+			Suffix("RETURNING user_pk").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var userPK int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&userPK); err != nil {
+			s.logger.ErrorContext(ctx, "failed to update overdraft limit", "error", err)
+			return status.Errorf(codes.Internal, "failed to update overdraft limit")
+		}
+
+		change.AuditLogID, err = s.writeAuditLog(ctx, tx, userPK, AuditActionOverdraftLimitChanged, change)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to set overdraft limit", "error", err, "account_id", change.AccountID)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "overdraft limit changed",
+		"account_id", change.AccountID,
+		"previous_limit", change.PreviousLimit,
+		"limit", change.Limit,
+	)
+	return nil
+}
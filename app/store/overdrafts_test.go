@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_Overdraft(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "20", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	transfer := func(from, to, amount string) ([]*LedgerEntry, error) {
+		return s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: TransactionTypeTransfer,
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        "USD",
+			Status:          TransactionStatusSuccess,
+		})
+	}
+
+	if _, err := transfer(from, to, "30"); !IsInsufficientFunds(err) {
+		t.Fatalf("CreateTransaction() without an overdraft error = %v, want insufficient funds", err)
+	}
+
+	change := &OverdraftLimitChange{
+		AccountID: from,
+		Limit:     decimal.NewFromInt(50),
+		Reason:    "business customer",
+		Actor:     "ops",
+	}
+	if err := s.SetOverdraftLimit(ctx, change); err != nil {
+		t.Fatalf("SetOverdraftLimit() error = %v", err)
+	}
+	if !change.PreviousLimit.IsZero() || change.AuditLogID == "" {
+		t.Errorf("SetOverdraftLimit() = %+v, want a previous limit of 0 and an audit log", change)
+	}
+
+	var action string
+	var limit decimal.Decimal
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT action, (data->>'limit')::numeric FROM dbank_audit_logs WHERE id = $1", change.AuditLogID,
+	).Scan(&action, &limit)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if action != AuditActionOverdraftLimitChanged || !limit.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("audit log = %s %s, want %s 50", action, limit, AuditActionOverdraftLimitChanged)
+	}
+
+	entries, err := transfer(from, to, "60")
+	if err != nil {
+		t.Fatalf("CreateTransaction() into the overdraft error = %v", err)
+	}
+	if !entries[0].Overdrawn || entries[1].Overdrawn {
+		t.Errorf("overdrawn postings = %v, %v, want only the debit", entries[0].Overdrawn, entries[1].Overdrawn)
+	}
+
+	// 10 is left of the overdraft
+	if _, err = transfer(from, to, "11"); !IsInsufficientFunds(err) {
+		t.Errorf("CreateTransaction() over the overdraft limit error = %v, want insufficient funds", err)
+	}
+
+	entries, err = transfer(from, to, "10")
+	if err != nil {
+		t.Fatalf("CreateTransaction() to the overdraft limit error = %v", err)
+	}
+	if entries[0].Overdrawn {
+		t.Errorf("posting of an account that already was overdrawn is marked overdrawn")
+	}
+
+	overdraft := func() (bool, decimal.Decimal) {
+		t.Helper()
+		var overdrawn bool
+		var maxOverdraft decimal.Decimal
+		err := s.db.Pool.QueryRow(ctx,
+			"SELECT overdrawn_since IS NOT NULL, max_overdraft FROM dbank_accounts WHERE id = $1", from,
+		).Scan(&overdrawn, &maxOverdraft)
+		if err != nil {
+			t.Fatalf("failed to read overdraft: %v", err)
+		}
+		return overdrawn, maxOverdraft
+	}
+
+	if overdrawn, maxOverdraft := overdraft(); !overdrawn || !maxOverdraft.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("overdrawn, max overdraft = %v, %s, want true, 50", overdrawn, maxOverdraft)
+	}
+
+	if _, err = transfer(to, from, "70"); err != nil {
+		t.Fatalf("CreateTransaction() back error = %v", err)
+	}
+	if overdrawn, maxOverdraft := overdraft(); overdrawn || !maxOverdraft.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("overdrawn, max overdraft = %v, %s, want false, 50", overdrawn, maxOverdraft)
+	}
+
+	err = s.SetOverdraftLimit(ctx, &OverdraftLimitChange{AccountID: idx.UUID4(), Reason: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("SetOverdraftLimit() of an unknown account error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,369 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// TransactionTypeReversal is the type of the transactions created by ReverseTransaction
+const TransactionTypeReversal = "reversal"
+
+// ReversalRequest asks to compensate a transaction.
+// A zero Amount reverses the part of the original transaction that is not
+// reversed yet. Currency, when set, must be the currency of the original.
+type ReversalRequest struct {
+	TransactionID         string          `json:"transaction_id"`
+	OriginalTransactionID string          `json:"original_transaction_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	Currency              string          `json:"currency"`
+	Reason                string          `json:"reason"`
+}
+
+// ReverseTransaction moves the money of a transaction back with a new linked
+// transaction that posts the opposite entries. A transaction can be reversed
+// in several parts, the original is partially_reversed until the parts add up
+// to its amount and reversed once they do.
+// The original transaction row is locked first, so two concurrent reversals of
+// the same transaction serialize and the second one sees what the first reversed.
+func (s *Store) ReverseTransaction(
+	ctx context.Context,
+	request *ReversalRequest,
+) (*Transaction, []*LedgerEntry, error) {
+	reversal := &Transaction{
+		TransactionID:   request.TransactionID,
+		TransactionType: TransactionTypeReversal,
+		Description:     request.Reason,
+		Status:          TransactionStatusSuccess,
+		ReversalOf:      request.OriginalTransactionID,
+	}
+
+	var entries []*LedgerEntry
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select(
+				"pk", "from_account_id", "to_account_id", "amount", "currency", "status",
+				"to_amount", "to_currency", "COALESCE(fx_rate_id::text, '')",
+				"COALESCE(fx_rate, 0)", "COALESCE(fx_spread, 0)", "COALESCE(fx_snapshot_id::text, '')",
+				"fee_amount", "reversed_amount",
+			).
+			From("dbank_transactions").
+			Where("id = ?", request.OriginalTransactionID).
+			Where("deleted_at IS NULL").
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var (
+			originalPK     int
+			original       Transaction
+			reversedAmount decimal.Decimal
+		)
+		err = tx.QueryRow(ctx, sql, args...).Scan(
+			&originalPK,
+			&original.FromAccountID,
+			&original.ToAccountID,
+			&original.Amount,
+			&original.Currency,
+			&original.Status,
+			&original.ToAmount,
+			&original.ToCurrency,
+			&original.FXRateID,
+			&original.FXRate,
+			&original.FXSpread,
+			&original.FXSnapshotID,
+			&original.FeeAmount,
+			&reversedAmount,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "transaction not found")
+			}
+			s.logger.ErrorContext(ctx, "failed to lock transaction", "error", err)
+			return status.Errorf(codes.Internal, "failed to lock transaction")
+		}
+
+		switch original.Status {
+		case TransactionStatusSuccess, TransactionStatusPartiallyReversed:
+		case TransactionStatusReversed:
+			return status.Errorf(codes.FailedPrecondition, "transaction already reversed")
+		default:
+			return status.Errorf(codes.FailedPrecondition, "cannot reverse a %s transaction", original.Status)
+		}
+
+		if request.Currency != "" && request.Currency != original.Currency {
+			return status.Errorf(codes.InvalidArgument, "reversal currency %s is not the transaction currency %s",
+				request.Currency, original.Currency)
+		}
+
+		remaining := original.Amount.Sub(reversedAmount)
+		reversal.Amount = remaining
+		if !request.Amount.IsZero() {
+			if request.Amount.GreaterThan(remaining) {
+				return status.Errorf(codes.InvalidArgument, "reversal amount exceeds the unreversed amount %s",
+					remaining)
+			}
+			reversal.Amount = request.Amount
+		}
+		reversedAmount = reversedAmount.Add(reversal.Amount)
+
+		// the money flows back from the original receiver to the original sender
+		reversal.FromAccountID = original.ToAccountID
+		reversal.ToAccountID = original.FromAccountID
+		reversal.Currency = original.Currency
+		reversal.ToAmount = reversal.Amount
+		reversal.ToCurrency = original.Currency
+
+		// a converted transaction is undone at its own rate by posting the
+		// opposite of each of its postings, which only works in full
+		converted := original.ToCurrency != original.Currency
+		if converted {
+			if !reversal.Amount.Equal(original.Amount) {
+				return status.Errorf(codes.InvalidArgument, "converted transactions can only be reversed in full")
+			}
+			reversal.Amount = original.ToAmount
+			reversal.Currency = original.ToCurrency
+			reversal.ToAmount = original.Amount
+			reversal.ToCurrency = original.Currency
+			reversal.FXRateID = original.FXRateID
+			reversal.FXRate = original.FXRate
+			reversal.FXSpread = original.FXSpread
+			reversal.FXSnapshotID = original.FXSnapshotID
+		}
+
+		accounts, err := s.lockAccounts(ctx, tx, reversal.FromAccountID, reversal.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		fromAccount := accounts[reversal.FromAccountID]
+		toAccount := accounts[reversal.ToAccountID]
+		if err = checkAccountStatuses(fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		if _, err = s.releaseExpiredHolds(ctx, tx, fromAccount); err != nil {
+			return err
+		}
+		if fromAccount.SystemCode == "" && fromAccount.available().LessThan(reversal.Amount) {
+			return insufficientFundsError()
+		}
+
+		sql, args, err = s.db.Builder.
+			Insert("dbank_transactions").
+			Columns(
+				"id", "from_account_id", "to_account_id", "transaction_type",
+				"amount", "currency", "description", "status", "reversal_of",
+				"to_amount", "to_currency", "fx_rate_id", "fx_rate", "fx_spread", "fx_snapshot_id",
+			).
+			Values(
+				reversal.TransactionID,
+				reversal.FromAccountID,
+				reversal.ToAccountID,
+				reversal.TransactionType,
+				reversal.Amount,
+				reversal.Currency,
+				reversal.Description,
+				reversal.Status,
+				reversal.ReversalOf,
+				reversal.ToAmount,
+				reversal.ToCurrency,
+				nullString(reversal.FXRateID),
+				nullDecimal(converted, reversal.FXRate),
+				nullDecimal(converted, reversal.FXSpread),
+				nullString(reversal.FXSnapshotID),
+			).
+			Suffix("RETURNING pk, created_at, updated_at").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build reversal SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var reversalPK int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&reversalPK, &reversal.CreatedAt, &reversal.UpdatedAt); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert reversal", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert reversal")
+		}
+
+		if converted {
+			entries, err = s.reversePostings(ctx, tx, accounts, originalPK, reversalPK, reversal.TransactionID)
+			if err != nil {
+				return err
+			}
+		} else {
+			debit, err := s.postEntry(ctx, tx, fromAccount, reversalPK, reversal.TransactionID,
+				reversal.Amount.Neg(), reversal.Currency)
+			if err != nil {
+				return err
+			}
+
+			credit, err := s.postEntry(ctx, tx, toAccount, reversalPK, reversal.TransactionID,
+				reversal.Amount, reversal.Currency)
+			if err != nil {
+				return err
+			}
+
+			entries = []*LedgerEntry{debit, credit}
+
+			// the reversal that completes the transaction refunds the fee,
+			// converted transactions refund it with the rest of their postings
+			if original.FeeAmount.IsPositive() && reversedAmount.Equal(original.Amount) {
+				refund, err := s.postFee(ctx, tx, reversalPK, reversal.TransactionID, original.FeeAmount.Neg(), toAccount)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, refund...)
+			}
+		}
+
+		originalStatus := TransactionStatusPartiallyReversed
+		if reversedAmount.Equal(original.Amount) {
+			originalStatus = TransactionStatusReversed
+		}
+
+		sql, args, err = s.db.Builder.
+			Update("dbank_transactions").
+			Set("status", originalStatus).
+			Set("reversed_amount", reversedAmount).
+			Set("updated_at", squirrel.Expr("now()")).
+			Where("id = ?", request.OriginalTransactionID).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to mark transaction reversed", "error", err)
+			return status.Errorf(codes.Internal, "failed to mark transaction reversed")
+		}
+
+		s.logger.InfoContext(ctx, "transaction reversed",
+			"transaction_id", request.OriginalTransactionID,
+			"reversal_id", reversal.TransactionID,
+		)
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to reverse transaction", "error", err)
+		return nil, nil, err
+	}
+
+	return reversal, entries, nil
+}
+
+// reversePostings posts the opposite of every posting of a transaction.
+// locked holds the two customer accounts. The internal accounts are locked
+// after them in the order of the forward path: the accounts of the conversion
+// like in postConversion, then the fee income account like in postFee.
+func (s *Store) reversePostings(
+	ctx context.Context,
+	tx pgx.Tx,
+	locked map[string]*lockedAccount,
+	originalPK int,
+	reversalPK int,
+	reversalID string,
+) ([]*LedgerEntry, error) {
+	sql, args, err := s.db.Builder.
+		Select("a.id", "COALESCE(a.system_code, '')", "l.amount").
+		From("dbank_ledgers l").
+		Join("dbank_accounts a ON a.pk = l.account_pk").
+		Where("l.transaction_pk = ?", originalPK).
+		Where("l.deleted_at IS NULL").
+		OrderBy("l.pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query ledger postings", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query ledger postings")
+	}
+
+	type posting struct {
+		accountID string
+		amount    decimal.Decimal
+	}
+	var (
+		postings []posting
+		fxIDs    []string
+		feeIDs   []string
+		code     string
+	)
+	for rows.Next() {
+		var p posting
+		if err = rows.Scan(&p.accountID, &code, &p.amount); err != nil {
+			rows.Close()
+			s.logger.ErrorContext(ctx, "failed to scan ledger posting", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to scan ledger posting")
+		}
+		postings = append(postings, p)
+		if _, ok := locked[p.accountID]; ok {
+			continue
+		}
+		if code == SystemAccountFeeIncome {
+			feeIDs = append(feeIDs, p.accountID)
+		} else {
+			fxIDs = append(fxIDs, p.accountID)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query ledger postings", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query ledger postings")
+	}
+
+	internal, err := s.lockAccounts(ctx, tx, fxIDs...)
+	if err != nil {
+		return nil, err
+	}
+	feeAccounts, err := s.lockAccounts(ctx, tx, feeIDs...)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(internal, feeAccounts)
+	maps.Copy(internal, locked)
+
+	entries := make([]*LedgerEntry, 0, len(postings))
+	for _, p := range postings {
+		account := internal[p.accountID]
+		entry, err := s.postEntry(ctx, tx, account, reversalPK, reversalID, p.amount.Neg(), account.Currency)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// nullString maps an empty string to SQL NULL
+func nullString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullDecimal maps a decimal to SQL NULL unless valid
+func nullDecimal(valid bool, value decimal.Decimal) any {
+	if !valid {
+		return nil
+	}
+	return value
+}
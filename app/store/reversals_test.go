@@ -0,0 +1,230 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_ReverseTransaction(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "100", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	transfer := func(amount string) string {
+		request := &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: "transfer",
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        "USD",
+			Status:          TransactionStatusSuccess,
+		}
+		if _, err := s.CreateTransaction(ctx, request); err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+		return request.TransactionID
+	}
+
+	reverse := func(id, amount string) (*Transaction, error) {
+		request := &ReversalRequest{TransactionID: idx.UUID4(), OriginalTransactionID: id, Reason: "mistake"}
+		if amount != "" {
+			request.Amount = decimal.RequireFromString(amount)
+		}
+		reversal, _, err := s.ReverseTransaction(ctx, request)
+		return reversal, err
+	}
+
+	full := transfer("30")
+	reversal, err := reverse(full, "")
+	if err != nil {
+		t.Fatalf("ReverseTransaction() error = %v", err)
+	}
+	if reversal.FromAccountID != to || reversal.ToAccountID != from || !reversal.Amount.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("ReverseTransaction() = %+v, want 30 moved back from %s to %s", reversal, to, from)
+	}
+
+	original, err := s.GetTransaction(ctx, full)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if original.Status != TransactionStatusReversed {
+		t.Errorf("original status = %s, want %s", original.Status, TransactionStatusReversed)
+	}
+
+	stored, err := s.GetTransaction(ctx, reversal.TransactionID)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if stored.ReversalOf != full || stored.TransactionType != TransactionTypeReversal {
+		t.Errorf("reversal = %+v, want a reversal of %s", stored, full)
+	}
+
+	if _, err = reverse(full, ""); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second ReverseTransaction() error = %v, want FailedPrecondition", err)
+	}
+
+	partial := transfer("40")
+	if _, err = reverse(partial, "40.01"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ReverseTransaction() above the amount error = %v, want InvalidArgument", err)
+	}
+	_, _, err = s.ReverseTransaction(ctx, &ReversalRequest{
+		TransactionID:         idx.UUID4(),
+		OriginalTransactionID: partial,
+		Amount:                decimal.NewFromInt(5),
+		Currency:              "EUR",
+		Reason:                "mistake",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ReverseTransaction() in another currency error = %v, want InvalidArgument", err)
+	}
+	if _, err = reverse(partial, "15"); err != nil {
+		t.Fatalf("partial ReverseTransaction() error = %v", err)
+	}
+	if original, err = s.GetTransaction(ctx, partial); err != nil || original.Status != TransactionStatusPartiallyReversed {
+		t.Errorf("GetTransaction() after a partial reversal = %+v, %v, want %s", original, err, TransactionStatusPartiallyReversed)
+	}
+
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(75)) {
+		t.Errorf("from balance = %s, want 75", got)
+	}
+	if got := accountBalance(t, s, to); !got.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("to balance = %s, want 25", got)
+	}
+
+	// the parts of a transaction add up to its amount
+	if _, err = reverse(partial, "25.01"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ReverseTransaction() above the unreversed amount error = %v, want InvalidArgument", err)
+	}
+	if _, err = reverse(partial, "10"); err != nil {
+		t.Fatalf("second partial ReverseTransaction() error = %v", err)
+	}
+	reversal, err = reverse(partial, "")
+	if err != nil {
+		t.Fatalf("ReverseTransaction() of the rest error = %v", err)
+	}
+	if !reversal.Amount.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("ReverseTransaction() of the rest amount = %s, want 15", reversal.Amount)
+	}
+	if original, err = s.GetTransaction(ctx, partial); err != nil || original.Status != TransactionStatusReversed {
+		t.Errorf("GetTransaction() after the last reversal = %+v, %v, want %s", original, err, TransactionStatusReversed)
+	}
+	if _, err = reverse(partial, "1"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReverseTransaction() of a reversed transaction error = %v, want FailedPrecondition", err)
+	}
+
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("from balance = %s, want 100", got)
+	}
+	if got := accountBalance(t, s, to); !got.IsZero() {
+		t.Errorf("to balance = %s, want 0", got)
+	}
+
+	// the receiving account spent the money before the reversal
+	spent := transfer("20")
+	_, err = s.CreateTransaction(ctx, &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   to,
+		ToAccountID:     from,
+		TransactionType: "transfer",
+		Amount:          decimal.NewFromInt(20),
+		Currency:        "USD",
+		Status:          TransactionStatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	if _, err = reverse(spent, ""); !IsInsufficientFunds(err) {
+		t.Errorf("ReverseTransaction() of spent money error = %v, want insufficient funds", err)
+	}
+}
+
+func TestStore_ReverseConvertedTransfer_Concurrent(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	err := s.CreateFXRate(ctx, &FXRate{
+		BaseCurrency:  "CZK",
+		QuoteCurrency: "RON",
+		Rate:          decimal.RequireFromString("0.2"),
+		Spread:        decimal.RequireFromString("0.01"),
+		EffectiveAt:   time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateFXRate() error = %v", err)
+	}
+	err = s.SetFeeSchedule(ctx, &FeeSchedule{
+		TransactionType: FeeTransactionTypeConversion,
+		Currency:        "CZK",
+		FeeType:         FeeTypeFlat,
+		FlatAmount:      decimal.NewFromInt(1),
+	})
+	if err != nil {
+		t.Fatalf("SetFeeSchedule() error = %v", err)
+	}
+
+	transfer := func(from, to string) (string, error) {
+		request := &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: TransactionTypeTransfer,
+			Amount:          decimal.NewFromInt(10),
+			Currency:        "CZK",
+			Status:          TransactionStatusSuccess,
+		}
+		if err := s.PrepareConversion(ctx, request); err != nil {
+			return "", err
+		}
+		if err := s.PrepareFee(ctx, request); err != nil {
+			return "", err
+		}
+		_, err := s.CreateTransaction(ctx, request)
+		return request.TransactionID, err
+	}
+
+	var originals []string
+	for range 10 {
+		id, err := transfer(createTestAccount(t, s, "100", "CZK"), createTestAccount(t, s, "0", "RON"))
+		if err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+		originals = append(originals, id)
+	}
+
+	// the forward path locks the fx accounts before the fee income account,
+	// the reversals have to take them in the same order
+	var wg sync.WaitGroup
+	for _, original := range originals {
+		from, to := createTestAccount(t, s, "100", "CZK"), createTestAccount(t, s, "0", "RON")
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, err := s.ReverseTransaction(ctx, &ReversalRequest{
+				TransactionID:         idx.UUID4(),
+				OriginalTransactionID: original,
+				Reason:                "mistake",
+			})
+			if err != nil {
+				t.Errorf("ReverseTransaction() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := transfer(from, to); err != nil {
+				t.Errorf("CreateTransaction() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
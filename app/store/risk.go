@@ -0,0 +1,396 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// Decisions of a risk rule, from the least to the most severe
+const (
+	RiskDecisionAllow  = "allow"
+	RiskDecisionReview = "review"
+	RiskDecisionDeny   = "deny"
+)
+
+// Statuses of the review of a transaction pending review
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// RiskDecision is what a risk rule decided about a transfer and why
+type RiskDecision struct {
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// TransferStats summarizes the transfers an account sent
+type TransferStats struct {
+	Count   int
+	Average decimal.Decimal
+}
+
+// TransactionReview is an operator's resolution of a transaction pending
+// review. Request is the reviewed transfer, set when it is resolved.
+type TransactionReview struct {
+	TransactionID string
+	Reason        string
+	Actor         string
+	Request       *TransactionRequest
+}
+
+// SaveRiskDecisions stores the decisions of the risk rules on a transfer
+func (s *Store) SaveRiskDecisions(
+	ctx context.Context,
+	transactionID string,
+	fromAccountID string,
+	decisions []*RiskDecision,
+) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	builder := s.db.Builder.
+		Insert("dbank_risk_decisions").
+		Columns("transaction_id", "from_account_id", "rule", "decision", "reason")
+	for _, decision := range decisions {
+		builder = builder.Values(transactionID, fromAccountID, decision.Rule, decision.Decision, decision.Reason)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = s.db.Pool.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save risk decisions", "error", err)
+		return status.Errorf(codes.Internal, "failed to save risk decisions")
+	}
+
+	return nil
+}
+
+// GetRiskDecisions returns the decisions of the risk rules on a transfer
+func (s *Store) GetRiskDecisions(
+	ctx context.Context,
+	transactionID string,
+) ([]*RiskDecision, error) {
+	sql, args, err := s.db.Builder.
+		Select("rule", "decision", "reason").
+		From("dbank_risk_decisions").
+		Where("transaction_id = ?", transactionID).
+		OrderBy("pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query risk decisions", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query risk decisions")
+	}
+
+	decisions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[RiskDecision])
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to scan risk decisions", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to scan risk decisions")
+	}
+
+	return decisions, nil
+}
+
+// outgoingTransfers selects the transfers an account sent that make up its history
+func (s *Store) outgoingTransfers(fromAccountID string) squirrel.SelectBuilder {
+	return s.db.Builder.
+		Select().
+		From("dbank_transactions").
+		Where("from_account_id = ?", fromAccountID).
+		Where(squirrel.Eq{"status": outgoingStatuses}).
+		Where("transaction_type <> ?", TransactionTypeReversal).
+		Where("deleted_at IS NULL")
+}
+
+// PayeeTransferCount returns how many transfers an account sent to a payee
+func (s *Store) PayeeTransferCount(
+	ctx context.Context,
+	fromAccountID, toAccountID string,
+) (int, error) {
+	sql, args, err := s.outgoingTransfers(fromAccountID).
+		Column("COUNT(*)").
+		Where("to_account_id = ?", toAccountID).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var count int
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		s.logger.ErrorContext(ctx, "failed to count payee transfers", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to count payee transfers")
+	}
+
+	return count, nil
+}
+
+// NewPayeeCount returns how many payees an account first sent money to since a time
+func (s *Store) NewPayeeCount(
+	ctx context.Context,
+	fromAccountID string,
+	since time.Time,
+) (int, error) {
+	payees := s.outgoingTransfers(fromAccountID).
+		Column("to_account_id").
+		GroupBy("to_account_id").
+		Having("MIN(created_at) >= ?", since)
+
+	sql, args, err := s.db.Builder.
+		Select("COUNT(*)").
+		FromSelect(payees, "payees").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var count int
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		s.logger.ErrorContext(ctx, "failed to count new payees", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to count new payees")
+	}
+
+	return count, nil
+}
+
+// TransferStatsSince returns the number and average amount of the transfers
+// an account sent since a time
+func (s *Store) TransferStatsSince(
+	ctx context.Context,
+	fromAccountID string,
+	since time.Time,
+) (*TransferStats, error) {
+	sql, args, err := s.outgoingTransfers(fromAccountID).
+		Columns("COUNT(*)", "COALESCE(AVG(amount), 0)").
+		Where("created_at >= ?", since).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var stats TransferStats
+	if err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&stats.Count, &stats.Average); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query transfer stats", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query transfer stats")
+	}
+
+	return &stats, nil
+}
+
+// insertReview stores the transfer of a transaction pending review, so that
+// it can be posted as it was requested when it is approved
+func (s *Store) insertReview(
+	ctx context.Context,
+	tx pgx.Tx,
+	transactionPK int,
+	request *TransactionRequest,
+) error {
+	reviewed := *request
+	reviewed.IdempotencyKey = nil
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_transaction_reviews").
+		Columns("transaction_pk", "request").
+		Values(transactionPK, &reviewed).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert transaction review", "error", err)
+		return status.Errorf(codes.Internal, "failed to insert transaction review")
+	}
+
+	return nil
+}
+
+// lockReview locks a transaction pending review and returns its pk and transfer
+func (s *Store) lockReview(
+	ctx context.Context,
+	tx pgx.Tx,
+	transactionID string,
+) (int, *TransactionRequest, error) {
+	sql, args, err := s.db.Builder.
+		Select("t.pk", "t.status", "r.request").
+		From("dbank_transactions t").
+		Join("dbank_transaction_reviews r ON r.transaction_pk = t.pk").
+		Where("t.id = ?", transactionID).
+		Where("t.deleted_at IS NULL").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var transactionPK int
+	var transactionStatus string
+	var body []byte
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&transactionPK, &transactionStatus, &body); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, status.Errorf(codes.NotFound, "transaction review not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to lock transaction review", "error", err)
+		return 0, nil, status.Errorf(codes.Internal, "failed to lock transaction review")
+	}
+
+	if transactionStatus != TransactionStatusPendingReview {
+		return 0, nil, status.Errorf(codes.FailedPrecondition, "transaction is %s, not pending review", transactionStatus)
+	}
+
+	var request TransactionRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		s.logger.ErrorContext(ctx, "failed to decode reviewed transaction", "error", err)
+		return 0, nil, status.Errorf(codes.Internal, "failed to decode reviewed transaction")
+	}
+
+	return transactionPK, &request, nil
+}
+
+// resolveReview writes the outcome of the review of a locked transaction
+func (s *Store) resolveReview(
+	ctx context.Context,
+	tx pgx.Tx,
+	transactionPK int,
+	transactionStatus, reviewStatus string,
+	review *TransactionReview,
+) error {
+	sql, args, err := s.db.Builder.
+		Update("dbank_transactions").
+		Set("status", transactionStatus).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("pk = ?", transactionPK).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update transaction status", "error", err)
+		return status.Errorf(codes.Internal, "failed to update transaction status")
+	}
+
+	sql, args, err = s.db.Builder.
+		Update("dbank_transaction_reviews").
+		Set("status", reviewStatus).
+		Set("reason", review.Reason).
+		Set("actor", review.Actor).
+		Set("resolved_at", squirrel.Expr("now()")).
+		Where("transaction_pk = ?", transactionPK).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update transaction review", "error", err)
+		return status.Errorf(codes.Internal, "failed to update transaction review")
+	}
+
+	return nil
+}
+
+// ApproveTransaction posts a transaction pending review as it was requested.
+// The balance is checked again, an approval that the sender cannot afford
+// fails and leaves the transaction pending.
+func (s *Store) ApproveTransaction(
+	ctx context.Context,
+	review *TransactionReview,
+) ([]*LedgerEntry, error) {
+	var entries []*LedgerEntry
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		transactionPK, request, err := s.lockReview(ctx, tx, review.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := s.lockAccounts(ctx, tx, request.FromAccountID, request.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		fromAccount := accounts[request.FromAccountID]
+		toAccount := accounts[request.ToAccountID]
+
+		if err = checkAccountStatuses(fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		if err = checkConversion(request, fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		if _, err = s.releaseExpiredHolds(ctx, tx, fromAccount); err != nil {
+			return err
+		}
+		if fromAccount.available().LessThan(request.Amount.Add(request.feeAmount())) {
+			return insufficientFundsError()
+		}
+
+		if entries, err = s.postTransaction(ctx, tx, request, transactionPK, fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		review.Request = request
+		return s.resolveReview(ctx, tx, transactionPK, TransactionStatusSuccess, ReviewStatusApproved, review)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to approve transaction", "error", err,
+			"transaction_id", review.TransactionID)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// RejectTransaction rejects a transaction pending review, it never moves money
+func (s *Store) RejectTransaction(
+	ctx context.Context,
+	review *TransactionReview,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		transactionPK, request, err := s.lockReview(ctx, tx, review.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		review.Request = request
+		return s.resolveReview(ctx, tx, transactionPK, TransactionStatusRejected, ReviewStatusRejected, review)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to reject transaction", "error", err,
+			"transaction_id", review.TransactionID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_TransactionReview(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "100", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	pending := func() string {
+		t.Helper()
+		request := &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: "transfer",
+			Amount:          decimal.NewFromInt(30),
+			Currency:        "USD",
+			Status:          TransactionStatusPendingReview,
+		}
+		if _, err := s.CreateTransaction(ctx, request); err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+		return request.TransactionID
+	}
+
+	approved := pending()
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("sender balance while pending = %s, want 100", got)
+	}
+
+	// a pending transfer is part of the history of the sender
+	if count, err := s.PayeeTransferCount(ctx, from, to); err != nil || count != 1 {
+		t.Errorf("PayeeTransferCount() = %d, %v, want 1", count, err)
+	}
+	if count, err := s.NewPayeeCount(ctx, from, time.Now().Add(-time.Hour)); err != nil || count != 1 {
+		t.Errorf("NewPayeeCount() = %d, %v, want 1", count, err)
+	}
+
+	if _, err := s.ApproveTransaction(ctx, &TransactionReview{TransactionID: approved, Actor: "ops"}); err != nil {
+		t.Fatalf("ApproveTransaction() error = %v", err)
+	}
+	if got := accountBalance(t, s, to); !got.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("receiver balance after approval = %s, want 30", got)
+	}
+	if transaction, err := s.GetTransaction(ctx, approved); err != nil || transaction.Status != TransactionStatusSuccess {
+		t.Errorf("GetTransaction() after approval = %+v, %v, want success", transaction, err)
+	}
+
+	_, err := s.ApproveTransaction(ctx, &TransactionReview{TransactionID: approved})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ApproveTransaction() twice error = %v, want FailedPrecondition", err)
+	}
+
+	rejected := pending()
+	review := &TransactionReview{TransactionID: rejected, Reason: "fraud", Actor: "ops"}
+	if err = s.RejectTransaction(ctx, review); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+	if review.Request == nil || !review.Request.Amount.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("RejectTransaction() request = %+v, want the transfer of 30", review.Request)
+	}
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("sender balance after rejection = %s, want 70", got)
+	}
+	if transaction, err := s.GetTransaction(ctx, rejected); err != nil || transaction.Status != TransactionStatusRejected {
+		t.Errorf("GetTransaction() after rejection = %+v, %v, want rejected", transaction, err)
+	}
+
+	decisions := []*RiskDecision{
+		{Rule: "amount", Decision: RiskDecisionReview, Reason: "large"},
+		{Rule: "velocity", Decision: RiskDecisionAllow},
+	}
+	if err = s.SaveRiskDecisions(ctx, rejected, from, decisions); err != nil {
+		t.Fatalf("SaveRiskDecisions() error = %v", err)
+	}
+	saved, err := s.GetRiskDecisions(ctx, rejected)
+	if err != nil || len(saved) != 2 || saved[0].Rule != "amount" || saved[1].Decision != RiskDecisionAllow {
+		t.Errorf("GetRiskDecisions() = %+v, %v, want the saved decisions", saved, err)
+	}
+}
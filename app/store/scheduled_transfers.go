@@ -0,0 +1,655 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/cronx"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyCron    = "cron"
+)
+
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusCompleted = "completed"
+)
+
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunRetrying  = "retrying"
+	ScheduledRunFailed    = "failed"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail of the store errors
+// that clients and workers tell apart by their reason
+const ErrorDomain = "dbank"
+
+// ReasonInsufficientFunds is the reason of the ErrorInfo detail of the error
+// returned when the sender cannot cover the amount
+const ReasonInsufficientFunds = "INSUFFICIENT_FUNDS"
+
+// insufficientFundsMessage is the message of the insufficient funds error
+const insufficientFundsMessage = "insufficient balance in from account"
+
+// insufficientFundsError is the InvalidArgument error of a sender that cannot
+// cover the amount, with an ErrorInfo detail of ReasonInsufficientFunds
+func insufficientFundsError() error {
+	st, err := status.New(codes.InvalidArgument, insufficientFundsMessage).WithDetails(&errdetails.ErrorInfo{
+		Reason: ReasonInsufficientFunds,
+		Domain: ErrorDomain,
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, insufficientFundsMessage)
+	}
+	return st.Err()
+}
+
+// IsInsufficientFunds reports whether err, possibly wrapped by the service
+// layer, carries the ErrorInfo detail of the insufficient funds error
+func IsInsufficientFunds(err error) bool {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			return info.Reason == ReasonInsufficientFunds
+		}
+	}
+	return false
+}
+
+// ScheduledTransfer is a standing order. Occurrences start at StartAt and
+// repeat with Frequency until EndAt or MaxRuns, a zero MaxRuns is unlimited.
+// An occurrence that fails for insufficient funds is retried up to MaxRetries
+// times, RetryInterval apart.
+type ScheduledTransfer struct {
+	ID               string          `json:"id"`
+	FromAccountID    string          `json:"from_account_id"`
+	ToAccountID      string          `json:"to_account_id"`
+	Amount           decimal.Decimal `json:"amount"`
+	Currency         string          `json:"currency"`
+	Description      string          `json:"description"`
+	Frequency        string          `json:"frequency"`
+	CronExpression   string          `json:"cron_expression,omitempty"`
+	StartAt          time.Time       `json:"start_at"`
+	EndAt            *time.Time      `json:"end_at,omitempty"`
+	MaxRuns          int             `json:"max_runs,omitempty"`
+	RunCount         int             `json:"run_count"`
+	MaxRetries       int             `json:"max_retries"`
+	RetryInterval    time.Duration   `json:"retry_interval"`
+	Attempt          int             `json:"attempt"`
+	NextOccurrenceAt *time.Time      `json:"next_occurrence_at,omitempty"`
+	NextRunAt        *time.Time      `json:"next_run_at,omitempty"`
+	Status           string          `json:"status"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+
+	pk int
+}
+
+// ScheduledTransferRun is the outcome of one attempt of an occurrence
+type ScheduledTransferRun struct {
+	ID            string    `json:"id"`
+	OccurrenceAt  time.Time `json:"occurrence_at"`
+	Attempt       int       `json:"attempt"`
+	Status        string    `json:"status"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// firstOccurrence returns the first occurrence at or after StartAt
+func (t *ScheduledTransfer) firstOccurrence() (time.Time, bool) {
+	if t.Frequency == FrequencyCron {
+		return t.nextOccurrence(t.StartAt.Add(-time.Nanosecond))
+	}
+	return t.StartAt, t.EndAt == nil || !t.StartAt.After(*t.EndAt)
+}
+
+// nextOccurrence returns the occurrence after previous, false when the
+// schedule has no more occurrences before EndAt. It does not look at MaxRuns.
+func (t *ScheduledTransfer) nextOccurrence(previous time.Time) (time.Time, bool) {
+	var next time.Time
+	switch t.Frequency {
+	case FrequencyDaily:
+		next = previous.AddDate(0, 0, 1)
+	case FrequencyWeekly:
+		next = previous.AddDate(0, 0, 7)
+	case FrequencyMonthly:
+		// counted from StartAt so that a schedule on the 31st comes back to
+		// the 31st after being clamped to the end of a shorter month
+		months := (previous.Year()-t.StartAt.Year())*12 + int(previous.Month()-t.StartAt.Month())
+		next = addMonthsClamped(t.StartAt, months+1)
+	case FrequencyCron:
+		schedule, err := cronx.Parse(t.CronExpression)
+		if err != nil {
+			return time.Time{}, false
+		}
+		next = schedule.Next(previous)
+		if next.IsZero() {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if t.EndAt != nil && next.After(*t.EndAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// addMonthsClamped adds months to t, clamping the day to the end of the month
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+// scheduledTransferColumns are the columns scanned by scanScheduledTransfer
+var scheduledTransferColumns = []string{
+	"pk", "id", "from_account_id", "to_account_id", "amount", "currency", "description",
+	"frequency", "cron_expression", "start_at", "end_at", "COALESCE(max_runs, 0)", "run_count",
+	"max_retries", "retry_interval_seconds", "attempt", "next_occurrence_at", "next_run_at",
+	"status", "created_at", "updated_at",
+}
+
+func scanScheduledTransfer(row pgx.Row) (*ScheduledTransfer, error) {
+	var (
+		transfer             ScheduledTransfer
+		retryIntervalSeconds int
+	)
+	err := row.Scan(
+		&transfer.pk,
+		&transfer.ID,
+		&transfer.FromAccountID,
+		&transfer.ToAccountID,
+		&transfer.Amount,
+		&transfer.Currency,
+		&transfer.Description,
+		&transfer.Frequency,
+		&transfer.CronExpression,
+		&transfer.StartAt,
+		&transfer.EndAt,
+		&transfer.MaxRuns,
+		&transfer.RunCount,
+		&transfer.MaxRetries,
+		&retryIntervalSeconds,
+		&transfer.Attempt,
+		&transfer.NextOccurrenceAt,
+		&transfer.NextRunAt,
+		&transfer.Status,
+		&transfer.CreatedAt,
+		&transfer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.RetryInterval = time.Duration(retryIntervalSeconds) * time.Second
+	return &transfer, nil
+}
+
+// CreateScheduledTransfer stores a new active schedule and sets its first occurrence
+func (s *Store) CreateScheduledTransfer(
+	ctx context.Context,
+	transfer *ScheduledTransfer,
+) error {
+	if transfer.Frequency == FrequencyCron {
+		if _, err := cronx.Parse(transfer.CronExpression); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid cron_expression: %v", err)
+		}
+	}
+
+	first, ok := transfer.firstOccurrence()
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "the schedule has no occurrence before its end")
+	}
+
+	transfer.ID = idx.UUID4()
+	transfer.Status = ScheduleStatusActive
+	transfer.NextOccurrenceAt = &first
+	transfer.NextRunAt = &first
+
+	var maxRuns any
+	if transfer.MaxRuns > 0 {
+		maxRuns = transfer.MaxRuns
+	}
+
+	sql, args, err := s.db.Builder.
+		Insert("dbank_scheduled_transfers").
+		Columns(
+			"id", "from_account_id", "to_account_id", "amount", "currency", "description",
+			"frequency", "cron_expression", "start_at", "end_at", "max_runs",
+			"max_retries", "retry_interval_seconds", "next_occurrence_at", "next_run_at", "status",
+		).
+		Values(
+			transfer.ID, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.Currency,
+			transfer.Description, transfer.Frequency, transfer.CronExpression, transfer.StartAt, transfer.EndAt,
+			maxRuns, transfer.MaxRetries, int(transfer.RetryInterval.Seconds()),
+			transfer.NextOccurrenceAt, transfer.NextRunAt, transfer.Status,
+		).
+		Suffix("RETURNING pk, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&transfer.pk, &transfer.CreatedAt, &transfer.UpdatedAt)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to insert scheduled transfer", "error", err)
+		return status.Errorf(codes.Internal, "failed to insert scheduled transfer")
+	}
+
+	return nil
+}
+
+// GetScheduledTransfer returns a schedule by id
+func (s *Store) GetScheduledTransfer(
+	ctx context.Context,
+	id string,
+) (*ScheduledTransfer, error) {
+	sql, args, err := s.db.Builder.
+		Select(scheduledTransferColumns...).
+		From("dbank_scheduled_transfers").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	transfer, err := scanScheduledTransfer(s.db.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query scheduled transfer", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query scheduled transfer")
+	}
+
+	return transfer, nil
+}
+
+// ListScheduledTransfers returns the schedules paying from or into an account, newest first
+func (s *Store) ListScheduledTransfers(
+	ctx context.Context,
+	accountID string,
+) ([]*ScheduledTransfer, error) {
+	sql, args, err := s.db.Builder.
+		Select(scheduledTransferColumns...).
+		From("dbank_scheduled_transfers").
+		Where(squirrel.Or{
+			squirrel.Eq{"from_account_id": accountID},
+			squirrel.Eq{"to_account_id": accountID},
+		}).
+		OrderBy("created_at DESC", "pk DESC").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query scheduled transfers", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query scheduled transfers")
+	}
+	defer rows.Close()
+
+	var transfers []*ScheduledTransfer
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan scheduled transfer", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to scan scheduled transfer")
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query scheduled transfers", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query scheduled transfers")
+	}
+
+	return transfers, nil
+}
+
+// ListScheduledTransferRuns returns the attempts of a schedule, newest first
+func (s *Store) ListScheduledTransferRuns(
+	ctx context.Context,
+	id string,
+) ([]*ScheduledTransferRun, error) {
+	sql, args, err := s.db.Builder.
+		Select(
+			"r.id", "r.occurrence_at", "r.attempt", "r.status",
+			"COALESCE(r.transaction_id::text, '')", "r.error", "r.created_at",
+		).
+		From("dbank_scheduled_transfer_runs r").
+		Join("dbank_scheduled_transfers t ON t.pk = r.scheduled_transfer_pk").
+		Where("t.id = ?", id).
+		OrderBy("r.pk DESC").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query scheduled transfer runs", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query scheduled transfer runs")
+	}
+	defer rows.Close()
+
+	var runs []*ScheduledTransferRun
+	for rows.Next() {
+		var run ScheduledTransferRun
+		err = rows.Scan(
+			&run.ID,
+			&run.OccurrenceAt,
+			&run.Attempt,
+			&run.Status,
+			&run.TransactionID,
+			&run.Error,
+			&run.CreatedAt,
+		)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan scheduled transfer run", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to scan scheduled transfer run")
+		}
+		runs = append(runs, &run)
+	}
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query scheduled transfer runs", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query scheduled transfer runs")
+	}
+
+	return runs, nil
+}
+
+// PauseScheduledTransfer stops the occurrences of an active schedule until it is resumed
+func (s *Store) PauseScheduledTransfer(
+	ctx context.Context,
+	id string,
+) (*ScheduledTransfer, error) {
+	return s.updateScheduledTransfer(ctx, id, func(transfer *ScheduledTransfer) error {
+		if transfer.Status != ScheduleStatusActive {
+			return status.Errorf(codes.FailedPrecondition, "cannot pause a %s scheduled transfer", transfer.Status)
+		}
+		transfer.Status = ScheduleStatusPaused
+		return nil
+	})
+}
+
+// ResumeScheduledTransfer reactivates a paused schedule. The occurrences that
+// fell due while it was paused are skipped, they do not count as runs.
+func (s *Store) ResumeScheduledTransfer(
+	ctx context.Context,
+	id string,
+	now time.Time,
+) (*ScheduledTransfer, error) {
+	return s.updateScheduledTransfer(ctx, id, func(transfer *ScheduledTransfer) error {
+		if transfer.Status != ScheduleStatusPaused {
+			return status.Errorf(codes.FailedPrecondition, "cannot resume a %s scheduled transfer", transfer.Status)
+		}
+
+		transfer.Status = ScheduleStatusActive
+		transfer.Attempt = 0
+
+		next, ok := *transfer.NextOccurrenceAt, true
+		for ok && next.Before(now) {
+			next, ok = transfer.nextOccurrence(next)
+		}
+		if !ok {
+			transfer.complete()
+			return nil
+		}
+
+		transfer.NextOccurrenceAt = &next
+		transfer.NextRunAt = &next
+		return nil
+	})
+}
+
+// CancelScheduledTransfer stops a schedule for good
+func (s *Store) CancelScheduledTransfer(
+	ctx context.Context,
+	id string,
+) (*ScheduledTransfer, error) {
+	return s.updateScheduledTransfer(ctx, id, func(transfer *ScheduledTransfer) error {
+		switch transfer.Status {
+		case ScheduleStatusActive, ScheduleStatusPaused:
+		default:
+			return status.Errorf(codes.FailedPrecondition, "cannot cancel a %s scheduled transfer", transfer.Status)
+		}
+
+		transfer.Status = ScheduleStatusCancelled
+		transfer.NextOccurrenceAt = nil
+		transfer.NextRunAt = nil
+		return nil
+	})
+}
+
+// complete ends a schedule that has no more occurrences
+func (t *ScheduledTransfer) complete() {
+	t.Status = ScheduleStatusCompleted
+	t.NextOccurrenceAt = nil
+	t.NextRunAt = nil
+}
+
+// updateScheduledTransfer applies change to a locked schedule and saves it
+func (s *Store) updateScheduledTransfer(
+	ctx context.Context,
+	id string,
+	change func(transfer *ScheduledTransfer) error,
+) (*ScheduledTransfer, error) {
+	var transfer *ScheduledTransfer
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select(scheduledTransferColumns...).
+			From("dbank_scheduled_transfers").
+			Where("id = ?", id).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		transfer, err = scanScheduledTransfer(tx.QueryRow(ctx, sql, args...))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "scheduled transfer not found")
+			}
+			s.logger.ErrorContext(ctx, "failed to lock scheduled transfer", "error", err)
+			return status.Errorf(codes.Internal, "failed to lock scheduled transfer")
+		}
+
+		if err = change(transfer); err != nil {
+			return err
+		}
+
+		return s.saveScheduledTransfer(ctx, tx, transfer)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to update scheduled transfer", "error", err, "id", id)
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// saveScheduledTransfer writes the progress and status of a locked schedule
+func (s *Store) saveScheduledTransfer(
+	ctx context.Context,
+	tx pgx.Tx,
+	transfer *ScheduledTransfer,
+) error {
+	sql, args, err := s.db.Builder.
+		Update("dbank_scheduled_transfers").
+		Set("status", transfer.Status).
+		Set("run_count", transfer.RunCount).
+		Set("attempt", transfer.Attempt).
+		Set("next_occurrence_at", transfer.NextOccurrenceAt).
+		Set("next_run_at", transfer.NextRunAt).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("pk = ?", transfer.pk).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&transfer.UpdatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update scheduled transfer", "error", err)
+		return status.Errorf(codes.Internal, "failed to update scheduled transfer")
+	}
+
+	return nil
+}
+
+// ScheduledTransferExecutor moves the money of one occurrence of a schedule
+// and returns the id of the transaction
+type ScheduledTransferExecutor func(
+	ctx context.Context,
+	transfer *ScheduledTransfer,
+	occurrence time.Time,
+) (string, error)
+
+// ProcessDueScheduledTransfer runs the attempt of one schedule that is due at
+// now and records its outcome, it returns false when nothing was due.
+//
+// The schedule row stays locked with FOR UPDATE SKIP LOCKED while execute
+// runs, so concurrent workers, also in other processes, pick different
+// schedules. execute must be idempotent per occurrence: if the process dies
+// after the money moved but before the outcome is committed, the same
+// occurrence is executed again.
+func (s *Store) ProcessDueScheduledTransfer(
+	ctx context.Context,
+	now time.Time,
+	execute ScheduledTransferExecutor,
+) (bool, error) {
+	var processed bool
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select(scheduledTransferColumns...).
+			From("dbank_scheduled_transfers").
+			Where("status = ?", ScheduleStatusActive).
+			Where("next_run_at <= ?", now).
+			OrderBy("next_run_at", "pk").
+			Limit(1).
+			Suffix("FOR UPDATE SKIP LOCKED").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		transfer, err := scanScheduledTransfer(tx.QueryRow(ctx, sql, args...))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			s.logger.ErrorContext(ctx, "failed to claim scheduled transfer", "error", err)
+			return status.Errorf(codes.Internal, "failed to claim scheduled transfer")
+		}
+		processed = true
+
+		occurrence := *transfer.NextOccurrenceAt
+		run := &ScheduledTransferRun{
+			ID:           idx.UUID4(),
+			OccurrenceAt: occurrence,
+			Attempt:      transfer.Attempt + 1,
+		}
+
+		transactionID, err := execute(ctx, transfer, occurrence)
+		if ctx.Err() != nil {
+			// shutting down, leave the occurrence to the next run
+			return ctx.Err()
+		}
+
+		switch {
+		case err == nil:
+			run.Status = ScheduledRunSucceeded
+			run.TransactionID = transactionID
+		case IsInsufficientFunds(err) && transfer.Attempt < transfer.MaxRetries:
+			run.Status = ScheduledRunRetrying
+			run.Error = status.Convert(err).Message()
+		default:
+			run.Status = ScheduledRunFailed
+			run.Error = status.Convert(err).Message()
+		}
+
+		if run.Status == ScheduledRunRetrying {
+			retryAt := now.Add(transfer.RetryInterval)
+			transfer.Attempt++
+			transfer.NextRunAt = &retryAt
+		} else {
+			transfer.RunCount++
+			transfer.Attempt = 0
+			next, ok := transfer.nextOccurrence(occurrence)
+			if !ok || (transfer.MaxRuns > 0 && transfer.RunCount >= transfer.MaxRuns) {
+				transfer.complete()
+			} else {
+				transfer.NextOccurrenceAt = &next
+				transfer.NextRunAt = &next
+			}
+		}
+
+		var runTransactionID any
+		if run.TransactionID != "" {
+			runTransactionID = run.TransactionID
+		}
+
+		sql, args, err = s.db.Builder.
+			Insert("dbank_scheduled_transfer_runs").
+			Columns("id", "scheduled_transfer_pk", "occurrence_at", "attempt", "status", "transaction_id", "error").
+			Values(run.ID, transfer.pk, run.OccurrenceAt, run.Attempt, run.Status, runTransactionID, run.Error).
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			s.logger.ErrorContext(ctx, "failed to insert scheduled transfer run", "error", err)
+			return status.Errorf(codes.Internal, "failed to insert scheduled transfer run")
+		}
+
+		if err = s.saveScheduledTransfer(ctx, tx, transfer); err != nil {
+			return err
+		}
+
+		s.logger.InfoContext(ctx, "scheduled transfer processed",
+			"scheduled_transfer_id", transfer.ID,
+			"occurrence_at", occurrence,
+			"attempt", run.Attempt,
+			"status", run.Status,
+		)
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to process scheduled transfer", "error", err)
+		return false, err
+	}
+
+	return processed, nil
+}
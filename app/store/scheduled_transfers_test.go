@@ -0,0 +1,261 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestScheduledTransfer_nextOccurrence(t *testing.T) {
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	endAt := time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		transfer ScheduledTransfer
+		want     []time.Time
+	}{
+		{
+			name:     "once",
+			transfer: ScheduledTransfer{Frequency: FrequencyOnce, StartAt: start},
+			want:     []time.Time{start},
+		},
+		{
+			name:     "daily",
+			transfer: ScheduledTransfer{Frequency: FrequencyDaily, StartAt: start, EndAt: ptr(start.AddDate(0, 0, 2))},
+			want:     []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)},
+		},
+		{
+			name:     "weekly",
+			transfer: ScheduledTransfer{Frequency: FrequencyWeekly, StartAt: start, EndAt: ptr(start.AddDate(0, 0, 20))},
+			want:     []time.Time{start, start.AddDate(0, 0, 7), start.AddDate(0, 0, 14)},
+		},
+		{
+			name:     "monthly clamps to the end of the month",
+			transfer: ScheduledTransfer{Frequency: FrequencyMonthly, StartAt: start, EndAt: &endAt},
+			want: []time.Time{
+				start,
+				time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "cron",
+			transfer: ScheduledTransfer{
+				Frequency: FrequencyCron, CronExpression: "0 9 1 * *", StartAt: start, EndAt: &endAt,
+			},
+			want: []time.Time{
+				time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []time.Time
+			next, ok := tt.transfer.firstOccurrence()
+			for ok && len(got) < 10 {
+				got = append(got, next)
+				next, ok = tt.transfer.nextOccurrence(next)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("occurrences = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestIsInsufficientFunds(t *testing.T) {
+	if !IsInsufficientFunds(insufficientFundsError()) {
+		t.Errorf("IsInsufficientFunds() of the insufficient funds error = false, want true")
+	}
+	if IsInsufficientFunds(status.Error(codes.InvalidArgument, insufficientFundsMessage)) {
+		t.Errorf("IsInsufficientFunds() of an error with only its message = true, want false")
+	}
+	if IsInsufficientFunds(nil) {
+		t.Errorf("IsInsufficientFunds(nil) = true, want false")
+	}
+}
+
+func TestStore_ProcessDueScheduledTransfer(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "100", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	transfer := &ScheduledTransfer{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        decimal.NewFromInt(10),
+		Currency:      "USD",
+		Frequency:     FrequencyDaily,
+		StartAt:       start,
+		MaxRuns:       2,
+		MaxRetries:    1,
+		RetryInterval: time.Minute,
+	}
+	if err := s.CreateScheduledTransfer(ctx, transfer); err != nil {
+		t.Fatalf("CreateScheduledTransfer() error = %v", err)
+	}
+
+	// the executor only sees the schedule created by this test, other
+	// schedules in a shared database are skipped with a successful run
+	var calls atomic.Int32
+	outcome := func(err error) ScheduledTransferExecutor {
+		return func(_ context.Context, due *ScheduledTransfer, occurrence time.Time) (string, error) {
+			if due.ID != transfer.ID {
+				return idx.UUID4(), nil
+			}
+			calls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return idx.UUID4(), err
+		}
+	}
+	runAll := func(now time.Time, execute ScheduledTransferExecutor) {
+		for {
+			processed, err := s.ProcessDueScheduledTransfer(ctx, now, execute)
+			if err != nil {
+				t.Fatalf("ProcessDueScheduledTransfer() error = %v", err)
+			}
+			if !processed {
+				return
+			}
+		}
+	}
+
+	// two workers at once execute the due occurrence only once
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runAll(time.Now(), outcome(insufficientFundsError()))
+		}()
+	}
+	wg.Wait()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("executor called %d times, want 1", got)
+	}
+
+	got, err := s.GetScheduledTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("GetScheduledTransfer() error = %v", err)
+	}
+	if got.Attempt != 1 || got.RunCount != 0 || !got.NextOccurrenceAt.Equal(start) || !got.NextRunAt.After(start) {
+		t.Errorf("after insufficient funds = %+v, want a retry of the first occurrence", got)
+	}
+
+	// the retry is not due before the retry interval
+	runAll(time.Now(), outcome(nil))
+	if calls.Load() != 1 {
+		t.Fatalf("retry ran before its retry interval")
+	}
+
+	// the retry fails again and exhausts the retries, so the occurrence counts as failed
+	runAll(time.Now().Add(2*time.Minute), outcome(insufficientFundsError()))
+	got, err = s.GetScheduledTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("GetScheduledTransfer() error = %v", err)
+	}
+	if got.RunCount != 1 || got.Attempt != 0 || !got.NextOccurrenceAt.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("after exhausted retries = %+v, want the next day's occurrence", got)
+	}
+
+	// the second occurrence succeeds and reaches max runs
+	runAll(start.AddDate(0, 0, 1), outcome(nil))
+	got, err = s.GetScheduledTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("GetScheduledTransfer() error = %v", err)
+	}
+	if got.Status != ScheduleStatusCompleted || got.RunCount != 2 || got.NextRunAt != nil {
+		t.Errorf("after max runs = %+v, want it completed", got)
+	}
+
+	runs, err := s.ListScheduledTransferRuns(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("ListScheduledTransferRuns() error = %v", err)
+	}
+	var statuses []string
+	for _, run := range runs {
+		statuses = append(statuses, run.Status)
+	}
+	want := []string{ScheduledRunSucceeded, ScheduledRunFailed, ScheduledRunRetrying}
+	if len(statuses) != len(want) {
+		t.Fatalf("runs = %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("runs = %v, want %v", statuses, want)
+			break
+		}
+	}
+}
+
+func TestStore_ScheduledTransferStatus(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	start := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Second)
+	transfer := &ScheduledTransfer{
+		FromAccountID: createTestAccount(t, s, "100", "USD"),
+		ToAccountID:   createTestAccount(t, s, "0", "USD"),
+		Amount:        decimal.NewFromInt(1),
+		Currency:      "USD",
+		Frequency:     FrequencyDaily,
+		StartAt:       start,
+		RetryInterval: time.Hour,
+	}
+	if err := s.CreateScheduledTransfer(ctx, transfer); err != nil {
+		t.Fatalf("CreateScheduledTransfer() error = %v", err)
+	}
+
+	if _, err := s.ResumeScheduledTransfer(ctx, transfer.ID, time.Now()); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ResumeScheduledTransfer() of an active schedule error = %v, want FailedPrecondition", err)
+	}
+
+	paused, err := s.PauseScheduledTransfer(ctx, transfer.ID)
+	if err != nil || paused.Status != ScheduleStatusPaused {
+		t.Fatalf("PauseScheduledTransfer() = %+v, %v, want it paused", paused, err)
+	}
+
+	now := time.Now()
+	resumed, err := s.ResumeScheduledTransfer(ctx, transfer.ID, now)
+	if err != nil {
+		t.Fatalf("ResumeScheduledTransfer() error = %v", err)
+	}
+	if resumed.Status != ScheduleStatusActive || resumed.NextOccurrenceAt.Before(now) ||
+		resumed.NextOccurrenceAt.After(now.Add(24*time.Hour)) {
+		t.Errorf("ResumeScheduledTransfer() = %+v, want the first occurrence after now", resumed)
+	}
+
+	cancelled, err := s.CancelScheduledTransfer(ctx, transfer.ID)
+	if err != nil || cancelled.Status != ScheduleStatusCancelled || cancelled.NextRunAt != nil {
+		t.Fatalf("CancelScheduledTransfer() = %+v, %v, want it cancelled", cancelled, err)
+	}
+
+	if _, err = s.PauseScheduledTransfer(ctx, transfer.ID); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("PauseScheduledTransfer() of a cancelled schedule error = %v, want FailedPrecondition", err)
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// Statement is the ledger activity of an account over the period [From, To)
+type Statement struct {
+	AccountID      string            `json:"account_id"`
+	AccountNumber  string            `json:"account_number"`
+	AccountName    string            `json:"account_name"`
+	Currency       string            `json:"currency"`
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	OpeningBalance decimal.Decimal   `json:"opening_balance"`
+	TotalDebits    decimal.Decimal   `json:"total_debits"`
+	TotalCredits   decimal.Decimal   `json:"total_credits"`
+	ClosingBalance decimal.Decimal   `json:"closing_balance"`
+	Entries        []*StatementEntry `json:"entries"`
+}
+
+// StatementEntry is a ledger posting together with the transaction it belongs to
+type StatementEntry struct {
+	LedgerEntry
+	TransactionType       string `json:"transaction_type"`
+	CounterpartyAccountID string `json:"counterparty_account_id"`
+}
+
+// GetStatement builds the statement of an account from its ledger postings.
+// All reads happen in one repeatable read transaction so that the opening
+// balance, the postings and the closing balance come from the same snapshot.
+func (s *Store) GetStatement(
+	ctx context.Context,
+	accountID string,
+	from, to time.Time,
+) (*Statement, error) {
+	statement := &Statement{
+		AccountID:    accountID,
+		From:         from,
+		To:           to,
+		TotalDebits:  decimal.Zero,
+		TotalCredits: decimal.Zero,
+	}
+
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := dbx.RunInTxWithOptions(ctx, s.logger, s.db, opts, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Select("pk", "account_number", "account_name", "currency", "balance").
+			From("dbank_accounts").
+			Where("id = ?", accountID).
+			// internal accounts have no statement
+			Where("system_code IS NULL").
+			Where("deleted_at IS NULL").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var accountPK int
+		var balance decimal.Decimal
+		err = tx.QueryRow(ctx, sql, args...).Scan(
+			&accountPK,
+			&statement.AccountNumber,
+			&statement.AccountName,
+			&statement.Currency,
+			&balance,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "account not found")
+			}
+			s.logger.ErrorContext(ctx, "failed to query account", "error", err)
+			return status.Errorf(codes.Internal, "failed to query account")
+		}
+
+		statement.OpeningBalance, err = s.balanceAt(ctx, tx, accountPK, balance, from)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = s.db.Builder.
+			Select(
+				"l.id", "t.id", "t.transaction_type", "l.entry_type",
+				"l.amount", "l.balance", "l.currency",
+			).
+			Column(squirrel.Expr(
+				"CASE WHEN t.from_account_id = ? THEN t.to_account_id ELSE t.from_account_id END", accountID,
+			)).
+			Columns("COALESCE(t.description, '')", "l.created_at").
+			From("dbank_ledgers l").
+			Join("dbank_transactions t ON t.pk = l.transaction_pk").
+			Where("l.account_pk = ?", accountPK).
+			Where("l.created_at >= ?", from).
+			Where("l.created_at < ?", to).
+			Where("l.deleted_at IS NULL").
+			OrderBy("l.created_at", "l.pk").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to query ledger entries", "error", err)
+			return status.Errorf(codes.Internal, "failed to query ledger entries")
+		}
+		defer rows.Close()
+
+		statement.ClosingBalance = statement.OpeningBalance
+		for rows.Next() {
+			entry := &StatementEntry{LedgerEntry: LedgerEntry{AccountID: accountID}}
+			err = rows.Scan(
+				&entry.ID,
+				&entry.TransactionID,
+				&entry.TransactionType,
+				&entry.EntryType,
+				&entry.Amount,
+				&entry.Balance,
+				&entry.Currency,
+				&entry.CounterpartyAccountID,
+				&entry.Description,
+				&entry.CreatedAt,
+			)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to scan ledger entry", "error", err)
+				return status.Errorf(codes.Internal, "failed to scan ledger entry")
+			}
+
+			if entry.Amount.IsNegative() {
+				statement.TotalDebits = statement.TotalDebits.Add(entry.Amount.Neg())
+			} else {
+				statement.TotalCredits = statement.TotalCredits.Add(entry.Amount)
+			}
+			statement.ClosingBalance = entry.Balance
+			statement.Entries = append(statement.Entries, entry)
+		}
+
+		if err = rows.Err(); err != nil {
+			s.logger.ErrorContext(ctx, "failed to iterate ledger entries", "error", err)
+			return status.Errorf(codes.Internal, "failed to iterate ledger entries")
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get statement", "error", err, "account_id", accountID)
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// balanceAt returns the balance of an account at the instant at.
+// That is the running balance of the last posting before at. Postings are
+// stamped when they are written under the account row lock, so their order by
+// time is the order they were written in. Balances that were seeded without a
+// posting are recovered from the first posting after at, and an account
+// without any posting since at still has its current balance.
+func (s *Store) balanceAt(
+	ctx context.Context,
+	tx pgx.Tx,
+	accountPK int,
+	current decimal.Decimal,
+	at time.Time,
+) (decimal.Decimal, error) {
+	queries := []squirrel.SelectBuilder{
+		s.db.Builder.
+			Select("balance").
+			From("dbank_ledgers").
+			Where("account_pk = ?", accountPK).
+			Where("created_at < ?", at).
+			Where("deleted_at IS NULL").
+			OrderBy("created_at DESC", "pk DESC").
+			Limit(1),
+		s.db.Builder.
+			Select("balance - amount").
+			From("dbank_ledgers").
+			Where("account_pk = ?", accountPK).
+			Where("created_at >= ?", at).
+			Where("deleted_at IS NULL").
+			OrderBy("created_at", "pk").
+			Limit(1),
+	}
+
+	for _, query := range queries {
+		sql, args, err := query.ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return decimal.Zero, status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var balance decimal.Decimal
+		err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+		if err == nil {
+			return balance, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.ErrorContext(ctx, "failed to query balance", "error", err)
+			return decimal.Zero, status.Errorf(codes.Internal, "failed to query balance")
+		}
+	}
+
+	return current, nil
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_GetStatement(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "100", "USD")
+	other := createTestAccount(t, s, "100", "USD")
+
+	// the opening balances are posted as deposits when the accounts are created,
+	// the statement period starts after them
+	from := time.Now()
+
+	transfer := func(from, to, amount string) {
+		t.Helper()
+		if _, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: "transfer",
+			Amount:          decimal.RequireFromString(amount),
+			Currency:        "USD",
+			Status:          "success",
+		}); err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+	}
+
+	transfer(account, other, "30")
+	transfer(other, account, "12.5")
+	transfer(account, other, "0.25")
+
+	to := time.Now().Add(time.Hour)
+	statement, err := s.GetStatement(ctx, account, from, to)
+	if err != nil {
+		t.Fatalf("GetStatement() error = %v", err)
+	}
+
+	want := map[string]string{
+		"opening": "100",
+		"debits":  "30.25",
+		"credits": "12.5",
+		"closing": "82.25",
+	}
+	got := map[string]decimal.Decimal{
+		"opening": statement.OpeningBalance,
+		"debits":  statement.TotalDebits,
+		"credits": statement.TotalCredits,
+		"closing": statement.ClosingBalance,
+	}
+	for name, value := range want {
+		if !got[name].Equal(decimal.RequireFromString(value)) {
+			t.Errorf("GetStatement() %s balance = %s, want %s", name, got[name], value)
+		}
+	}
+
+	if len(statement.Entries) != 3 {
+		t.Fatalf("GetStatement() returned %d entries, want 3", len(statement.Entries))
+	}
+	if statement.Entries[1].CounterpartyAccountID != other {
+		t.Errorf("GetStatement() counterparty = %s, want %s", statement.Entries[1].CounterpartyAccountID, other)
+	}
+
+	// a period after all postings opens and closes at the current balance
+	later, err := s.GetStatement(ctx, account, to, to.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetStatement() error = %v", err)
+	}
+	if !later.OpeningBalance.Equal(statement.ClosingBalance) || len(later.Entries) != 0 {
+		t.Errorf("GetStatement() after the last posting = %+v, want an empty period at %s",
+			later, statement.ClosingBalance)
+	}
+}
+
+func TestStore_GetStatement_ConcurrentTransfers(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "0", "USD")
+	senders := []string{
+		createTestAccount(t, s, "100", "USD"),
+		createTestAccount(t, s, "100", "USD"),
+		createTestAccount(t, s, "100", "USD"),
+	}
+	from := time.Now().Add(-time.Minute)
+
+	// the transfers wait on the lock of the receiving account, so their
+	// transactions start in a different order than they post in
+	var wg sync.WaitGroup
+	for _, sender := range senders {
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.CreateTransaction(ctx, &TransactionRequest{
+					TransactionID:   idx.UUID4(),
+					FromAccountID:   sender,
+					ToAccountID:     account,
+					TransactionType: TransactionTypeTransfer,
+					Amount:          decimal.NewFromInt(1),
+					Currency:        "USD",
+					Status:          TransactionStatusSuccess,
+				})
+				if err != nil {
+					t.Errorf("CreateTransaction() error = %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	statement, err := s.GetStatement(ctx, account, from, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetStatement() error = %v", err)
+	}
+
+	running := statement.OpeningBalance
+	for i, entry := range statement.Entries {
+		running = running.Add(entry.Amount)
+		if !entry.Balance.Equal(running) {
+			t.Fatalf("entry %d running balance = %s, want %s", i, entry.Balance, running)
+		}
+	}
+	if !statement.ClosingBalance.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("closing balance = %s, want 30", statement.ClosingBalance)
+	}
+}
+
+func TestStore_GetStatement_SystemAccount(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	cashIn, err := s.SystemAccountID(ctx, SystemAccountCashIn, "USD")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+
+	_, err = s.GetStatement(ctx, cashIn, time.Now().Add(-time.Hour), time.Now())
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetStatement() of a system account error = %v, want NotFound", err)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
+	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 	"google.golang.org/grpc/codes"
@@ -27,140 +31,237 @@ func NewStore(
 	}
 }
 
+// CreateUserRequest creates a user together with their first account
 type CreateUserRequest struct {
-	ID            string  `json:"id"`
-	Username      string  `json:"username"`
-	Email         string  `json:"email"`
-	Password      string  `json:"password"`
-	AccountID     string  `json:"account_id"`
-	AccountName   string  `json:"account_name"`
-	AccountType   string  `json:"account_type"`
-	AccountNumber string  `json:"account_number"`
-	Balance       float64 `json:"balance"`
-	Currency      string  `json:"currency"`
-	Status        string  `json:"status"`
+	ID            string          `json:"id"`
+	Username      string          `json:"username"`
+	Email         string          `json:"email"`
+	Password      string          `json:"password"`
+	AccountID     string          `json:"account_id"`
+	AccountName   string          `json:"account_name"`
+	AccountType   string          `json:"account_type"`
+	AccountNumber string          `json:"account_number"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Status        string          `json:"status"`
 }
 
+// OpenAccountRequest opens an account for an existing user, Balance is
+// posted as an opening deposit
+type OpenAccountRequest struct {
+	UserID        string          `json:"user_id"`
+	AccountID     string          `json:"account_id"`
+	AccountName   string          `json:"account_name"`
+	AccountType   string          `json:"account_type"`
+	AccountNumber string          `json:"account_number"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Status        string          `json:"status"`
+}
+
+// AccountDetails is an account together with the user who owns it
 type AccountDetails struct {
-	ID            string  `json:"id"`
-	Username      string  `json:"username"`
-	Email         string  `json:"email"`
-	AccountID     string  `json:"account_id"`
-	AccountName   string  `json:"account_name"`
-	AccountType   string  `json:"account_type"`
-	AccountNumber string  `json:"account_number"`
-	Balance       float64 `json:"balance"`
-	Currency      string  `json:"currency"`
-	Status        string  `json:"status"`
+	ID            string          `json:"id"`
+	UserID        string          `json:"user_id"`
+	Username      string          `json:"username"`
+	Email         string          `json:"email"`
+	AccountName   string          `json:"account_name"`
+	AccountType   string          `json:"account_type"`
+	AccountNumber string          `json:"account_number"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Status        string          `json:"status"`
+	// HeldBalance is reserved by authorized holds, AvailableBalance is what
+	// can be spent: the rest of Balance plus OverdraftLimit
+	HeldBalance      decimal.Decimal `json:"held_balance"`
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	// OverdraftLimit is how far below zero the balance may go, OverdrawnSince
+	// is set while it is below zero and MaxOverdraft is the deepest it went
+	OverdraftLimit decimal.Decimal `json:"overdraft_limit"`
+	OverdrawnSince *time.Time      `json:"overdrawn_since,omitempty"`
+	MaxOverdraft   decimal.Decimal `json:"max_overdraft"`
+	// ClosedAt is set once the account is closed
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
 }
 
+// UpdateAccountRequest updates an account, the profile of its owner is
+// changed with UpdateUser and SetPassword
 type UpdateAccountRequest struct {
-	ID          string  `json:"id"`
-	Username    string  `json:"username"`
-	Email       string  `json:"email"`
-	Password    string  `json:"password"`
-	AccountName string  `json:"account_name"`
-	AccountType string  `json:"account_type"`
-	Balance     float64 `json:"balance"`
-	Currency    string  `json:"currency"`
-	Status      string  `json:"status"`
+	ID          string `json:"id"`
+	AccountName string `json:"account_name"`
+	AccountType string `json:"account_type"`
+	// Balance is the new balance, nil leaves it unchanged
+	Balance  *decimal.Decimal `json:"balance,omitempty"`
+	Currency string           `json:"currency"`
+	// StatusChange moves the account to a new status, nil leaves it unchanged
+	StatusChange *AccountStatusChange `json:"status_change,omitempty"`
+}
+
+// accountDetailsColumns are the columns scanned by scanAccountDetails
+var accountDetailsColumns = []string{
+	"a.id", "u.id", "u.username", "u.email",
+	"a.account_name", "a.account_type", "a.account_number",
+	"a.balance", "a.currency", "a.status",
+	"a.held_balance", "a.available_balance",
+	"a.overdraft_limit", "a.overdrawn_since", "a.max_overdraft", "a.closed_at",
+}
+
+// accountDetails selects the customer accounts with their owners, closed
+// accounts included
+func (s *Store) accountDetails() squirrel.SelectBuilder {
+	return s.db.Builder.
+		Select(accountDetailsColumns...).
+		From("dbank_accounts a").
+		Join("dbank_users u ON u.pk = a.user_pk").
+		Where("u.deleted_at IS NULL").
+		Where("a.deleted_at IS NULL").
+		Where("a.system_code IS NULL")
 }
 
+func scanAccountDetails(row pgx.Row) (*AccountDetails, error) {
+	var account AccountDetails
+	err := row.Scan(
+		&account.ID,
+		&account.UserID,
+		&account.Username,
+		&account.Email,
+		&account.AccountName,
+		&account.AccountType,
+		&account.AccountNumber,
+		&account.Balance,
+		&account.Currency,
+		&account.Status,
+		&account.HeldBalance,
+		&account.AvailableBalance,
+		&account.OverdraftLimit,
+		&account.OverdrawnSince,
+		&account.MaxOverdraft,
+		&account.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccount creates a user together with their first account
 func (s *Store) CreateAccount(
 	ctx context.Context,
 	request *CreateUserRequest,
 ) error {
 	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
-		// Insert user data
-		userSQL, userArgs, err := s.db.Builder.
-			Insert("dbank_users").
-			Columns("id", "username", "email", "password").
-			Values(request.ID, request.Username, request.Email, request.Password).
-			Suffix("RETURNING pk").
-			ToSql()
+		userPK, err := s.insertUser(ctx, tx, &User{
+			ID:       request.ID,
+			Username: request.Username,
+			Email:    request.Email,
+			Password: request.Password,
+		})
 		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to build user SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to build SQL query")
+			return err
 		}
 
-		var userPK int
-		err = tx.QueryRow(ctx, userSQL, userArgs...).Scan(&userPK)
-		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to execute user SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to execute SQL query")
-		}
+		return s.insertAccount(ctx, tx, userPK, &OpenAccountRequest{
+			UserID:        request.ID,
+			AccountID:     request.AccountID,
+			AccountName:   request.AccountName,
+			AccountType:   request.AccountType,
+			AccountNumber: request.AccountNumber,
+			Balance:       request.Balance,
+			Currency:      request.Currency,
+			Status:        request.Status,
+		})
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create account", "error", err)
+		return err
+	}
 
-		// Insert account data
-		accountSQL, accountArgs, err := s.db.Builder.
-			Insert("dbank_accounts").
-			Columns("id", "user_pk", "account_type", "account_number", "balance", "currency", "status", "account_name").
-			Values(
-				request.AccountID,
-				userPK,
-				request.AccountType,
-				request.AccountNumber,
-				request.Balance,
-				request.Currency,
-				request.Status,
-				request.AccountName,
-			).
-			ToSql()
-		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to build account SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to build SQL query")
-		}
+	return nil
+}
 
-		_, err = tx.Exec(ctx, accountSQL, accountArgs...)
+// OpenAccount opens another account for an existing user
+func (s *Store) OpenAccount(
+	ctx context.Context,
+	request *OpenAccountRequest,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		userPK, err := s.userPK(ctx, tx, request.UserID)
 		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to execute account SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to execute SQL query")
+			return err
 		}
 
-		return nil
+		return s.insertAccount(ctx, tx, userPK, request)
 	})
 	if err != nil {
-		s.logger.ErrorContext(ctx, "failed to create account", "error", err)
+		s.logger.ErrorContext(ctx, "failed to open account", "error", err, "user_id", request.UserID)
 		return err
 	}
 
+	s.logger.InfoContext(ctx, "account opened", "account_id", request.AccountID, "user_id", request.UserID)
 	return nil
 }
 
+// insertAccount inserts an account of a user and posts its opening balance
+// as a deposit
+func (s *Store) insertAccount(
+	ctx context.Context,
+	tx pgx.Tx,
+	userPK int,
+	request *OpenAccountRequest,
+) error {
+	sql, args, err := s.db.Builder.
+		Insert("dbank_accounts").
+		Columns("id", "user_pk", "account_type", "account_number", "currency", "status", "account_name").
+		Values(
+			request.AccountID,
+			userPK,
+			request.AccountType,
+			request.AccountNumber,
+			request.Currency,
+			request.Status,
+			request.AccountName,
+		).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build account SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to execute account SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to execute SQL query")
+	}
+
+	if !request.Balance.IsPositive() {
+		return nil
+	}
+
+	_, err = s.postSystemTransaction(ctx, tx, &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		ToAccountID:     request.AccountID,
+		TransactionType: TransactionTypeDeposit,
+		Amount:          request.Balance,
+		Currency:        request.Currency,
+		Description:     "Opening balance",
+		Status:          TransactionStatusSuccess,
+	}, SystemAccountCashIn)
+	return err
+}
+
+// GetAccount returns a customer account by its id
 func (s *Store) GetAccount(
 	ctx context.Context,
 	id string,
 ) (*AccountDetails, error) {
-	// Join dbank_users and dbank_accounts to get all required information
-	sql, args, err := s.db.Builder.
-		Select(
-			"u.id", "u.username", "u.email",
-			"a.id as account_id", "a.account_name", "a.account_type",
-			"a.account_number", "a.balance", "a.currency", "a.status",
-		).
-		From("dbank_users u").
-		Join("dbank_accounts a ON a.user_pk = u.pk").
-		Where("u.id = ?", id).
-		Where("u.deleted_at IS NULL").
-		Where("a.deleted_at IS NULL").
+	sql, args, err := s.accountDetails().
+		Where("a.id = ?", id).
 		ToSql()
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
 	}
 
-	var account AccountDetails
-	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(
-		&account.ID,
-		&account.Username,
-		&account.Email,
-		&account.AccountID,
-		&account.AccountName,
-		&account.AccountType,
-		&account.AccountNumber,
-		&account.Balance,
-		&account.Currency,
-		&account.Status,
-	)
+	account, err := scanAccountDetails(s.db.Pool.QueryRow(ctx, sql, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.logger.ErrorContext(ctx, "account not found", "id", id)
@@ -170,7 +271,7 @@ func (s *Store) GetAccount(
 		return nil, status.Errorf(codes.Internal, "failed to query account")
 	}
 
-	return &account, nil
+	return account, nil
 }
 
 // GetAllAccounts retrieves all accounts
@@ -179,50 +280,60 @@ func (s *Store) GetAllAccounts(
 	page uint64,
 	pageSize uint64,
 ) ([]*AccountDetails, error) {
-	sql, args, err := s.db.Builder.
-		Select(
-			"u.id", "u.username", "u.email",
-			"a.id as account_id", "a.account_name", "a.account_type",
-			"a.account_number", "a.balance", "a.currency", "a.status",
-		).
-		From("dbank_users u").
-		Join("dbank_accounts a ON a.user_pk = u.pk").
-		Where("u.deleted_at IS NULL").
-		Where("a.deleted_at IS NULL").
-		OrderBy("u.created_at DESC").
+	accounts, err := s.listAccounts(ctx, s.accountDetails().
+		OrderBy("a.created_at DESC").
 		Limit(pageSize).
-		Offset((page - 1) * pageSize).
-		ToSql()
+		Offset((page-1)*pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		s.logger.ErrorContext(ctx, "no accounts found")
+		return nil, status.Errorf(codes.NotFound, "no accounts found")
+	}
+
+	s.logger.InfoContext(ctx, "accounts retrieved", "count", len(accounts))
+	return accounts, nil
+}
+
+// ListUserAccounts returns the accounts of a user in the order they were
+// opened, closed accounts included
+func (s *Store) ListUserAccounts(
+	ctx context.Context,
+	userID string,
+) ([]*AccountDetails, error) {
+	return s.listAccounts(ctx, s.accountDetails().
+		Where("u.id = ?", userID).
+		OrderBy("a.created_at", "a.pk"))
+}
+
+// listAccounts runs a query built on accountDetails
+func (s *Store) listAccounts(
+	ctx context.Context,
+	query squirrel.SelectBuilder,
+) ([]*AccountDetails, error) {
+	sql, args, err := query.ToSql()
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
 	}
+
 	rows, err := s.db.Pool.Query(ctx, sql, args...)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to query accounts", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to query accounts")
 	}
 	defer rows.Close()
+
 	var accounts []*AccountDetails
 	for rows.Next() {
-		var account AccountDetails
-		err = rows.Scan(
-			&account.ID,
-			&account.Username,
-			&account.Email,
-			&account.AccountID,
-			&account.AccountName,
-			&account.AccountType,
-			&account.AccountNumber,
-			&account.Balance,
-			&account.Currency,
-			&account.Status,
-		)
+		account, err := scanAccountDetails(rows)
 		if err != nil {
 			s.logger.ErrorContext(ctx, "failed to scan account", "error", err)
 			return nil, status.Errorf(codes.Internal, "failed to scan account")
 		}
-		accounts = append(accounts, &account)
+		accounts = append(accounts, account)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -230,15 +341,10 @@ func (s *Store) GetAllAccounts(
 		return nil, status.Errorf(codes.Internal, "failed to iterate accounts")
 	}
 
-	if len(accounts) == 0 {
-		s.logger.ErrorContext(ctx, "no accounts found")
-		return nil, status.Errorf(codes.NotFound, "no accounts found")
-	}
-
-	s.logger.InfoContext(ctx, "accounts retrieved", "count", len(accounts))
 	return accounts, nil
 }
 
+// UpdateAccount updates an account
 func (s *Store) UpdateAccount(
 	ctx context.Context,
 	request *UpdateAccountRequest,
@@ -246,70 +352,43 @@ func (s *Store) UpdateAccount(
 	var updatedAccount *AccountDetails
 
 	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
-		// First get user pk from id
-		userPkSQL, userPkArgs, err := s.db.Builder.
-			Select("pk").
-			From("dbank_users").
-			Where("id = ?", request.ID).
-			Where("deleted_at IS NULL").
-			ToSql()
-		if err != nil {
-			return status.Errorf(codes.Internal, "failed to build SQL query")
-		}
-
-		var userPk int
-		err = tx.QueryRow(ctx, userPkSQL, userPkArgs...).Scan(&userPk)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return status.Errorf(codes.NotFound, "user not found")
+		// Lock the account, so that no posting lands between the currency
+		// check and the update. A new balance is posted against the
+		// suspense account, which is locked together with the account in
+		// the same order as transfers and closures lock them.
+		ids := []string{request.ID}
+		if request.Balance != nil {
+			suspenseID, err := s.systemAccountID(ctx, tx, SystemAccountSuspense, request.Currency)
+			if err != nil {
+				return err
 			}
-			return status.Errorf(codes.Internal, "failed to get user pk")
+			ids = append(ids, suspenseID)
 		}
-
-		// Update user information
-		userSQL, userArgs, err := s.db.Builder.
-			Update("dbank_users").
-			Set("username", request.Username).
-			Set("email", request.Email).
-			Set("updated_at", "now()").
-			Where("pk = ?", userPk).
-			ToSql()
+		accounts, err := s.lockAccounts(ctx, tx, ids...)
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to build user update SQL")
+			return err
 		}
-
-		_, err = tx.Exec(ctx, userSQL, userArgs...)
-		if err != nil {
-			return status.Errorf(codes.Internal, "failed to update user")
+		account := accounts[request.ID]
+		if account.SystemCode != "" {
+			return status.Errorf(codes.NotFound, "account not found")
 		}
+		accountPk := account.PK
 
-		// If password provided, update it
-		if request.Password != "" {
-			passSQL, passArgs, err := s.db.Builder.
-				Update("dbank_users").
-				Set("password", request.Password).
-				Where("pk = ?", userPk).
-				ToSql()
-			if err != nil {
-				return status.Errorf(codes.Internal, "failed to build password update SQL")
-			}
-
-			_, err = tx.Exec(ctx, passSQL, passArgs...)
-			if err != nil {
-				return status.Errorf(codes.Internal, "failed to update password")
+		if request.Currency != account.Currency {
+			if err = s.checkCurrencyChange(ctx, tx, account); err != nil {
+				return err
 			}
 		}
 
-		// Update account information
+		// Update account information, before the balance is adjusted in
+		// the currency of the account
 		accountSQL, accountArgs, err := s.db.Builder.
 			Update("dbank_accounts").
 			Set("account_name", request.AccountName).
 			Set("account_type", request.AccountType).
-			Set("balance", request.Balance).
 			Set("currency", request.Currency).
-			Set("status", request.Status).
 			Set("updated_at", "now()").
-			Where("user_pk = ?", userPk).
+			Where("pk = ?", accountPk).
 			ToSql()
 		if err != nil {
 			return status.Errorf(codes.Internal, "failed to build account update SQL")
@@ -320,34 +399,28 @@ func (s *Store) UpdateAccount(
 			return status.Errorf(codes.Internal, "failed to update account")
 		}
 
+		// A new balance is posted as an adjustment against the suspense account
+		if request.Balance != nil {
+			if err = s.adjustAccountBalance(ctx, tx, request.ID, *request.Balance); err != nil {
+				return err
+			}
+		}
+
+		if request.StatusChange != nil {
+			if err = s.changeAccountStatus(ctx, tx, request.StatusChange, nil); err != nil {
+				return err
+			}
+		}
+
 		// Get updated account details
-		accountDetailsSQL, accountDetailsArgs, err := s.db.Builder.
-			Select(
-				"u.id", "u.username", "u.email",
-				"a.id as account_id", "a.account_name", "a.account_type",
-				"a.account_number", "a.balance", "a.currency", "a.status",
-			).
-			From("dbank_users u").
-			Join("dbank_accounts a ON a.user_pk = u.pk").
-			Where("u.pk = ?", userPk).
+		accountDetailsSQL, accountDetailsArgs, err := s.accountDetails().
+			Where("a.pk = ?", accountPk).
 			ToSql()
 		if err != nil {
 			return status.Errorf(codes.Internal, "failed to build details query")
 		}
 
-		updatedAccount = &AccountDetails{}
-		err = tx.QueryRow(ctx, accountDetailsSQL, accountDetailsArgs...).Scan(
-			&updatedAccount.ID,
-			&updatedAccount.Username,
-			&updatedAccount.Email,
-			&updatedAccount.AccountID,
-			&updatedAccount.AccountName,
-			&updatedAccount.AccountType,
-			&updatedAccount.AccountNumber,
-			&updatedAccount.Balance,
-			&updatedAccount.Currency,
-			&updatedAccount.Status,
-		)
+		updatedAccount, err = scanAccountDetails(tx.QueryRow(ctx, accountDetailsSQL, accountDetailsArgs...))
 		if err != nil {
 			return status.Errorf(codes.Internal, "failed to get updated account details")
 		}
@@ -362,165 +435,441 @@ func (s *Store) UpdateAccount(
 	return updatedAccount, nil
 }
 
-func (s *Store) DeleteAccount(
+// checkCurrencyChange refuses to change the currency of a locked account
+// with postings or a balance, they stay in the currency they were made in
+func (s *Store) checkCurrencyChange(
 	ctx context.Context,
-	id string,
+	tx pgx.Tx,
+	account *lockedAccount,
 ) error {
-	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
-		// Get user pk
-		userPkSQL, userPkArgs, err := s.db.Builder.
-			Select("pk").
-			From("dbank_users").
+	if !account.Balance.IsZero() || !account.HeldBalance.IsZero() {
+		return status.Errorf(codes.FailedPrecondition, "cannot change the currency of an account with a balance")
+	}
+
+	sql, args, err := s.db.Builder.
+		Select("pk").
+		From("dbank_ledgers").
+		Where("account_pk = ?", account.PK).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var postingPK int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&postingPK)
+	if err == nil {
+		return status.Errorf(codes.FailedPrecondition, "cannot change the currency of an account with postings")
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "failed to query ledger postings", "error", err)
+		return status.Errorf(codes.Internal, "failed to query ledger postings")
+	}
+
+	return nil
+}
+
+const (
+	TransactionStatusSuccess  = "success"
+	TransactionStatusFailed   = "failed"
+	TransactionStatusReversed = "reversed"
+	// TransactionStatusPartiallyReversed transactions had part of their
+	// amount moved back by reversals
+	TransactionStatusPartiallyReversed = "partially_reversed"
+	// TransactionStatusPendingReview transactions were held by a risk rule,
+	// they move no money until an operator approves them
+	TransactionStatusPendingReview = "pending_review"
+	// TransactionStatusRejected transactions were rejected on review
+	TransactionStatusRejected = "rejected"
+)
+
+// outgoingStatuses are the statuses of the transactions that count towards
+// the transfer limits and the history of their sender
+var outgoingStatuses = []string{
+	TransactionStatusSuccess,
+	TransactionStatusReversed,
+	TransactionStatusPartiallyReversed,
+	TransactionStatusPendingReview,
+}
+
+type TransactionRequest struct {
+	TransactionID   string          `json:"transaction_id"`
+	FromAccountID   string          `json:"from_account_id"`
+	ToAccountID     string          `json:"to_account_id"`
+	TransactionType string          `json:"transaction_type"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+	Description     string          `json:"description"`
+	Status          string          `json:"status"`
+	IdempotencyKey  *IdempotencyKey `json:"idempotency_key,omitempty"`
+
+	// AllowSystemAccounts lets deposits, withdrawals and adjustments post
+	// against the internal system accounts, which customer transfers cannot
+	AllowSystemAccounts bool `json:"-"`
+
+	// Conversion is the quote from PrepareConversion for a transfer between
+	// accounts in different currencies, nil when both hold the same currency
+	Conversion *Conversion `json:"conversion,omitempty"`
+
+	// HoldID is the authorized hold the transaction captures, its
+	// reservation is released in the same database transaction
+	HoldID string `json:"hold_id,omitempty"`
+
+	// Fee is the fee from PrepareFee, charged to the sender on top of the
+	// amount and posted to the fee income account, nil when there is none
+	Fee *Fee `json:"fee,omitempty"`
+}
+
+// lockedAccount is an account row held with SELECT ... FOR UPDATE
+// for the lifetime of the surrounding database transaction
+type lockedAccount struct {
+	PK             int
+	ID             string
+	Balance        decimal.Decimal
+	HeldBalance    decimal.Decimal
+	OverdraftLimit decimal.Decimal
+	Currency       string
+	Status         string
+	SystemCode     string
+}
+
+// available returns what can be spent: the balance that is not reserved
+// by holds plus the arranged overdraft
+func (a *lockedAccount) available() decimal.Decimal {
+	return a.Balance.Sub(a.HeldBalance).Add(a.OverdraftLimit)
+}
+
+// lockAccounts locks the given accounts one by one in ascending id order.
+// Taking the row locks in a fixed order keeps two transfers between the
+// same pair of accounts from deadlocking each other.
+func (s *Store) lockAccounts(
+	ctx context.Context,
+	tx pgx.Tx,
+	ids ...string,
+) (map[string]*lockedAccount, error) {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	accounts := make(map[string]*lockedAccount, len(sorted))
+	for _, id := range sorted {
+		sql, args, err := s.db.Builder.
+			Select(
+				"pk", "id", "balance", "held_balance", "overdraft_limit",
+				"currency", "status", "COALESCE(system_code, '')",
+			).
+			From("dbank_accounts").
 			Where("id = ?", id).
 			Where("deleted_at IS NULL").
+			Suffix("FOR UPDATE").
 			ToSql()
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to build SQL query")
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to build SQL query")
 		}
 
-		var userPk int
-		err = tx.QueryRow(ctx, userPkSQL, userPkArgs...).Scan(&userPk)
+		var account lockedAccount
+		err = tx.QueryRow(ctx, sql, args...).Scan(
+			&account.PK,
+			&account.ID,
+			&account.Balance,
+			&account.HeldBalance,
+			&account.OverdraftLimit,
+			&account.Currency,
+			&account.Status,
+			&account.SystemCode,
+		)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return status.Errorf(codes.NotFound, "user not found")
+				s.logger.ErrorContext(ctx, "account not found", "id", id)
+				return nil, status.Errorf(codes.NotFound, "account %s not found", id)
 			}
-			return status.Errorf(codes.Internal, "failed to get user pk")
+			s.logger.ErrorContext(ctx, "failed to lock account", "error", err, "id", id)
+			return nil, status.Errorf(codes.Internal, "failed to lock account")
 		}
 
-		// Soft delete account
-		accountSQL, accountArgs, err := s.db.Builder.
-			Update("dbank_accounts").
-			Set("deleted_at", "now()").
-			Where("user_pk = ?", userPk).
-			ToSql()
-		if err != nil {
-			return status.Errorf(codes.Internal, "failed to build account delete SQL")
+		accounts[id] = &account
+	}
+
+	return accounts, nil
+}
+
+// adjustBalance applies a relative change to an account balance that was
+// previously locked by lockAccounts. Customer accounts also track how long
+// and how deep they are overdrawn.
+func (s *Store) adjustBalance(
+	ctx context.Context,
+	tx pgx.Tx,
+	account *lockedAccount,
+	delta decimal.Decimal,
+) error {
+	builder := s.db.Builder.
+		Update("dbank_accounts").
+		Set("balance", squirrel.Expr("balance + ?", delta)).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("pk = ?", account.PK)
+	if account.SystemCode == "" {
+		newBalance := account.Balance.Add(delta)
+		if newBalance.IsNegative() {
+			builder = builder.
+				Set("overdrawn_since", squirrel.Expr("COALESCE(overdrawn_since, now())")).
+				Set("max_overdraft", squirrel.Expr("GREATEST(max_overdraft, ?)", newBalance.Neg()))
+		} else {
+			builder = builder.Set("overdrawn_since", nil)
 		}
+	}
 
-		_, err = tx.Exec(ctx, accountSQL, accountArgs...)
-		if err != nil {
-			return status.Errorf(codes.Internal, "failed to delete account")
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update account balance", "error", err, "id", account.ID)
+		return status.Errorf(codes.Internal, "failed to update account balance")
+	}
+
+	account.Balance = account.Balance.Add(delta)
+	return nil
+}
+
+// CreateTransaction moves money between two accounts and records the transaction
+// together with its debit and credit ledger postings.
+// Both account rows are locked for the whole transfer, so the balance check
+// and the relative balance updates cannot interleave with a concurrent transfer.
+func (s *Store) CreateTransaction(
+	ctx context.Context,
+	request *TransactionRequest,
+) ([]*LedgerEntry, error) {
+	var entries []*LedgerEntry
+	if err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		// Claim the idempotency key first, a concurrent request with the
+		// same key waits here and then fails instead of moving money twice
+		if request.IdempotencyKey != nil {
+			if err := s.saveIdempotencyKey(ctx, tx, request.IdempotencyKey); err != nil {
+				return err
+			}
 		}
 
-		// Soft delete user
-		userSQL, userArgs, err := s.db.Builder.
-			Update("dbank_users").
-			Set("deleted_at", "now()").
-			Where("pk = ?", userPk).
-			ToSql()
+		accounts, err := s.lockAccounts(ctx, tx, request.FromAccountID, request.ToAccountID)
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to build user delete SQL")
+			return err
+		}
+
+		entries, err = s.transfer(ctx, tx, request, accounts[request.FromAccountID], accounts[request.ToAccountID])
+		return err
+	}); err != nil {
+		request.Status = TransactionStatusFailed
+		request.Description = "Transaction failed"
+		s.logger.ErrorContext(ctx, "failed to create transaction", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// CreateTransactions moves the money of several transactions in one database
+// transaction, so that either all of them are recorded or none is. Every
+// account is locked up front in ascending id order, and the transactions are
+// checked and posted in the order given, each one seeing the balances the
+// ones before it left. The transaction that failed is marked failed.
+func (s *Store) CreateTransactions(
+	ctx context.Context,
+	requests []*TransactionRequest,
+) ([][]*LedgerEntry, error) {
+	entries := make([][]*LedgerEntry, len(requests))
+	if err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		ids := make([]string, 0, 2*len(requests))
+		for _, request := range requests {
+			if request.IdempotencyKey != nil {
+				if err := s.saveIdempotencyKey(ctx, tx, request.IdempotencyKey); err != nil {
+					request.Status = TransactionStatusFailed
+					return err
+				}
+			}
+			ids = append(ids, request.FromAccountID, request.ToAccountID)
 		}
 
-		_, err = tx.Exec(ctx, userSQL, userArgs...)
+		accounts, err := s.lockAccounts(ctx, tx, ids...)
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to delete user")
+			return err
+		}
+
+		for i, request := range requests {
+			entries[i], err = s.transfer(ctx, tx, request, accounts[request.FromAccountID], accounts[request.ToAccountID])
+			if err != nil {
+				request.Status = TransactionStatusFailed
+				return err
+			}
 		}
 
 		return nil
-	})
-	if err != nil {
-		s.logger.ErrorContext(ctx, "failed to delete account", "error", err)
-		return err
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "failed to create transactions", "error", err, "count", len(requests))
+		return nil, err
 	}
 
-	return nil
+	return entries, nil
 }
 
-type TransactionRequest struct {
-	TransactionID      string          `json:"transaction_id"`
-	FromAccountID      string          `json:"from_account_id"`
-	FromAccountBalance decimal.Decimal `json:"from_account_balance"`
-	ToAccountID        string          `json:"to_account_id"`
-	ToAccountBalance   decimal.Decimal `json:"to_account_balance"`
-	TransactionType    string          `json:"transaction_type"`
-	Amount             decimal.Decimal `json:"amount"`
-	Currency           string          `json:"currency"`
-	Description        string          `json:"description"`
-	Status             string          `json:"status"`
+// transfer checks a transaction against its two locked accounts and records it
+func (s *Store) transfer(
+	ctx context.Context,
+	tx pgx.Tx,
+	request *TransactionRequest,
+	fromAccount, toAccount *lockedAccount,
+) ([]*LedgerEntry, error) {
+	if !request.AllowSystemAccounts && (fromAccount.SystemCode != "" || toAccount.SystemCode != "") {
+		return nil, status.Errorf(codes.PermissionDenied, "internal accounts cannot take part in transfers")
+	}
+
+	if err := checkAccountStatuses(fromAccount, toAccount); err != nil {
+		return nil, err
+	}
+
+	// money reserved by holds cannot be spent, unless it is the hold
+	// this transaction captures
+	if _, err := s.releaseExpiredHolds(ctx, tx, fromAccount); err != nil {
+		return nil, err
+	}
+	if request.HoldID != "" {
+		if err := s.captureHold(ctx, tx, request, fromAccount, toAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	// internal accounts are the other side of money entering or leaving
+	// the bank, so they go negative and are not checked
+	if fromAccount.SystemCode == "" && fromAccount.available().LessThan(request.Amount.Add(request.feeAmount())) {
+		return nil, insufficientFundsError()
+	}
+
+	entries, err := s.recordTransaction(ctx, tx, request, fromAccount, toAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "transaction created", "transaction_id", request.TransactionID)
+	return entries, nil
 }
 
-// CreateTransaction creates a new transaction
-func (s *Store) CreateTransaction(
+// recordTransaction inserts a transaction and posts its debit and credit
+// entries on the two locked accounts, converting the amount when they hold
+// different currencies. A transaction pending review is stored for the
+// review without postings.
+func (s *Store) recordTransaction(
 	ctx context.Context,
+	tx pgx.Tx,
 	request *TransactionRequest,
-) error {
-	if err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
-		// deduct amount from sender's account
-		// add amount to receiver's account
-		// create transaction record
+	fromAccount, toAccount *lockedAccount,
+) ([]*LedgerEntry, error) {
+	if err := checkConversion(request, fromAccount, toAccount); err != nil {
+		return nil, err
+	}
 
-		sql, args, err := s.db.Builder.
-			Update("dbank_accounts").
-			Set("balance", request.FromAccountBalance).
-			Where("id = ?", request.FromAccountID).
-			ToSql()
-		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to build SQL query")
-		}
-		_, err = tx.Exec(ctx, sql, args...)
-		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to execute SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to execute SQL query")
-		}
+	toAmount, toCurrency := request.Amount, request.Currency
+	var fxRateID, fxRate, fxSpread, fxSnapshotID, feeScheduleID any
+	if conversion := request.Conversion; conversion != nil {
+		toAmount, toCurrency = conversion.ConvertedAmount, conversion.Rate.QuoteCurrency
+		fxRateID, fxRate, fxSpread = conversion.Rate.ID, conversion.Rate.Rate, conversion.Rate.Spread
+		fxSnapshotID = nullString(conversion.Rate.SnapshotID)
+	}
+	if request.Fee != nil {
+		feeScheduleID = request.Fee.ScheduleID
+	}
 
-		sql, args, err = s.db.Builder.
-			Update("dbank_accounts").
-			Set("balance", request.ToAccountBalance).
-			Where("id = ?", request.ToAccountID).
-			ToSql()
+	sql, args, err := s.db.Builder.
+		Insert("dbank_transactions").
+		Columns(
+			"id", "from_account_id", "to_account_id", "transaction_type",
+			"amount", "currency", "description", "status",
+			"to_amount", "to_currency", "fx_rate_id", "fx_rate", "fx_spread", "fx_snapshot_id",
+			"fee_amount", "fee_schedule_id",
+		).
+		Values(
+			request.TransactionID,
+			request.FromAccountID,
+			request.ToAccountID,
+			request.TransactionType,
+			request.Amount,
+			request.Currency,
+			request.Description,
+			request.Status,
+			toAmount,
+			toCurrency,
+			fxRateID,
+			fxRate,
+			fxSpread,
+			fxSnapshotID,
+			request.feeAmount(),
+			feeScheduleID,
+		).
+		Suffix("RETURNING pk").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build transaction SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var transactionPK int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&transactionPK); err != nil {
+		s.logger.ErrorContext(ctx, "failed to execute transaction SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to execute SQL query")
+	}
+
+	if request.Status == TransactionStatusPendingReview {
+		return nil, s.insertReview(ctx, tx, transactionPK, request)
+	}
+
+	return s.postTransaction(ctx, tx, request, transactionPK, fromAccount, toAccount)
+}
+
+// postTransaction posts the entries of a recorded transaction on the two
+// locked accounts and the fee on the sender
+func (s *Store) postTransaction(
+	ctx context.Context,
+	tx pgx.Tx,
+	request *TransactionRequest,
+	transactionPK int,
+	fromAccount, toAccount *lockedAccount,
+) ([]*LedgerEntry, error) {
+	var entries []*LedgerEntry
+	var err error
+	if request.Conversion != nil {
+		entries, err = s.postConversion(ctx, tx, request, transactionPK, fromAccount, toAccount)
 		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to build SQL query")
+			return nil, err
 		}
-		_, err = tx.Exec(ctx, sql, args...)
+	} else {
+		// debit sender's account
+		debit, err := s.postEntry(ctx, tx, fromAccount, transactionPK, request.TransactionID,
+			request.Amount.Neg(), request.Currency)
 		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to execute SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to execute SQL query")
+			return nil, err
 		}
 
-		// Create transaction record
-		// Insert transaction data
-		sql, args, err = s.db.Builder.
-			Insert("dbank_transactions").
-			Columns("from_account_id", "to_account_id", "transaction_type", "amount", "currency", "description", "status").
-			Values(
-				request.FromAccountID,
-				request.ToAccountID,
-				request.TransactionType,
-				request.Amount,
-				request.Currency,
-				request.Description,
-				request.Status,
-			).
-			Suffix("RETURNING id").
-			ToSql()
+		// credit receiver's account
+		credit, err := s.postEntry(ctx, tx, toAccount, transactionPK, request.TransactionID,
+			request.Amount, request.Currency)
 		if err != nil {
-			s.logger.ErrorContext(ctx, "failed to build transaction SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to build SQL query")
+			return nil, err
 		}
 
-		var transactionID int
-		err = tx.QueryRow(ctx, sql, args...).Scan(&transactionID)
+		entries = []*LedgerEntry{debit, credit}
+	}
+
+	// the fee is posted as its own entries of the same transaction
+	if request.Fee != nil {
+		fee, err := s.postFee(ctx, tx, transactionPK, request.TransactionID, request.Fee.Amount, fromAccount)
 		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				s.logger.ErrorContext(ctx, "transaction not found", "id", transactionID)
-				return status.Errorf(codes.NotFound, "transaction not found")
-			}
-			s.logger.ErrorContext(ctx, "failed to execute transaction SQL query", "error", err)
-			return status.Errorf(codes.Internal, "failed to execute SQL query")
+			return nil, err
 		}
-		s.logger.InfoContext(ctx, "transaction created", "transaction_id", transactionID)
-		return nil
-	}); err != nil {
-		request.Status = "failed"
-		request.Description = "Transaction failed"
-		s.logger.ErrorContext(ctx, "failed to create transaction", "error", err)
-		return err
+		entries = append(entries, fee...)
 	}
 
-	return nil
+	return entries, nil
 }
 
 type Transaction struct {
@@ -534,8 +883,21 @@ type Transaction struct {
 	Currency           string          `json:"currency"`
 	Description        string          `json:"description"`
 	Status             string          `json:"status"`
-	CreatedAt          string          `json:"created_at"`
-	UpdatedAt          string          `json:"updated_at"`
+	ReversalOf         string          `json:"reversal_of,omitempty"`
+	// ToAmount and ToCurrency are what the receiver was credited. They differ
+	// from Amount and Currency when the transfer was converted at FXRate,
+	// taken from the rate provider refresh FXSnapshotID.
+	ToAmount     decimal.Decimal `json:"to_amount"`
+	ToCurrency   string          `json:"to_currency"`
+	FXRateID     string          `json:"fx_rate_id,omitempty"`
+	FXRate       decimal.Decimal `json:"fx_rate"`
+	FXSpread     decimal.Decimal `json:"fx_spread"`
+	FXSnapshotID string          `json:"fx_snapshot_id,omitempty"`
+	// FeeAmount is what the sender was charged on top of Amount, in Currency
+	FeeAmount decimal.Decimal `json:"fee_amount"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (s *Store) GetTransaction(
@@ -546,7 +908,10 @@ func (s *Store) GetTransaction(
 		Select(
 			"t.id", "t.from_account_id", "t.to_account_id",
 			"t.transaction_type", "t.amount", "t.currency",
-			"t.description", "t.status", "t.created_at", "t.updated_at",
+			"t.description", "t.status", "COALESCE(t.reversal_of::text, '')",
+			"t.to_amount", "t.to_currency", "COALESCE(t.fx_rate_id::text, '')",
+			"COALESCE(t.fx_rate, 0)", "COALESCE(t.fx_spread, 0)", "COALESCE(t.fx_snapshot_id::text, '')",
+			"t.fee_amount", "t.created_at", "t.updated_at",
 		).
 		From("dbank_transactions t").
 		Where("t.id = ?", id).
@@ -566,6 +931,14 @@ func (s *Store) GetTransaction(
 		&transaction.Currency,
 		&transaction.Description,
 		&transaction.Status,
+		&transaction.ReversalOf,
+		&transaction.ToAmount,
+		&transaction.ToCurrency,
+		&transaction.FXRateID,
+		&transaction.FXRate,
+		&transaction.FXSpread,
+		&transaction.FXSnapshotID,
+		&transaction.FeeAmount,
 		&transaction.CreatedAt,
 		&transaction.UpdatedAt,
 	)
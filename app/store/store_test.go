@@ -0,0 +1,339 @@
+package store
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/db"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// newTestStore connects to the Postgres database in DB_URL and migrates it up.
+// Tests that need a database are skipped when DB_URL is not set.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		t.Skip("DB_URL is not set")
+	}
+
+	ctx := context.Background()
+	if err := db.MigrateUp(ctx, dbURL); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	pg, err := dbx.NewPostgres(dbURL, dbx.MaxPoolSize(20))
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(pg.Close)
+
+	return NewStore(pg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// createTestAccount creates a user with a single account and returns the account id
+func createTestAccount(t *testing.T, s *Store, balance, currency string) string {
+	t.Helper()
+
+	id := idx.UUID4()
+	accountID := idx.UUID4()
+	err := s.CreateAccount(context.Background(), &CreateUserRequest{
+		ID:            id,
+		Username:      "test-" + id,
+		Email:         id + "@example.com",
+		Password:      "secret",
+		AccountID:     accountID,
+		AccountName:   "test",
+		AccountType:   "checking",
+		AccountNumber: accountID[:8],
+		Balance:       decimal.RequireFromString(balance),
+		Currency:      currency,
+		Status:        "active",
+	})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	return accountID
+}
+
+// accountBalance reads the current balance of an account straight from the table
+func accountBalance(t *testing.T, s *Store, accountID string) decimal.Decimal {
+	t.Helper()
+
+	var balance decimal.Decimal
+	err := s.db.Pool.QueryRow(context.Background(),
+		"SELECT balance FROM dbank_accounts WHERE id = $1", accountID,
+	).Scan(&balance)
+	if err != nil {
+		t.Fatalf("failed to read balance: %v", err)
+	}
+
+	return balance
+}
+
+func TestStore_AccountBalancePrecision(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// neither balance survives a round trip through float64
+	opening := decimal.RequireFromString("12345678901234.123457")
+	adjusted := decimal.RequireFromString("90071992547409.930001")
+
+	id := idx.UUID4()
+	accountID := idx.UUID4()
+	err := s.CreateAccount(ctx, &CreateUserRequest{
+		ID:            id,
+		Username:      "test-" + id,
+		Email:         id + "@example.com",
+		Password:      "secret",
+		AccountID:     accountID,
+		AccountName:   "test",
+		AccountType:   "checking",
+		AccountNumber: accountID[:8],
+		Balance:       opening,
+		Currency:      "USD",
+		Status:        "active",
+	})
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+
+	account, err := s.GetAccount(ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if !account.Balance.Equal(opening) || !account.AvailableBalance.Equal(opening) {
+		t.Errorf("GetAccount() balance = %s, available = %s, want %s",
+			account.Balance, account.AvailableBalance, opening)
+	}
+
+	updated, err := s.UpdateAccount(ctx, &UpdateAccountRequest{
+		ID:          accountID,
+		AccountName: account.AccountName,
+		AccountType: account.AccountType,
+		Balance:     &adjusted,
+		Currency:    account.Currency,
+	})
+	if err != nil {
+		t.Fatalf("UpdateAccount() error = %v", err)
+	}
+	if !updated.Balance.Equal(adjusted) {
+		t.Errorf("UpdateAccount() balance = %s, want %s", updated.Balance, adjusted)
+	}
+	if got := accountBalance(t, s, accountID); !got.Equal(adjusted) {
+		t.Errorf("stored balance = %s, want %s", got, adjusted)
+	}
+}
+
+func TestStore_UpdateAccount_Currency(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	accountID := createTestAccount(t, s, "0", "USD")
+	update := func(currency string) error {
+		_, err := s.UpdateAccount(ctx, &UpdateAccountRequest{
+			ID:          accountID,
+			AccountName: "test",
+			AccountType: "checking",
+			Currency:    currency,
+		})
+		return err
+	}
+
+	// an account without postings can still change its currency
+	if err := update("EUR"); err != nil {
+		t.Fatalf("UpdateAccount() of a new account error = %v", err)
+	}
+
+	from := createTestAccount(t, s, "10", "EUR")
+	transfer := func(from, to string) {
+		_, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: "transfer",
+			Amount:          decimal.NewFromInt(10),
+			Currency:        "EUR",
+			Status:          TransactionStatusSuccess,
+		})
+		if err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+	}
+
+	transfer(from, accountID)
+	if err := update("USD"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpdateAccount() of an account with a balance error = %v, want FailedPrecondition", err)
+	}
+
+	transfer(accountID, from)
+	if err := update("USD"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpdateAccount() of an account with postings error = %v, want FailedPrecondition", err)
+	}
+	if err := update("EUR"); err != nil {
+		t.Errorf("UpdateAccount() keeping the currency error = %v", err)
+	}
+}
+
+func TestStore_CreateTransaction_Concurrent(t *testing.T) {
+	s := newTestStore(t)
+
+	accounts := []string{
+		createTestAccount(t, s, "100", "USD"),
+		createTestAccount(t, s, "100", "USD"),
+		createTestAccount(t, s, "100", "USD"),
+	}
+	total := decimal.NewFromInt(300)
+
+	const transfers = 200
+	var succeeded atomic.Int32
+	var wg sync.WaitGroup
+	for range transfers {
+		from := rand.IntN(len(accounts))
+		to := (from + 1 + rand.IntN(len(accounts)-1)) % len(accounts)
+		amount := decimal.NewFromInt(int64(1 + rand.IntN(25)))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// insufficient balance is an expected outcome under contention
+			_, err := s.CreateTransaction(context.Background(), &TransactionRequest{
+				TransactionID:   idx.UUID4(),
+				FromAccountID:   accounts[from],
+				ToAccountID:     accounts[to],
+				TransactionType: "transfer",
+				Amount:          amount,
+				Currency:        "USD",
+				Status:          "success",
+			})
+			if err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded.Load() == 0 {
+		t.Fatal("no transfer succeeded")
+	}
+
+	sum := decimal.Zero
+	for _, id := range accounts {
+		balance := accountBalance(t, s, id)
+		if balance.IsNegative() {
+			t.Errorf("account %s has a negative balance: %s", id, balance)
+		}
+		sum = sum.Add(balance)
+	}
+
+	if !sum.Equal(total) {
+		t.Errorf("money was created or destroyed: got total %s, want %s", sum, total)
+	}
+}
+
+func TestStore_CreateTransaction_PersistsID(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "50", "USD")
+	to := createTestAccount(t, s, "0", "USD")
+
+	request := &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		FromAccountID:   from,
+		ToAccountID:     to,
+		TransactionType: "transfer",
+		Amount:          decimal.RequireFromString("12.5"),
+		Currency:        "USD",
+		Status:          "success",
+	}
+	entries, err := s.CreateTransaction(ctx, request)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+
+	if len(entries) != 2 ||
+		!entries[0].Amount.Add(entries[1].Amount).IsZero() ||
+		!entries[0].Balance.Equal(decimal.RequireFromString("37.5")) ||
+		!entries[1].Balance.Equal(decimal.RequireFromString("12.5")) {
+		t.Errorf("CreateTransaction() postings = %+v, want a balanced debit and credit", entries)
+	}
+
+	transaction, err := s.GetTransaction(ctx, request.TransactionID)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+
+	if transaction.TransactionID != request.TransactionID ||
+		transaction.FromAccountID != from ||
+		transaction.ToAccountID != to ||
+		transaction.Status != "success" ||
+		!transaction.Amount.Equal(request.Amount) {
+		t.Errorf("GetTransaction() = %+v, want the stored transfer %+v", transaction, request)
+	}
+}
+
+func TestStore_CreateTransactions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	from := createTestAccount(t, s, "50", "USD")
+	to := []string{createTestAccount(t, s, "0", "USD"), createTestAccount(t, s, "0", "USD")}
+
+	transfers := func(amounts ...string) []*TransactionRequest {
+		requests := make([]*TransactionRequest, 0, len(amounts))
+		for i, amount := range amounts {
+			requests = append(requests, &TransactionRequest{
+				TransactionID:   idx.UUID4(),
+				FromAccountID:   from,
+				ToAccountID:     to[i],
+				TransactionType: "transfer",
+				Amount:          decimal.RequireFromString(amount),
+				Currency:        "USD",
+				Status:          "success",
+			})
+		}
+		return requests
+	}
+
+	// the second transfer only fails with the balance the first one left
+	requests := transfers("30", "30")
+	if _, err := s.CreateTransactions(ctx, requests); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("CreateTransactions() beyond the balance error = %v, want InvalidArgument", err)
+	}
+	if requests[0].Status == TransactionStatusFailed || requests[1].Status != TransactionStatusFailed {
+		t.Errorf("CreateTransactions() statuses = %s, %s, want the second one failed", requests[0].Status, requests[1].Status)
+	}
+	if _, err := s.GetTransaction(ctx, requests[0].TransactionID); status.Code(err) != codes.NotFound {
+		t.Errorf("GetTransaction() of the first transfer error = %v, want NotFound", err)
+	}
+	if got := accountBalance(t, s, from); !got.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("balance after the failed transfers = %s, want 50", got)
+	}
+
+	entries, err := s.CreateTransactions(ctx, transfers("20", "30"))
+	if err != nil {
+		t.Fatalf("CreateTransactions() error = %v", err)
+	}
+	if len(entries) != 2 || !entries[1][0].Balance.IsZero() {
+		t.Errorf("CreateTransactions() postings = %+v, want the second debit to empty the account", entries)
+	}
+	for i, want := range []int64{20, 30} {
+		if got := accountBalance(t, s, to[i]); !got.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("balance of receiver %d = %s, want %d", i, got, want)
+		}
+	}
+}
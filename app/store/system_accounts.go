@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+// SystemUserID owns the internal system accounts, it is created by a migration
+const SystemUserID = "00000000-0000-0000-0000-000000000001"
+
+// Codes of the internal system accounts. Internal accounts are hidden from the
+// account APIs, cannot take part in customer transfers and may go negative.
+const (
+	// SystemAccountCashIn is debited when money is deposited
+	SystemAccountCashIn = "cash_in"
+	// SystemAccountCashOut is credited when money is withdrawn
+	SystemAccountCashOut = "cash_out"
+	// SystemAccountSuspense is the other side of manual balance adjustments
+	SystemAccountSuspense = "suspense"
+)
+
+// systemAccountNames are the account names of the internal accounts
+var systemAccountNames = map[string]string{
+	SystemAccountCashIn:   "Cash in",
+	SystemAccountCashOut:  "Cash out",
+	SystemAccountSuspense: "Suspense",
+
+	SystemAccountFXPosition: "FX position",
+	SystemAccountFXPnL:      "FX P&L",
+
+	SystemAccountInterestExpense: "Interest expense",
+	SystemAccountFeeIncome:       "Fee income",
+}
+
+const (
+	TransactionTypeTransfer   = "transfer"
+	TransactionTypeDeposit    = "deposit"
+	TransactionTypeWithdrawal = "withdrawal"
+	TransactionTypeAdjustment = "adjustment"
+)
+
+// SystemAccountID returns the id of the internal account with the given code
+// and currency, creating it for currencies that have not been used before
+func (s *Store) SystemAccountID(
+	ctx context.Context,
+	code string,
+	currency string,
+) (string, error) {
+	var id string
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		id, err = s.systemAccountID(ctx, tx, code, currency)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get system account", "error", err, "code", code)
+		return "", err
+	}
+
+	return id, nil
+}
+
+// systemAccountID is SystemAccountID inside a database transaction
+func (s *Store) systemAccountID(
+	ctx context.Context,
+	tx pgx.Tx,
+	code string,
+	currency string,
+) (string, error) {
+	sql, args, err := s.db.Builder.
+		Insert("dbank_accounts").
+		Columns(
+			"id", "user_pk", "account_type", "account_number",
+			"currency", "status", "account_name", "system_code",
+		).
+		Select(s.db.Builder.
+			Select().
+			Column("?::uuid", idx.UUID4()).
+			Column("pk").
+			Column("'internal'").
+			Column("?", strings.ToUpper(code)+"-"+currency).
+			Column("?", currency).
+			Column("'active'").
+			Column("?", systemAccountNames[code]).
+			Column("?", code).
+			From("dbank_users").
+			Where("id = ?", SystemUserID),
+		).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		s.logger.ErrorContext(ctx, "failed to create system account", "error", err, "code", code)
+		return "", status.Errorf(codes.Internal, "failed to create system account")
+	}
+
+	sql, args, err = s.db.Builder.
+		Select("id").
+		From("dbank_accounts").
+		Where("system_code = ?", code).
+		Where("currency = ?", currency).
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var id string
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		s.logger.ErrorContext(ctx, "failed to query system account", "error", err, "code", code)
+		return "", status.Errorf(codes.Internal, "failed to query system account")
+	}
+
+	return id, nil
+}
+
+// postSystemTransaction records a transaction between a customer account and
+// the internal account with the given code, which fills the empty side of the
+// request. The status of the customer account must let it send or receive.
+func (s *Store) postSystemTransaction(
+	ctx context.Context,
+	tx pgx.Tx,
+	request *TransactionRequest,
+	code string,
+) ([]*LedgerEntry, error) {
+	systemID, err := s.systemAccountID(ctx, tx, code, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.FromAccountID == "" {
+		request.FromAccountID = systemID
+	} else {
+		request.ToAccountID = systemID
+	}
+	request.AllowSystemAccounts = true
+
+	accounts, err := s.lockAccounts(ctx, tx, request.FromAccountID, request.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, toAccount := accounts[request.FromAccountID], accounts[request.ToAccountID]
+	if err = checkAccountStatuses(fromAccount, toAccount); err != nil {
+		return nil, err
+	}
+
+	return s.recordTransaction(ctx, tx, request, fromAccount, toAccount)
+}
+
+// adjustAccountBalance brings an account to the given balance with an
+// adjustment posted against the suspense account. The caller locks the
+// suspense account together with the account, locking it after the account
+// alone could deadlock with a closure that sweeps to the suspense account.
+func (s *Store) adjustAccountBalance(
+	ctx context.Context,
+	tx pgx.Tx,
+	accountID string,
+	balance decimal.Decimal,
+) error {
+	accounts, err := s.lockAccounts(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+
+	account := accounts[accountID]
+	delta := balance.Sub(account.Balance)
+	if delta.IsZero() {
+		return nil
+	}
+
+	request := &TransactionRequest{
+		TransactionID:   idx.UUID4(),
+		TransactionType: TransactionTypeAdjustment,
+		Amount:          delta.Abs(),
+		Currency:        account.Currency,
+		Description:     "Balance adjustment",
+		Status:          TransactionStatusSuccess,
+	}
+	if delta.IsPositive() {
+		request.ToAccountID = accountID
+	} else {
+		request.FromAccountID = accountID
+	}
+
+	_, err = s.postSystemTransaction(ctx, tx, request, SystemAccountSuspense)
+	return err
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestStore_SystemAccounts(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "10", "EUR")
+
+	cashIn, err := s.SystemAccountID(ctx, SystemAccountCashIn, "EUR")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+	again, err := s.SystemAccountID(ctx, SystemAccountCashIn, "EUR")
+	if err != nil || again != cashIn {
+		t.Fatalf("SystemAccountID() = %s, %v, want the existing account %s", again, err, cashIn)
+	}
+	cashOut, err := s.SystemAccountID(ctx, SystemAccountCashOut, "EUR")
+	if err != nil {
+		t.Fatalf("SystemAccountID() error = %v", err)
+	}
+
+	cashInBefore := accountBalance(t, s, cashIn)
+
+	move := func(from, to, transactionType, amount string, allow bool) error {
+		_, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:       idx.UUID4(),
+			FromAccountID:       from,
+			ToAccountID:         to,
+			TransactionType:     transactionType,
+			Amount:              decimal.RequireFromString(amount),
+			Currency:            "EUR",
+			Status:              TransactionStatusSuccess,
+			AllowSystemAccounts: allow,
+		})
+		return err
+	}
+
+	if err = move(cashIn, account, TransactionTypeDeposit, "25", true); err != nil {
+		t.Fatalf("deposit error = %v", err)
+	}
+	if err = move(account, cashOut, TransactionTypeWithdrawal, "40", true); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("withdrawal above the balance error = %v, want InvalidArgument", err)
+	}
+	if err = move(account, cashOut, TransactionTypeWithdrawal, "30", true); err != nil {
+		t.Fatalf("withdrawal error = %v", err)
+	}
+	if err = move(cashIn, account, TransactionTypeTransfer, "1", false); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("transfer from an internal account error = %v, want PermissionDenied", err)
+	}
+
+	if got := accountBalance(t, s, account); !got.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("account balance = %s, want 5", got)
+	}
+	if got := accountBalance(t, s, cashIn).Sub(cashInBefore); !got.Equal(decimal.NewFromInt(-25)) {
+		t.Errorf("cash-in balance changed by %s, want -25", got)
+	}
+
+	accounts, err := s.GetAllAccounts(ctx, 1, 1000)
+	if err != nil {
+		t.Fatalf("GetAllAccounts() error = %v", err)
+	}
+	for _, a := range accounts {
+		if a.ID == cashIn || a.ID == cashOut {
+			t.Errorf("GetAllAccounts() returned internal account %s", a.ID)
+		}
+	}
+}
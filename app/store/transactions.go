@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+// ErrInvalidCursor is returned when a page token cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionFilter narrows down the transactions of an account.
+// Zero values are ignored.
+type TransactionFilter struct {
+	AccountID       string
+	Direction       string
+	TransactionType string
+	Status          string
+	Currency        string
+	MinAmount       *decimal.Decimal
+	MaxAmount       *decimal.Decimal
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	Limit           uint64
+	Cursor          string
+}
+
+// transactionCursor is the keyset position of the last row of a page
+type transactionCursor struct {
+	CreatedAt time.Time `json:"c"`
+	PK        int       `json:"p"`
+}
+
+// encodeCursor returns an opaque page token for a keyset position
+func encodeCursor(c transactionCursor) string {
+	body, _ := json.Marshal(c) // marshalling a time and an int cannot fail
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+// decodeCursor parses a page token created by encodeCursor
+func decodeCursor(token string) (transactionCursor, error) {
+	var c transactionCursor
+
+	body, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(body, &c); err != nil || c.PK <= 0 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// ListTransactions returns one page of an account's transactions, newest first,
+// and the cursor of the next page which is empty on the last page.
+// Pages are keyset paginated on (created_at, pk) so that they stay stable while
+// new transactions are being written.
+func (s *Store) ListTransactions(
+	ctx context.Context,
+	filter *TransactionFilter,
+) ([]*Transaction, string, error) {
+	builder := s.db.Builder.
+		Select(
+			"t.pk", "t.id", "t.from_account_id", "t.to_account_id",
+			"t.transaction_type", "t.amount", "t.currency",
+			"t.description", "t.status", "COALESCE(t.reversal_of::text, '')",
+			"t.to_amount", "t.to_currency", "COALESCE(t.fx_rate_id::text, '')",
+			"COALESCE(t.fx_rate, 0)", "COALESCE(t.fx_spread, 0)", "COALESCE(t.fx_snapshot_id::text, '')",
+			"t.fee_amount", "t.created_at", "t.updated_at",
+		).
+		From("dbank_transactions t").
+		Where("t.deleted_at IS NULL").
+		OrderBy("t.created_at DESC", "t.pk DESC").
+		Limit(filter.Limit + 1)
+
+	switch filter.Direction {
+	case DirectionIncoming:
+		builder = builder.Where("t.to_account_id = ?", filter.AccountID)
+	case DirectionOutgoing:
+		builder = builder.Where("t.from_account_id = ?", filter.AccountID)
+	default:
+		builder = builder.Where(squirrel.Or{
+			squirrel.Eq{"t.from_account_id": filter.AccountID},
+			squirrel.Eq{"t.to_account_id": filter.AccountID},
+		})
+	}
+
+	if filter.TransactionType != "" {
+		builder = builder.Where("t.transaction_type = ?", filter.TransactionType)
+	}
+	if filter.Status != "" {
+		builder = builder.Where("t.status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		builder = builder.Where("t.currency = ?", filter.Currency)
+	}
+	if filter.MinAmount != nil {
+		builder = builder.Where("t.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		builder = builder.Where("t.amount <= ?", *filter.MaxAmount)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		builder = builder.Where("t.created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		builder = builder.Where("t.created_at < ?", filter.CreatedTo)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid page token")
+		}
+		builder = builder.Where("(t.created_at, t.pk) < (?, ?)", cursor.CreatedAt, cursor.PK)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, "", status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to query transactions", "error", err)
+		return nil, "", status.Errorf(codes.Internal, "failed to query transactions")
+	}
+	defer rows.Close()
+
+	var (
+		transactions []*Transaction
+		last         transactionCursor
+		hasMore      bool
+	)
+	for rows.Next() {
+		var transaction Transaction
+		var pk int
+		err = rows.Scan(
+			&pk,
+			&transaction.TransactionID,
+			&transaction.FromAccountID,
+			&transaction.ToAccountID,
+			&transaction.TransactionType,
+			&transaction.Amount,
+			&transaction.Currency,
+			&transaction.Description,
+			&transaction.Status,
+			&transaction.ReversalOf,
+			&transaction.ToAmount,
+			&transaction.ToCurrency,
+			&transaction.FXRateID,
+			&transaction.FXRate,
+			&transaction.FXSpread,
+			&transaction.FXSnapshotID,
+			&transaction.FeeAmount,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+		)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to scan transaction", "error", err)
+			return nil, "", status.Errorf(codes.Internal, "failed to scan transaction")
+		}
+
+		if uint64(len(transactions)) == filter.Limit {
+			// the extra row only tells us that there is a next page
+			hasMore = true
+			break
+		}
+
+		transactions = append(transactions, &transaction)
+		last = transactionCursor{CreatedAt: transaction.CreatedAt, PK: pk}
+	}
+
+	if err = rows.Err(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to iterate transactions", "error", err)
+		return nil, "", status.Errorf(codes.Internal, "failed to iterate transactions")
+	}
+
+	var nextCursor string
+	if hasMore {
+		nextCursor = encodeCursor(last)
+	}
+
+	return transactions, nextCursor, nil
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func Test_decodeCursor(t *testing.T) {
+	want := transactionCursor{CreatedAt: time.Date(2025, 5, 1, 10, 30, 0, 123456000, time.UTC), PK: 42}
+
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.PK != want.PK {
+		t.Errorf("decodeCursor() = %+v, want %+v", got, want)
+	}
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", encodeCursor(transactionCursor{})} {
+		if _, err := decodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func TestStore_ListTransactions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	account := createTestAccount(t, s, "100", "USD")
+	other := createTestAccount(t, s, "100", "USD")
+
+	for i := range 5 {
+		from, to := account, other
+		if i%2 == 1 {
+			from, to = other, account
+		}
+		if _, err := s.CreateTransaction(ctx, &TransactionRequest{
+			TransactionID:   idx.UUID4(),
+			FromAccountID:   from,
+			ToAccountID:     to,
+			TransactionType: "transfer",
+			Amount:          decimal.NewFromInt(int64(i + 1)),
+			Currency:        "USD",
+			Status:          "success",
+		}); err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+	}
+
+	var all []*Transaction
+	cursor := ""
+	for {
+		page, next, err := s.ListTransactions(ctx, &TransactionFilter{AccountID: account, Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("ListTransactions() error = %v", err)
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(all) != 5 {
+		t.Fatalf("ListTransactions() returned %d transactions over all pages, want 5", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].CreatedAt.After(all[i-1].CreatedAt) {
+			t.Errorf("transactions are not ordered newest first at index %d", i)
+		}
+	}
+
+	outgoing, _, err := s.ListTransactions(ctx, &TransactionFilter{
+		AccountID: account,
+		Direction: DirectionOutgoing,
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("ListTransactions() error = %v", err)
+	}
+	if len(outgoing) != 3 {
+		t.Errorf("ListTransactions(outgoing) returned %d transactions, want 3", len(outgoing))
+	}
+
+	minAmount := decimal.NewFromInt(4)
+	large, _, err := s.ListTransactions(ctx, &TransactionFilter{
+		AccountID: account,
+		MinAmount: &minAmount,
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("ListTransactions() error = %v", err)
+	}
+	if len(large) != 2 {
+		t.Errorf("ListTransactions(min_amount=4) returned %d transactions, want 2", len(large))
+	}
+}
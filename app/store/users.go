@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/dbx"
+)
+
+// AuditActionPasswordChanged is the audit log action of SetPassword
+const AuditActionPasswordChanged = "user.password_changed"
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// customerUser leaves out the system user that owns the internal accounts,
+// it is not a customer and cannot be read, changed or given accounts
+var customerUser = squirrel.NotEq{"id": SystemUserID}
+
+// User is a customer of the bank, a user owns any number of accounts.
+// Password is the password hash.
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Password  string    `json:"-"`
+	KYCTier   string    `json:"kyc_tier"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateUser creates a user without accounts, KYCTier, CreatedAt and
+// UpdatedAt are set from the stored row
+func (s *Store) CreateUser(
+	ctx context.Context,
+	user *User,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := s.insertUser(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to create user", "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "user created", "user_id", user.ID)
+	return nil
+}
+
+// insertUser inserts a user and returns its pk
+func (s *Store) insertUser(
+	ctx context.Context,
+	tx pgx.Tx,
+	user *User,
+) (int, error) {
+	sql, args, err := s.db.Builder.
+		Insert("dbank_users").
+		Columns("id", "username", "email", "password").
+		Values(user.ID, user.Username, user.Email, user.Password).
+		Suffix("RETURNING pk, kyc_tier, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build user SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var userPK int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&userPK, &user.KYCTier, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to execute user SQL query", "error", err)
+		return 0, userWriteError(err, "failed to execute SQL query")
+	}
+
+	return userPK, nil
+}
+
+// GetUser returns a user by its id
+func (s *Store) GetUser(
+	ctx context.Context,
+	id string,
+) (*User, error) {
+	sql, args, err := s.db.Builder.
+		Select("id", "username", "email", "password", "kyc_tier", "created_at", "updated_at").
+		From("dbank_users").
+		Where("id = ?", id).
+		Where(customerUser).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var user User
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.KYCTier,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query user", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query user")
+	}
+
+	return &user, nil
+}
+
+// UpdateUser changes the username and email of a user, the other fields of
+// user are set from the stored row
+func (s *Store) UpdateUser(
+	ctx context.Context,
+	user *User,
+) error {
+	sql, args, err := s.db.Builder.
+		Update("dbank_users").
+		Set("username", user.Username).
+		Set("email", user.Email).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("id = ?", user.ID).
+		Where(customerUser).
+		Where("deleted_at IS NULL").
+		Suffix("RETURNING password, kyc_tier, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	err = s.db.Pool.QueryRow(ctx, sql, args...).Scan(&user.Password, &user.KYCTier, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to update user", "error", err, "user_id", user.ID)
+		return userWriteError(err, "failed to update user")
+	}
+
+	return nil
+}
+
+// SetPassword replaces the password hash of a user and records the change
+// in the audit log of the user
+func (s *Store) SetPassword(
+	ctx context.Context,
+	userID string,
+	passwordHash string,
+) error {
+	err := dbx.RunInTx(ctx, s.logger, s.db, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args, err := s.db.Builder.
+			Update("dbank_users").
+			Set("password", passwordHash).
+			Set("updated_at", squirrel.Expr("now()")).
+			Where("id = ?", userID).
+			Where(customerUser).
+			Where("deleted_at IS NULL").
+			Suffix("RETURNING pk").
+			ToSql()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+			return status.Errorf(codes.Internal, "failed to build SQL query")
+		}
+
+		var userPK int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&userPK); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "user not found")
+			}
+			s.logger.ErrorContext(ctx, "failed to update password", "error", err)
+			return status.Errorf(codes.Internal, "failed to update password")
+		}
+
+		_, err = s.writeAuditLog(ctx, tx, userPK, AuditActionPasswordChanged, map[string]string{"user_id": userID})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to set password", "error", err, "user_id", userID)
+		return err
+	}
+
+	return nil
+}
+
+// userPK returns the pk of a user
+func (s *Store) userPK(
+	ctx context.Context,
+	tx pgx.Tx,
+	id string,
+) (int, error) {
+	sql, args, err := s.db.Builder.
+		Select("pk").
+		From("dbank_users").
+		Where("id = ?", id).
+		Where(customerUser).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to build SQL query", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to build SQL query")
+	}
+
+	var userPK int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&userPK); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, status.Errorf(codes.NotFound, "user not found")
+		}
+		s.logger.ErrorContext(ctx, "failed to query user", "error", err)
+		return 0, status.Errorf(codes.Internal, "failed to query user")
+	}
+
+	return userPK, nil
+}
+
+// userWriteError maps a failed write of a user to a status, a taken username
+// or email is AlreadyExists
+func userWriteError(err error, message string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return status.Errorf(codes.AlreadyExists, "username or email is already taken")
+	}
+	return status.Errorf(codes.Internal, "%s", message)
+}
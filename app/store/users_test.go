@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/amjadjibon/dbank/pkg/idx"
+)
+
+func TestUserWriteError(t *testing.T) {
+	err := userWriteError(&pgconn.PgError{Code: pgUniqueViolation}, "failed")
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("userWriteError() of a unique violation = %v, want AlreadyExists", err)
+	}
+
+	err = userWriteError(errors.New("connection reset"), "failed")
+	if status.Code(err) != codes.Internal {
+		t.Errorf("userWriteError() = %v, want Internal", err)
+	}
+}
+
+func TestStore_Users(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	id := idx.UUID4()
+	user := &User{ID: id, Username: "test-" + id, Email: id + "@example.com", Password: "secret"}
+	if err := s.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.KYCTier == "" || user.CreatedAt.IsZero() {
+		t.Errorf("CreateUser() = %+v, want the stored tier and times", user)
+	}
+
+	taken := &User{ID: idx.UUID4(), Username: user.Username, Email: "other-" + user.Email, Password: "secret"}
+	if err := s.CreateUser(ctx, taken); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateUser() with a taken username error = %v, want AlreadyExists", err)
+	}
+
+	accounts, err := s.ListUserAccounts(ctx, id)
+	if err != nil || len(accounts) != 0 {
+		t.Fatalf("ListUserAccounts() of a new user = %v, %v, want none", accounts, err)
+	}
+
+	var opened []string
+	for _, currency := range []string{"USD", "EUR"} {
+		accountID := idx.UUID4()
+		err = s.OpenAccount(ctx, &OpenAccountRequest{
+			UserID:        id,
+			AccountID:     accountID,
+			AccountName:   "test " + currency,
+			AccountType:   "checking",
+			AccountNumber: accountID[:8],
+			Balance:       decimal.NewFromInt(10),
+			Currency:      currency,
+			Status:        AccountStatusActive,
+		})
+		if err != nil {
+			t.Fatalf("OpenAccount() error = %v", err)
+		}
+		opened = append(opened, accountID)
+	}
+
+	err = s.OpenAccount(ctx, &OpenAccountRequest{UserID: idx.UUID4(), AccountID: idx.UUID4()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("OpenAccount() for an unknown user error = %v, want NotFound", err)
+	}
+
+	accounts, err = s.ListUserAccounts(ctx, id)
+	if err != nil {
+		t.Fatalf("ListUserAccounts() error = %v", err)
+	}
+	if len(accounts) != len(opened) {
+		t.Fatalf("ListUserAccounts() returned %d accounts, want %d", len(accounts), len(opened))
+	}
+	for i, account := range accounts {
+		if account.ID != opened[i] || account.UserID != id {
+			t.Errorf("ListUserAccounts()[%d] = %s of %s, want %s of %s", i, account.ID, account.UserID, opened[i], id)
+		}
+		if !account.Balance.Equal(decimal.NewFromInt(10)) {
+			t.Errorf("ListUserAccounts()[%d] balance = %s, want 10", i, account.Balance)
+		}
+	}
+
+	account, err := s.GetAccount(ctx, opened[1])
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if account.Currency != "EUR" || account.UserID != id {
+		t.Errorf("GetAccount() = %s account of %s, want the EUR account of %s", account.Currency, account.UserID, id)
+	}
+	if _, err = s.GetAccount(ctx, id); status.Code(err) != codes.NotFound {
+		t.Errorf("GetAccount() with a user id error = %v, want NotFound", err)
+	}
+
+	user.Email = "updated-" + user.Email
+	if err = s.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if err = s.SetPassword(ctx, id, "new secret"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+
+	stored, err := s.GetUser(ctx, id)
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if stored.Email != user.Email || stored.Password != "new secret" {
+		t.Errorf("GetUser() = %s with password %q, want %s with the new password", stored.Email, stored.Password, user.Email)
+	}
+}
+
+func TestStore_Users_SystemUser(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.GetUser(ctx, SystemUserID); status.Code(err) != codes.NotFound {
+		t.Errorf("GetUser() of the system user error = %v, want NotFound", err)
+	}
+	err := s.UpdateUser(ctx, &User{ID: SystemUserID, Username: "taken-over", Email: "taken-over@example.com"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("UpdateUser() of the system user error = %v, want NotFound", err)
+	}
+	if err = s.SetPassword(ctx, SystemUserID, "secret"); status.Code(err) != codes.NotFound {
+		t.Errorf("SetPassword() of the system user error = %v, want NotFound", err)
+	}
+
+	accountID := idx.UUID4()
+	err = s.OpenAccount(ctx, &OpenAccountRequest{
+		UserID:        SystemUserID,
+		AccountID:     accountID,
+		AccountName:   "test",
+		AccountType:   "checking",
+		AccountNumber: accountID[:8],
+		Currency:      "USD",
+		Status:        AccountStatusActive,
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("OpenAccount() for the system user error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amjadjibon/dbank/app/exporter"
+	"github.com/amjadjibon/dbank/app/store"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/log"
+)
+
+var (
+	exportFormat    string
+	exportAccountID string
+	exportFrom      string
+	exportTo        string
+	exportOutput    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data from the database",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var exportStatementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Export the statement of an account for a period",
+	Run: func(cmd *cobra.Command, _ []string) {
+		if err := exportStatement(cmd); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportStatementCmd)
+
+	exportStatementCmd.Flags().StringVarP(&dbURL, "db-url", "d", "", "Database URL")
+	exportStatementCmd.Flags().StringVarP(&exportFormat, "format", "f", "camt053",
+		"Statement format: "+strings.Join(exporter.Names(), ", "))
+	exportStatementCmd.Flags().StringVarP(&exportAccountID, "account-id", "a", "", "Account ID")
+	exportStatementCmd.Flags().StringVar(&exportFrom, "from", "", "Start of the period (RFC3339, inclusive)")
+	exportStatementCmd.Flags().StringVar(&exportTo, "to", "", "End of the period (RFC3339, exclusive)")
+	exportStatementCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, defaults to stdout")
+
+	_ = exportStatementCmd.MarkFlagRequired("account-id")
+	_ = exportStatementCmd.MarkFlagRequired("from")
+	_ = exportStatementCmd.MarkFlagRequired("to")
+
+	exportStatementCmd.PreRun = checkAndSetDBURL
+}
+
+func exportStatement(cmd *cobra.Command) error {
+	format, ok := exporter.FormatByName(exportFormat)
+	if !ok {
+		return fmt.Errorf("unsupported format %q, use one of %s", exportFormat, strings.Join(exporter.Names(), ", "))
+	}
+
+	from, err := time.Parse(time.RFC3339, exportFrom)
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+
+	to, err := time.Parse(time.RFC3339, exportTo)
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	if !from.Before(to) {
+		return fmt.Errorf("--from must be before --to")
+	}
+
+	db, err := dbx.NewPostgres(dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statement, err := store.NewStore(db, log.GetLogger("error")).
+		GetStatement(cmd.Context(), exportAccountID, from, to)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if exportOutput != "" {
+		file, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return format.Write(w, statement, time.Now())
+}
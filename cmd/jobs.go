@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amjadjibon/dbank/app/interest"
+	"github.com/amjadjibon/dbank/app/service"
+	"github.com/amjadjibon/dbank/app/store"
+	"github.com/amjadjibon/dbank/pkg/amqpx"
+	"github.com/amjadjibon/dbank/pkg/dbx"
+	"github.com/amjadjibon/dbank/pkg/log"
+)
+
+var (
+	interestDate string
+	rabbitmqURL  string
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Run the batch jobs of the bank",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var jobsInterestCmd = &cobra.Command{
+	Use:   "interest",
+	Short: "Accrue the interest of a day, and post it when the day ends a month",
+	Run: func(cmd *cobra.Command, _ []string) {
+		if err := runInterest(cmd); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	jobsCmd.AddCommand(jobsInterestCmd)
+
+	jobsInterestCmd.Flags().StringVarP(&dbURL, "db-url", "d", "", "Database URL")
+	jobsInterestCmd.Flags().StringVar(&interestDate, "date", "",
+		"Day to run (YYYY-MM-DD, UTC), defaults to yesterday")
+	jobsInterestCmd.Flags().StringVar(&rabbitmqURL, "rabbitmq-url", "",
+		"RabbitMQ URL the interest payments are published to, defaults to RABBITMQ_URL")
+
+	jobsInterestCmd.PreRun = checkAndSetDBURL
+}
+
+func runInterest(cmd *cobra.Command) error {
+	date := time.Now().UTC().AddDate(0, 0, -1)
+	if interestDate != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, interestDate); err != nil {
+			return fmt.Errorf("invalid --date: %w", err)
+		}
+	}
+
+	db, err := dbx.NewPostgres(dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	logger := log.GetLogger("error")
+	storage := store.NewStore(db, logger)
+
+	// without RabbitMQ the payments are not published to the ledger projection
+	var publish interest.Publisher
+	if rabbitmqURL == "" {
+		rabbitmqURL = os.Getenv("RABBITMQ_URL")
+	}
+	if rabbitmqURL != "" {
+		rabbitmqClient, err := amqpx.NewRabbitMQClient(rabbitmqURL)
+		if err != nil {
+			return err
+		}
+		defer rabbitmqClient.Close()
+
+		publish = service.NewTransactionService(logger, storage, rabbitmqClient, nil, nil).PublishPosted
+	}
+
+	run, err := interest.NewJob(logger, storage, publish, 0).RunDate(cmd.Context(), date)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("interest for %s: %d accruals, %d postings\n",
+		run.Date.Format(time.DateOnly), run.Accruals, run.Postings)
+	return nil
+}
@@ -27,4 +27,6 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(jobsCmd)
 }
@@ -1,6 +1,8 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v11"
 )
 
@@ -13,6 +15,32 @@ type Config struct {
 	RedisURL    string `env:"REDIS_URL"`
 	RabbitMQURL string `env:"RABBITMQ_URL"`
 	MongoURL    string `env:"MONGO_URL"`
+
+	// FXRatesFile is an optional CSV or JSON file of FX rates, used as a
+	// fallback for the rates entered through the admin API
+	FXRatesFile       string        `env:"FX_RATES_FILE"`
+	FXRefreshInterval time.Duration `env:"FX_REFRESH_INTERVAL" envDefault:"1m"`
+	FXMaxRateAge      time.Duration `env:"FX_MAX_RATE_AGE"     envDefault:"24h"`
+
+	// SchedulerInterval is how often due scheduled transfers are executed
+	SchedulerInterval time.Duration `env:"SCHEDULER_INTERVAL" envDefault:"30s"`
+
+	// HoldTTL is how long a hold lasts unless it is authorized with its own
+	// TTL, expired holds are released every HoldExpiryInterval
+	HoldTTL            time.Duration `env:"HOLD_TTL"             envDefault:"168h"`
+	HoldExpiryInterval time.Duration `env:"HOLD_EXPIRY_INTERVAL" envDefault:"1m"`
+
+	// InterestInterval is how often the interest job looks for ended days
+	// that have not been run
+	InterestInterval time.Duration `env:"INTEREST_INTERVAL" envDefault:"1h"`
+
+	// RiskRulesFile is an optional JSON file of the risk rules run on
+	// transfers before they move money, none are run without it
+	RiskRulesFile string `env:"RISK_RULES_FILE"`
+
+	// BatchInterval is how often the batch worker looks for pending batches,
+	// it also wakes up whenever a batch is created
+	BatchInterval time.Duration `env:"BATCH_INTERVAL" envDefault:"10s"`
 }
 
 func NewConfig() *Config {
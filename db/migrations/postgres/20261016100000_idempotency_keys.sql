@@ -0,0 +1,12 @@
+-- +goose Up
+-- Idempotency keys (stores the first response for every key)
+CREATE TABLE dbank_idempotency_keys (
+    pk           SERIAL        PRIMARY KEY,
+    key          TEXT          NOT NULL UNIQUE,
+    request_hash TEXT          NOT NULL,
+    response     JSONB         NOT NULL,
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_idempotency_keys;
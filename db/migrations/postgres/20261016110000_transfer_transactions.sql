@@ -0,0 +1,37 @@
+-- +goose Up
+-- Transactions record both sides of a transfer (linked by account id)
+DROP INDEX IF EXISTS idx_dbank_tx_account_pk;
+ALTER TABLE dbank_transactions DROP COLUMN account_pk;
+
+ALTER TABLE dbank_transactions
+    ADD COLUMN from_account_id UUID NOT NULL,
+    ADD COLUMN to_account_id   UUID NOT NULL,
+    ADD COLUMN status          TEXT NOT NULL DEFAULT 'pending',
+    ADD FOREIGN KEY (from_account_id) REFERENCES dbank_accounts(id) ON DELETE NO ACTION,
+    ADD FOREIGN KEY (to_account_id)   REFERENCES dbank_accounts(id) ON DELETE NO ACTION,
+    ADD CONSTRAINT chk_dbank_tx_distinct_accounts CHECK (from_account_id <> to_account_id);
+
+CREATE INDEX idx_dbank_tx_from_account_id ON dbank_transactions(from_account_id);
+CREATE INDEX idx_dbank_tx_to_account_id   ON dbank_transactions(to_account_id);
+CREATE INDEX idx_dbank_tx_status          ON dbank_transactions(status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_dbank_tx_status;
+DROP INDEX IF EXISTS idx_dbank_tx_to_account_id;
+DROP INDEX IF EXISTS idx_dbank_tx_from_account_id;
+
+ALTER TABLE dbank_transactions ADD COLUMN account_pk INT;
+UPDATE dbank_transactions t
+   SET account_pk = a.pk
+  FROM dbank_accounts a
+ WHERE a.id = t.from_account_id;
+ALTER TABLE dbank_transactions
+    ALTER COLUMN account_pk SET NOT NULL,
+    ADD FOREIGN KEY (account_pk) REFERENCES dbank_accounts(pk) ON DELETE NO ACTION;
+CREATE INDEX idx_dbank_tx_account_pk ON dbank_transactions(account_pk);
+
+ALTER TABLE dbank_transactions
+    DROP CONSTRAINT IF EXISTS chk_dbank_tx_distinct_accounts,
+    DROP COLUMN status,
+    DROP COLUMN to_account_id,
+    DROP COLUMN from_account_id;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- Ledger postings: debits carry a negative amount, credits a positive one and
+-- balance is the running balance of the account after the posting.
+-- Postings are stamped when they are written, after the account row lock was
+-- taken, rather than when their database transaction started. A transfer that
+-- waited on the lock is then stamped after the one it waited for, so the
+-- postings of an account are in the same order by time as by pk.
+ALTER TABLE dbank_ledgers
+    ALTER COLUMN created_at SET DEFAULT clock_timestamp(),
+    ADD COLUMN entry_type TEXT          NOT NULL,
+    ADD COLUMN amount     DECIMAL(20,6) NOT NULL,
+    ADD COLUMN currency   TEXT          NOT NULL,
+    ADD CONSTRAINT chk_dbank_ledgers_entry_type CHECK (
+        (entry_type = 'debit' AND amount < 0) OR (entry_type = 'credit' AND amount > 0)
+    );
+CREATE INDEX idx_dbank_ledgers_account_created ON dbank_ledgers(account_pk, created_at, pk);
+
+-- The postings of a transaction must sum to zero in every currency. The check is
+-- a deferred constraint trigger so it runs once all postings have been inserted.
+-- +goose StatementBegin
+CREATE FUNCTION dbank_check_ledger_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+          FROM dbank_ledgers
+         WHERE transaction_pk = NEW.transaction_pk
+           AND deleted_at IS NULL
+         GROUP BY currency
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger postings for transaction % do not balance', NEW.transaction_pk;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE CONSTRAINT TRIGGER trg_dbank_ledgers_balanced
+    AFTER INSERT OR UPDATE ON dbank_ledgers
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION dbank_check_ledger_balanced();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_dbank_ledgers_balanced ON dbank_ledgers;
+DROP FUNCTION IF EXISTS dbank_check_ledger_balanced();
+DROP INDEX IF EXISTS idx_dbank_ledgers_account_created;
+ALTER TABLE dbank_ledgers
+    DROP CONSTRAINT IF EXISTS chk_dbank_ledgers_entry_type,
+    DROP COLUMN currency,
+    DROP COLUMN amount,
+    DROP COLUMN entry_type,
+    ALTER COLUMN created_at SET DEFAULT now();
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Keyset pagination of an account's transactions walks (created_at, pk) per side
+DROP INDEX IF EXISTS idx_dbank_tx_from_account_id;
+DROP INDEX IF EXISTS idx_dbank_tx_to_account_id;
+CREATE INDEX idx_dbank_tx_from_account_created ON dbank_transactions(from_account_id, created_at DESC, pk DESC);
+CREATE INDEX idx_dbank_tx_to_account_created   ON dbank_transactions(to_account_id, created_at DESC, pk DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_dbank_tx_to_account_created;
+DROP INDEX IF EXISTS idx_dbank_tx_from_account_created;
+CREATE INDEX idx_dbank_tx_from_account_id ON dbank_transactions(from_account_id);
+CREATE INDEX idx_dbank_tx_to_account_id   ON dbank_transactions(to_account_id);
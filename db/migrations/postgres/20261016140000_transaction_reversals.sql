@@ -0,0 +1,16 @@
+-- +goose Up
+-- A reversal is a transaction of its own that points at the transaction it compensates.
+-- A transaction can be reversed in several parts, up to its amount. reversed_amount
+-- is the part reversed so far, in the currency of the transaction, and the
+-- transaction is partially_reversed until it reaches the amount.
+ALTER TABLE dbank_transactions
+    ADD COLUMN reversal_of UUID REFERENCES dbank_transactions(id) ON DELETE NO ACTION,
+    ADD COLUMN reversed_amount DECIMAL(20,6) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_dbank_tx_reversal_of ON dbank_transactions(reversal_of) WHERE reversal_of IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_dbank_tx_reversal_of;
+
+ALTER TABLE dbank_transactions DROP COLUMN reversed_amount;
+ALTER TABLE dbank_transactions DROP COLUMN reversal_of;
@@ -0,0 +1,91 @@
+-- +goose Up
+-- Internal accounts are the other side of the money that enters or leaves the bank.
+-- They belong to the system user, one account per code and currency.
+ALTER TABLE dbank_accounts ADD COLUMN system_code TEXT;
+
+CREATE UNIQUE INDEX idx_dbank_accounts_system_code
+    ON dbank_accounts(system_code, currency) WHERE system_code IS NOT NULL;
+
+INSERT INTO dbank_users (id, username, email, password)
+VALUES ('00000000-0000-0000-0000-000000000001', 'dbank-system', 'system@dbank.internal', '!');
+
+-- Seed the internal accounts for the currencies in use, others are created on first use
+INSERT INTO dbank_accounts (id, user_pk, account_type, account_number, currency, status, account_name, system_code)
+SELECT gen_random_uuid(), u.pk, 'internal', upper(c.code) || '-' || cur.currency,
+       cur.currency, 'active', c.name, c.code
+  FROM dbank_users u
+ CROSS JOIN (VALUES ('cash_in', 'Cash in'), ('cash_out', 'Cash out'), ('suspense', 'Suspense')) AS c(code, name)
+ CROSS JOIN (SELECT currency FROM dbank_accounts UNION SELECT 'USD') AS cur(currency)
+ WHERE u.id = '00000000-0000-0000-0000-000000000001';
+
+-- Balances seeded before postings existed get an opening adjustment from cash_in,
+-- dated when the account was opened, so that the ledger sums to zero and every
+-- balance is the sum of its postings. The opening amount is the part of the
+-- balance that no posting accounts for.
+CREATE TEMPORARY TABLE dbank_opening_balances AS
+SELECT a.pk AS account_pk, a.id AS account_id, a.currency, a.created_at,
+       a.balance - COALESCE(SUM(l.amount), 0) AS amount,
+       gen_random_uuid() AS transaction_id
+  FROM dbank_accounts a
+  LEFT JOIN dbank_ledgers l ON l.account_pk = a.pk AND l.deleted_at IS NULL
+ WHERE a.system_code IS NULL
+ GROUP BY a.pk
+HAVING a.balance - COALESCE(SUM(l.amount), 0) <> 0;
+
+INSERT INTO dbank_transactions (
+    id, from_account_id, to_account_id, transaction_type, amount, currency, description, status,
+    transaction_date, created_at, updated_at
+)
+SELECT o.transaction_id,
+       CASE WHEN o.amount > 0 THEN c.id ELSE o.account_id END,
+       CASE WHEN o.amount > 0 THEN o.account_id ELSE c.id END,
+       'adjustment', abs(o.amount), o.currency, 'Opening balance', 'success',
+       o.created_at, o.created_at, o.created_at
+  FROM dbank_opening_balances o
+  JOIN dbank_accounts c ON c.system_code = 'cash_in' AND c.currency = o.currency;
+
+-- the opening posting comes first, so the running balance after it is the opening amount
+INSERT INTO dbank_ledgers (id, account_pk, transaction_pk, entry_type, amount, balance, currency, created_at, updated_at)
+SELECT gen_random_uuid(), o.account_pk, t.pk,
+       CASE WHEN o.amount > 0 THEN 'credit' ELSE 'debit' END,
+       o.amount, o.amount, o.currency, o.created_at, o.created_at
+  FROM dbank_opening_balances o
+  JOIN dbank_transactions t ON t.id = o.transaction_id;
+
+INSERT INTO dbank_ledgers (id, account_pk, transaction_pk, entry_type, amount, balance, currency, created_at, updated_at)
+SELECT gen_random_uuid(), c.pk, t.pk,
+       CASE WHEN o.amount > 0 THEN 'debit' ELSE 'credit' END,
+       -o.amount,
+       -SUM(o.amount) OVER (PARTITION BY o.currency ORDER BY o.created_at, o.account_pk),
+       o.currency, o.created_at, o.created_at
+  FROM dbank_opening_balances o
+  JOIN dbank_transactions t ON t.id = o.transaction_id
+  JOIN dbank_accounts c ON c.system_code = 'cash_in' AND c.currency = o.currency;
+
+UPDATE dbank_accounts c
+   SET balance = c.balance - o.amount
+  FROM (SELECT currency, SUM(amount) AS amount FROM dbank_opening_balances GROUP BY currency) o
+ WHERE c.system_code = 'cash_in' AND c.currency = o.currency;
+
+DROP TABLE dbank_opening_balances;
+
+-- +goose Down
+-- the opening adjustments are the only postings of the internal accounts this migration knows
+DELETE FROM dbank_ledgers
+ WHERE transaction_pk IN (
+    SELECT t.pk
+      FROM dbank_transactions t
+      JOIN dbank_accounts c ON c.id IN (t.from_account_id, t.to_account_id)
+     WHERE c.system_code = 'cash_in' AND t.transaction_type = 'adjustment' AND t.description = 'Opening balance'
+ );
+DELETE FROM dbank_transactions t
+ USING dbank_accounts c
+ WHERE c.id IN (t.from_account_id, t.to_account_id)
+   AND c.system_code = 'cash_in' AND t.transaction_type = 'adjustment' AND t.description = 'Opening balance';
+
+DELETE FROM dbank_accounts WHERE system_code IS NOT NULL;
+DELETE FROM dbank_users WHERE id = '00000000-0000-0000-0000-000000000001';
+
+DROP INDEX IF EXISTS idx_dbank_accounts_system_code;
+
+ALTER TABLE dbank_accounts DROP COLUMN system_code;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- FX rates: one unit of base_currency costs rate units of quote_currency from
+-- effective_at on. spread is the fraction of the converted amount kept by the
+-- bank, so a customer receives amount * rate * (1 - spread).
+CREATE TABLE dbank_fx_rates (
+    pk             SERIAL         PRIMARY KEY,
+    id             UUID           NOT NULL UNIQUE,
+    base_currency  TEXT           NOT NULL,
+    quote_currency TEXT           NOT NULL,
+    rate           DECIMAL(24,10) NOT NULL CHECK (rate > 0),
+    spread         DECIMAL(10,6)  NOT NULL DEFAULT 0 CHECK (spread >= 0 AND spread < 1),
+    effective_at   TIMESTAMPTZ    NOT NULL,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    CHECK (base_currency <> quote_currency)
+);
+CREATE INDEX idx_dbank_fx_rates_pair ON dbank_fx_rates(base_currency, quote_currency, effective_at DESC);
+
+-- Transfers credit to_amount in to_currency, which differ from amount and
+-- currency when the money was converted at the recorded fx rate
+ALTER TABLE dbank_transactions
+    ADD COLUMN to_amount   DECIMAL(20,6),
+    ADD COLUMN to_currency TEXT,
+    ADD COLUMN fx_rate_id  UUID REFERENCES dbank_fx_rates(id),
+    ADD COLUMN fx_rate     DECIMAL(24,10),
+    ADD COLUMN fx_spread   DECIMAL(10,6);
+UPDATE dbank_transactions SET to_amount = amount, to_currency = currency;
+ALTER TABLE dbank_transactions
+    ALTER COLUMN to_amount SET NOT NULL,
+    ALTER COLUMN to_currency SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE dbank_transactions
+    DROP COLUMN IF EXISTS fx_spread,
+    DROP COLUMN IF EXISTS fx_rate,
+    DROP COLUMN IF EXISTS fx_rate_id,
+    DROP COLUMN IF EXISTS to_currency,
+    DROP COLUMN IF EXISTS to_amount;
+DROP TABLE IF EXISTS dbank_fx_rates;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Every refresh of the FX rate providers is stored as a snapshot, the rates in
+-- dbank_fx_rates belong to the snapshot that fetched them
+CREATE TABLE dbank_fx_snapshots (
+    pk         SERIAL       PRIMARY KEY,
+    id         UUID         NOT NULL UNIQUE,
+    source     TEXT         NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+ALTER TABLE dbank_fx_rates
+    ADD COLUMN snapshot_id UUID    REFERENCES dbank_fx_snapshots(id),
+    ADD COLUMN source      TEXT    NOT NULL DEFAULT 'manual',
+    ADD COLUMN stale       BOOLEAN NOT NULL DEFAULT false;
+
+-- Converted transfers reference the snapshot of the rate they used
+ALTER TABLE dbank_transactions
+    ADD COLUMN fx_snapshot_id UUID REFERENCES dbank_fx_snapshots(id);
+
+-- Rates entered through the admin SetFXRate RPC, read by the admin rate provider
+CREATE TABLE dbank_fx_manual_rates (
+    pk             SERIAL         PRIMARY KEY,
+    id             UUID           NOT NULL UNIQUE,
+    base_currency  TEXT           NOT NULL,
+    quote_currency TEXT           NOT NULL,
+    rate           DECIMAL(24,10) NOT NULL CHECK (rate > 0),
+    spread         DECIMAL(10,6)  NOT NULL DEFAULT 0 CHECK (spread >= 0 AND spread < 1),
+    effective_at   TIMESTAMPTZ    NOT NULL,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    CHECK (base_currency <> quote_currency)
+);
+CREATE INDEX idx_dbank_fx_manual_rates_pair ON dbank_fx_manual_rates(base_currency, quote_currency, effective_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_fx_manual_rates;
+ALTER TABLE dbank_transactions DROP COLUMN IF EXISTS fx_snapshot_id;
+ALTER TABLE dbank_fx_rates
+    DROP COLUMN IF EXISTS stale,
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS snapshot_id;
+DROP TABLE IF EXISTS dbank_fx_snapshots;
@@ -0,0 +1,49 @@
+-- +goose Up
+-- Standing orders. next_occurrence_at is the occurrence due next and
+-- next_run_at when the worker tries it, which is later while an occurrence
+-- is retried. attempt counts the failed attempts of the current occurrence.
+CREATE TABLE dbank_scheduled_transfers (
+    pk                     SERIAL        PRIMARY KEY,
+    id                     UUID          NOT NULL UNIQUE,
+    from_account_id        UUID          NOT NULL REFERENCES dbank_accounts(id),
+    to_account_id          UUID          NOT NULL REFERENCES dbank_accounts(id),
+    amount                 DECIMAL(20,6) NOT NULL CHECK (amount > 0),
+    currency               TEXT          NOT NULL,
+    description            TEXT          NOT NULL DEFAULT '',
+    frequency              TEXT          NOT NULL CHECK (frequency IN ('once', 'daily', 'weekly', 'monthly', 'cron')),
+    cron_expression        TEXT          NOT NULL DEFAULT '',
+    start_at               TIMESTAMPTZ   NOT NULL,
+    end_at                 TIMESTAMPTZ,
+    max_runs               INT           CHECK (max_runs > 0),
+    run_count              INT           NOT NULL DEFAULT 0,
+    max_retries            INT           NOT NULL DEFAULT 0 CHECK (max_retries >= 0),
+    retry_interval_seconds INT           NOT NULL DEFAULT 3600 CHECK (retry_interval_seconds > 0),
+    attempt                INT           NOT NULL DEFAULT 0,
+    next_occurrence_at     TIMESTAMPTZ,
+    next_run_at            TIMESTAMPTZ,
+    status                 TEXT          NOT NULL CHECK (status IN ('active', 'paused', 'cancelled', 'completed')),
+    created_at             TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at             TIMESTAMPTZ   NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_dbank_scheduled_transfers_due ON dbank_scheduled_transfers(next_run_at) WHERE status = 'active';
+CREATE INDEX idx_dbank_scheduled_transfers_from ON dbank_scheduled_transfers(from_account_id);
+CREATE INDEX idx_dbank_scheduled_transfers_to ON dbank_scheduled_transfers(to_account_id);
+
+-- Outcome of every attempt of an occurrence. The unique key keeps a second
+-- worker from recording the same attempt twice.
+CREATE TABLE dbank_scheduled_transfer_runs (
+    pk                    SERIAL      PRIMARY KEY,
+    id                    UUID        NOT NULL UNIQUE,
+    scheduled_transfer_pk INT         NOT NULL REFERENCES dbank_scheduled_transfers(pk),
+    occurrence_at         TIMESTAMPTZ NOT NULL,
+    attempt               INT         NOT NULL,
+    status                TEXT        NOT NULL CHECK (status IN ('succeeded', 'retrying', 'failed')),
+    transaction_id        UUID,
+    error                 TEXT        NOT NULL DEFAULT '',
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (scheduled_transfer_pk, occurrence_at, attempt)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_scheduled_transfer_runs;
+DROP TABLE IF EXISTS dbank_scheduled_transfers;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- held_balance is the sum of the authorized holds of an account, the money
+-- that is reserved but not settled yet. available_balance is what is left to spend.
+ALTER TABLE dbank_accounts
+    ADD COLUMN held_balance DECIMAL(20,6) NOT NULL DEFAULT 0 CHECK (held_balance >= 0),
+    ADD COLUMN available_balance DECIMAL(20,6) GENERATED ALWAYS AS (balance - held_balance) STORED;
+
+-- Two-phase authorizations. A hold reserves amount on account_pk until it is
+-- captured into to_account_id, voided, or expires at expires_at.
+CREATE TABLE dbank_holds (
+    pk              SERIAL        PRIMARY KEY,
+    id              UUID          NOT NULL UNIQUE,
+    account_pk      INT           NOT NULL REFERENCES dbank_accounts(pk),
+    to_account_id   UUID          NOT NULL REFERENCES dbank_accounts(id),
+    amount          DECIMAL(20,6) NOT NULL CHECK (amount > 0),
+    currency        TEXT          NOT NULL,
+    description     TEXT          NOT NULL DEFAULT '',
+    status          TEXT          NOT NULL CHECK (status IN ('authorized', 'captured', 'voided', 'expired')),
+    captured_amount DECIMAL(20,6),
+    -- deferred because a capture settles the hold before inserting its transaction
+    transaction_id  UUID          REFERENCES dbank_transactions(id) DEFERRABLE INITIALLY DEFERRED,
+    expires_at      TIMESTAMPTZ   NOT NULL,
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ   NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_dbank_holds_account_pk ON dbank_holds(account_pk);
+CREATE INDEX idx_dbank_holds_expires_at ON dbank_holds(expires_at) WHERE status = 'authorized';
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_holds;
+
+ALTER TABLE dbank_accounts
+    DROP COLUMN available_balance,
+    DROP COLUMN held_balance;
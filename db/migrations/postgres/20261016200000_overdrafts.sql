@@ -0,0 +1,22 @@
+-- +goose Up
+-- overdraft_limit is how far below zero an account may go. overdrawn_since is
+-- set while the balance is below zero and max_overdraft is the deepest it went.
+ALTER TABLE dbank_accounts
+    ADD COLUMN overdraft_limit DECIMAL(20,6) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0),
+    ADD COLUMN overdrawn_since TIMESTAMPTZ,
+    ADD COLUMN max_overdraft   DECIMAL(20,6) NOT NULL DEFAULT 0;
+
+-- The arranged overdraft can be spent, so it is part of the available balance
+ALTER TABLE dbank_accounts DROP COLUMN available_balance;
+ALTER TABLE dbank_accounts
+    ADD COLUMN available_balance DECIMAL(20,6) GENERATED ALWAYS AS (balance - held_balance + overdraft_limit) STORED;
+
+-- +goose Down
+ALTER TABLE dbank_accounts DROP COLUMN available_balance;
+ALTER TABLE dbank_accounts
+    ADD COLUMN available_balance DECIMAL(20,6) GENERATED ALWAYS AS (balance - held_balance) STORED;
+
+ALTER TABLE dbank_accounts
+    DROP COLUMN max_overdraft,
+    DROP COLUMN overdrawn_since,
+    DROP COLUMN overdraft_limit;
@@ -0,0 +1,67 @@
+-- +goose Up
+-- Interest plans by account type. Each tier pays annual_rate on the part of
+-- the balance from its min_balance up to the min_balance of the next tier.
+CREATE TABLE dbank_interest_plans (
+    pk           SERIAL      PRIMARY KEY,
+    account_type TEXT        NOT NULL UNIQUE,
+    day_count    TEXT        NOT NULL CHECK (day_count IN ('ACT/365', 'ACT/360', '30/360')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE dbank_interest_tiers (
+    pk          SERIAL        PRIMARY KEY,
+    plan_pk     INT           NOT NULL REFERENCES dbank_interest_plans(pk) ON DELETE CASCADE,
+    min_balance DECIMAL(20,6) NOT NULL CHECK (min_balance >= 0),
+    annual_rate DECIMAL(10,6) NOT NULL CHECK (annual_rate >= 0),
+    UNIQUE (plan_pk, min_balance)
+);
+
+-- Monthly postings of the accrued interest. accrued includes the carry of the
+-- previous posting, amount is what was paid and carry the fraction of a cent left.
+CREATE TABLE dbank_interest_postings (
+    pk             SERIAL        PRIMARY KEY,
+    id             UUID          NOT NULL UNIQUE,
+    account_pk     INT           NOT NULL REFERENCES dbank_accounts(pk),
+    period_end     DATE          NOT NULL,
+    accrued        DECIMAL(20,10) NOT NULL,
+    amount         DECIMAL(20,6) NOT NULL,
+    carry          DECIMAL(20,10) NOT NULL,
+    transaction_id UUID          REFERENCES dbank_transactions(id),
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    UNIQUE (account_pk, period_end)
+);
+
+-- Daily accruals on end-of-day balances, kept with the tiers they were
+-- computed with so that they can be audited
+CREATE TABLE dbank_interest_accruals (
+    pk           SERIAL         PRIMARY KEY,
+    id           UUID           NOT NULL UNIQUE,
+    account_pk   INT            NOT NULL REFERENCES dbank_accounts(pk),
+    accrual_date DATE           NOT NULL,
+    balance      DECIMAL(20,6)  NOT NULL,
+    currency     TEXT           NOT NULL,
+    day_count    TEXT           NOT NULL,
+    day_fraction DECIMAL(12,10) NOT NULL,
+    tiers        JSONB          NOT NULL,
+    amount       DECIMAL(20,10) NOT NULL,
+    posting_pk   INT            REFERENCES dbank_interest_postings(pk),
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    UNIQUE (account_pk, accrual_date)
+);
+CREATE INDEX idx_dbank_interest_accruals_unposted ON dbank_interest_accruals(account_pk) WHERE posting_pk IS NULL;
+
+-- Dates the interest job has completed
+CREATE TABLE dbank_interest_runs (
+    run_date     DATE        PRIMARY KEY,
+    accruals     INT         NOT NULL,
+    postings     INT         NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_interest_runs;
+DROP TABLE IF EXISTS dbank_interest_accruals;
+DROP TABLE IF EXISTS dbank_interest_postings;
+DROP TABLE IF EXISTS dbank_interest_tiers;
+DROP TABLE IF EXISTS dbank_interest_plans;
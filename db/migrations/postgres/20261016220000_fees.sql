@@ -0,0 +1,30 @@
+-- +goose Up
+-- Fees charged to the sender of a transaction, by transaction type, account
+-- type of the sending account ('' for any) and currency
+CREATE TABLE dbank_fee_schedules (
+    pk               SERIAL        PRIMARY KEY,
+    id               UUID          NOT NULL UNIQUE,
+    transaction_type TEXT          NOT NULL,
+    account_type     TEXT          NOT NULL DEFAULT '',
+    currency         TEXT          NOT NULL,
+    fee_type         TEXT          NOT NULL CHECK (fee_type IN ('flat', 'percentage', 'tiered')),
+    flat_amount      DECIMAL(20,6) NOT NULL DEFAULT 0 CHECK (flat_amount >= 0),
+    rate             DECIMAL(10,6) NOT NULL DEFAULT 0 CHECK (rate >= 0),
+    min_fee          DECIMAL(20,6) NOT NULL DEFAULT 0 CHECK (min_fee >= 0),
+    max_fee          DECIMAL(20,6) NOT NULL DEFAULT 0 CHECK (max_fee >= 0),
+    tiers            JSONB         NOT NULL DEFAULT '[]',
+    created_at       TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    UNIQUE (transaction_type, account_type, currency)
+);
+
+-- The fee is posted with the transaction, from the sender to the fee income account
+ALTER TABLE dbank_transactions
+    ADD COLUMN fee_amount      DECIMAL(20,6) NOT NULL DEFAULT 0,
+    ADD COLUMN fee_schedule_id UUID          REFERENCES dbank_fee_schedules(id);
+
+-- +goose Down
+ALTER TABLE dbank_transactions
+    DROP COLUMN IF EXISTS fee_schedule_id,
+    DROP COLUMN IF EXISTS fee_amount;
+DROP TABLE IF EXISTS dbank_fee_schedules;
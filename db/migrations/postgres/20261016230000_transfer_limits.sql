@@ -0,0 +1,25 @@
+-- +goose Up
+-- Know-your-customer tier of a user, limits can be set per tier
+ALTER TABLE dbank_users ADD COLUMN kyc_tier TEXT NOT NULL DEFAULT 'basic';
+
+-- Limits on the outgoing transfers of customer accounts in a currency.
+-- scope_value is the account id, account type or KYC tier the limit applies to
+-- and a NULL limit is left to the less specific scopes.
+CREATE TABLE dbank_transfer_limits (
+    pk                  SERIAL        PRIMARY KEY,
+    id                  UUID          NOT NULL UNIQUE,
+    scope               TEXT          NOT NULL CHECK (scope IN ('account', 'account_type', 'kyc_tier')),
+    scope_value         TEXT          NOT NULL,
+    currency            TEXT          NOT NULL,
+    per_transaction_max DECIMAL(20,6) CHECK (per_transaction_max >= 0),
+    daily_amount        DECIMAL(20,6) CHECK (daily_amount >= 0),
+    monthly_amount      DECIMAL(20,6) CHECK (monthly_amount >= 0),
+    daily_count         INT           CHECK (daily_count >= 0),
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    UNIQUE (scope, scope_value, currency)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_transfer_limits;
+ALTER TABLE dbank_users DROP COLUMN IF EXISTS kyc_tier;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Decision of every risk rule evaluated on a transfer, also for transfers that
+-- were denied and never stored
+CREATE TABLE dbank_risk_decisions (
+    pk              SERIAL       PRIMARY KEY,
+    transaction_id  UUID         NOT NULL,
+    from_account_id UUID         NOT NULL,
+    rule            TEXT         NOT NULL,
+    decision        TEXT         NOT NULL CHECK (decision IN ('allow', 'review', 'deny')),
+    reason          TEXT         NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_dbank_risk_decisions_transaction_id ON dbank_risk_decisions(transaction_id);
+
+-- Transfers in pending_review status are stored without postings, request is
+-- the transfer that is posted when an operator approves it
+CREATE TABLE dbank_transaction_reviews (
+    pk             SERIAL       PRIMARY KEY,
+    transaction_pk INT          NOT NULL UNIQUE REFERENCES dbank_transactions(pk),
+    request        JSONB        NOT NULL,
+    status         TEXT         NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reason         TEXT         NOT NULL DEFAULT '',
+    actor          TEXT         NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    resolved_at    TIMESTAMPTZ
+);
+CREATE INDEX idx_dbank_transaction_reviews_pending ON dbank_transaction_reviews(created_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_transaction_reviews;
+DROP TABLE IF EXISTS dbank_risk_decisions;
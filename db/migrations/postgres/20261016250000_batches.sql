@@ -0,0 +1,42 @@
+-- +goose Up
+-- Uploaded payment files, processed by the batch worker
+CREATE TABLE dbank_batches (
+    pk              SERIAL        PRIMARY KEY,
+    id              UUID          NOT NULL UNIQUE,
+    from_account_id UUID          NOT NULL,
+    mode            TEXT          NOT NULL CHECK (mode IN ('all_or_nothing', 'best_effort')),
+    format          TEXT          NOT NULL,
+    file_name       TEXT          NOT NULL DEFAULT '',
+    status          TEXT          NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    total_rows      INT           NOT NULL,
+    succeeded       INT           NOT NULL DEFAULT 0,
+    in_review       INT           NOT NULL DEFAULT 0,
+    failed          INT           NOT NULL DEFAULT 0,
+    error           TEXT          NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    claimed_at      TIMESTAMPTZ,
+    completed_at    TIMESTAMPTZ
+);
+CREATE INDEX idx_dbank_batches_unfinished ON dbank_batches(pk) WHERE status IN ('pending', 'processing');
+
+-- Payments of a batch as written in the file and the result of their transfer
+CREATE TABLE dbank_batch_rows (
+    pk             SERIAL       PRIMARY KEY,
+    batch_pk       INT          NOT NULL REFERENCES dbank_batches(pk) ON DELETE CASCADE,
+    row_number     INT          NOT NULL,
+    to_account_id  TEXT         NOT NULL,
+    amount         TEXT         NOT NULL,
+    currency       TEXT         NOT NULL,
+    description    TEXT         NOT NULL DEFAULT '',
+    reference      TEXT         NOT NULL DEFAULT '',
+    status         TEXT         NOT NULL,
+    transaction_id UUID,
+    error          TEXT         NOT NULL DEFAULT '',
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    UNIQUE (batch_pk, row_number)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dbank_batch_rows;
+DROP TABLE IF EXISTS dbank_batches;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Accounts move through a fixed set of states, changes are recorded in
+-- dbank_audit_logs. Statuses outside the set were free text and become active.
+UPDATE dbank_accounts
+   SET status = 'active'
+ WHERE status NOT IN ('pending', 'active', 'frozen', 'debit_blocked', 'dormant', 'closed');
+
+ALTER TABLE dbank_accounts
+    ADD CONSTRAINT dbank_accounts_status_check
+    CHECK (status IN ('pending', 'active', 'frozen', 'debit_blocked', 'dormant', 'closed'));
+
+-- +goose Down
+ALTER TABLE dbank_accounts DROP CONSTRAINT dbank_accounts_status_check;
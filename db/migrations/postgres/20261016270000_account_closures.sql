@@ -0,0 +1,7 @@
+-- +goose Up
+-- Accounts are closed instead of deleted, so that closed accounts and their
+-- history stay readable
+ALTER TABLE dbank_accounts ADD COLUMN closed_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE dbank_accounts DROP COLUMN closed_at;
@@ -11,11 +11,85 @@ type TransactionEvent struct {
 	Status          string `json:"status"`
 	Description     string `json:"description,omitempty"`
 	Timestamp       int64  `json:"timestamp"`
+
+	// Postings are the ledger entries written to Postgres for the transaction
+	Postings []LedgerPosting `json:"postings,omitempty"`
+
+	// ReversalOf is the id of the transaction compensated by a reversal
+	ReversalOf string `json:"reversal_of,omitempty"`
+
+	// ToAmount and ToCurrency are set when the amount was converted
+	// before being credited to the receiving account
+	ToAmount   string `json:"to_amount,omitempty"`
+	ToCurrency string `json:"to_currency,omitempty"`
+
+	// Fee is charged to the sender on top of Amount, its postings are
+	// part of Postings
+	Fee string `json:"fee,omitempty"`
+}
+
+// LedgerPosting is a single debit or credit of a transaction.
+// Amount is signed and Balance is the account balance after the posting.
+type LedgerPosting struct {
+	ID        string `json:"id"`
+	AccountID string `json:"account_id"`
+	EntryType string `json:"entry_type"`
+	Amount    string `json:"amount"`
+	Balance   string `json:"balance"`
+	Currency  string `json:"currency"`
+}
+
+// AccountOverdrawnEvent is published when a transaction takes the balance
+// of an account below zero, into its arranged overdraft
+type AccountOverdrawnEvent struct {
+	AccountID     string `json:"account_id"`
+	TransactionID string `json:"transaction_id"`
+	Balance       string `json:"balance"`
+	Currency      string `json:"currency"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// AccountClosedEvent is published when an account is closed. Postings are
+// the ledger entries of the sweep of its remaining balance, if it had one.
+type AccountClosedEvent struct {
+	AccountID          string          `json:"account_id"`
+	PreviousStatus     string          `json:"previous_status"`
+	Reason             string          `json:"reason"`
+	Actor              string          `json:"actor,omitempty"`
+	SweepTransactionID string          `json:"sweep_transaction_id,omitempty"`
+	SweptAmount        string          `json:"swept_amount,omitempty"`
+	SweptToAccountID   string          `json:"swept_to_account_id,omitempty"`
+	Currency           string          `json:"currency,omitempty"`
+	Postings           []LedgerPosting `json:"postings,omitempty"`
+	Timestamp          int64           `json:"timestamp"`
+}
+
+// BatchEvent summarizes a batch of transfers once the batch worker has
+// processed all of its rows
+type BatchEvent struct {
+	BatchID       string `json:"batch_id"`
+	FromAccountID string `json:"from_account_id"`
+	Mode          string `json:"mode"`
+	Status        string `json:"status"`
+	TotalRows     int    `json:"total_rows"`
+	Succeeded     int    `json:"succeeded"`
+	InReview      int    `json:"in_review"`
+	Failed        int    `json:"failed"`
+	Error         string `json:"error,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
 }
 
 // Constants for AMQP exchanges and routing keys
 const (
-	TransactionExchange     = "transactions"
-	TransactionSuccessRoute = "transaction.success"
-	TransactionFailureRoute = "transaction.failure"
+	TransactionExchange      = "transactions"
+	TransactionSuccessRoute  = "transaction.success"
+	TransactionFailureRoute  = "transaction.failure"
+	TransactionReversedRoute = "transaction.reversed"
+
+	AccountExchange       = "accounts"
+	AccountOverdrawnRoute = "account.overdrawn"
+	AccountClosedRoute    = "account.closed"
+
+	BatchExchange      = "batches"
+	BatchFinishedRoute = "batch.finished"
 )
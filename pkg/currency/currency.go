@@ -0,0 +1,137 @@
+// Package currency is a registry of the ISO 4217 currencies, their minor
+// units and how amounts in them are rounded.
+package currency
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rounding modes of a currency
+const (
+	RoundHalfUp   = "half_up"
+	RoundHalfEven = "half_even"
+	RoundDown     = "down"
+)
+
+// DefaultMinorUnits are the minor units assumed for a code that is not in
+// the registry, such as the currency of an account opened before it existed
+const DefaultMinorUnits = 2
+
+// Currency is an ISO 4217 currency. MinorUnits is the number of decimal
+// places of its amounts and Rounding how amounts computed with more places,
+// such as fees, are rounded to them.
+type Currency struct {
+	Code       string
+	Numeric    string
+	MinorUnits int32
+	Rounding   string
+}
+
+// currenciesCSV is the registry: the active ISO 4217 currencies with minor
+// units, without funds, precious metals and testing codes
+//
+//go:embed currencies.csv
+var currenciesCSV string
+
+var registry = mustParse(currenciesCSV)
+
+// mustParse reads the embedded registry, a broken registry is a build error
+func mustParse(data string) map[string]*Currency {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("currency: failed to read registry: %v", err))
+	}
+
+	currencies := make(map[string]*Currency, len(records))
+	for _, record := range records[1:] {
+		minorUnits, err := strconv.ParseInt(record[2], 10, 32)
+		if err != nil {
+			panic(fmt.Sprintf("currency: invalid minor units of %s: %v", record[0], err))
+		}
+
+		switch record[3] {
+		case RoundHalfUp, RoundHalfEven, RoundDown:
+		default:
+			panic(fmt.Sprintf("currency: invalid rounding of %s: %q", record[0], record[3]))
+		}
+
+		currencies[record[0]] = &Currency{
+			Code:       record[0],
+			Numeric:    record[1],
+			MinorUnits: int32(minorUnits),
+			Rounding:   record[3],
+		}
+	}
+
+	return currencies
+}
+
+// Lookup returns the currency of an ISO 4217 alphabetic code, codes are
+// upper case
+func Lookup(code string) (*Currency, bool) {
+	currency, ok := registry[code]
+	return currency, ok
+}
+
+// Get returns the currency of a code, or a currency with the default minor
+// units and rounding for a code that is not in the registry
+func Get(code string) *Currency {
+	if currency, ok := registry[code]; ok {
+		return currency
+	}
+	return &Currency{Code: code, MinorUnits: DefaultMinorUnits, Rounding: RoundHalfUp}
+}
+
+// Fits reports whether an amount has no more decimal places than the minor
+// units of the currency
+func (c *Currency) Fits(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(c.MinorUnits))
+}
+
+// Round rounds an amount to the minor units with the rounding of the currency
+func (c *Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	switch c.Rounding {
+	case RoundHalfEven:
+		return amount.RoundBank(c.MinorUnits)
+	case RoundDown:
+		return amount.Truncate(c.MinorUnits)
+	default:
+		return amount.Round(c.MinorUnits)
+	}
+}
+
+// Truncate rounds an amount down to the minor units, for the amounts the
+// bank pays out of a computation and never rounds up
+func (c *Currency) Truncate(amount decimal.Decimal) decimal.Decimal {
+	return amount.Truncate(c.MinorUnits)
+}
+
+// Format writes an amount with exactly the minor units of the currency, or
+// with all of its places when it has more
+func (c *Currency) Format(amount decimal.Decimal) string {
+	if !c.Fits(amount) {
+		return amount.String()
+	}
+	return amount.StringFixed(c.MinorUnits)
+}
+
+// Validate checks that a code is in the registry and that an amount fits
+// its minor units
+func Validate(code string, amount decimal.Decimal) error {
+	currency, ok := Lookup(code)
+	if !ok {
+		return fmt.Errorf("unknown currency %q", code)
+	}
+
+	if !currency.Fits(amount) {
+		return fmt.Errorf("%s amounts have at most %d decimal places", code, currency.MinorUnits)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code       string
+		minorUnits int32
+		ok         bool
+	}{
+		{"USD", 2, true},
+		{"EUR", 2, true},
+		{"JPY", 0, true},
+		{"KWD", 3, true},
+		{"CLF", 4, true},
+		{"usd", 0, false},
+		{"XAU", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			currency, ok := Lookup(tt.code)
+			if ok != tt.ok {
+				t.Fatalf("Lookup(%q) ok = %v, want %v", tt.code, ok, tt.ok)
+			}
+			if ok && currency.MinorUnits != tt.minorUnits {
+				t.Errorf("Lookup(%q) minor units = %d, want %d", tt.code, currency.MinorUnits, tt.minorUnits)
+			}
+		})
+	}
+
+	if got := Get("ABC").MinorUnits; got != DefaultMinorUnits {
+		t.Errorf("Get() of an unknown code minor units = %d, want %d", got, DefaultMinorUnits)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		code    string
+		amount  string
+		wantErr bool
+	}{
+		{"USD", "10.25", false},
+		{"USD", "10.250", false},
+		{"USD", "10.255", true},
+		{"JPY", "1000", false},
+		{"JPY", "1000.5", true},
+		{"BHD", "1.125", false},
+		{"BHD", "1.1255", true},
+		{"XYZ", "1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code+" "+tt.amount, func(t *testing.T) {
+			err := Validate(tt.code, decimal.RequireFromString(tt.amount))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q, %s) error = %v, wantErr %v", tt.code, tt.amount, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCurrency_Round(t *testing.T) {
+	tests := []struct {
+		currency *Currency
+		amount   string
+		round    string
+		truncate string
+		format   string
+	}{
+		{&Currency{MinorUnits: 2, Rounding: RoundHalfUp}, "2.345", "2.35", "2.34", "2.345"},
+		{&Currency{MinorUnits: 2, Rounding: RoundHalfEven}, "2.345", "2.34", "2.34", "2.345"},
+		{&Currency{MinorUnits: 2, Rounding: RoundDown}, "2.349", "2.34", "2.34", "2.349"},
+		{&Currency{MinorUnits: 0, Rounding: RoundHalfUp}, "150.5", "151", "150", "150.5"},
+		{&Currency{MinorUnits: 2, Rounding: RoundHalfUp}, "7", "7", "7", "7.00"},
+		{&Currency{MinorUnits: 3, Rounding: RoundHalfUp}, "1.1", "1.1", "1.1", "1.100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency.Rounding+" "+tt.amount, func(t *testing.T) {
+			amount := decimal.RequireFromString(tt.amount)
+			if got := tt.currency.Round(amount); !got.Equal(decimal.RequireFromString(tt.round)) {
+				t.Errorf("Round(%s) = %s, want %s", tt.amount, got, tt.round)
+			}
+			if got := tt.currency.Truncate(amount); !got.Equal(decimal.RequireFromString(tt.truncate)) {
+				t.Errorf("Truncate(%s) = %s, want %s", tt.amount, got, tt.truncate)
+			}
+			if got := tt.currency.Format(amount); got != tt.format {
+				t.Errorf("Format(%s) = %s, want %s", tt.amount, got, tt.format)
+			}
+		})
+	}
+}
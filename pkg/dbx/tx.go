@@ -22,7 +22,18 @@ func RunInTx(
 	pg *Postgres,
 	fn TxFn,
 ) error {
-	tx, err := pg.Pool.Begin(ctx)
+	return RunInTxWithOptions(ctx, logger, pg, pgx.TxOptions{}, fn)
+}
+
+// RunInTxWithOptions is RunInTx with explicit isolation level and access mode
+func RunInTxWithOptions(
+	ctx context.Context,
+	logger *slog.Logger,
+	pg *Postgres,
+	opts pgx.TxOptions,
+	fn TxFn,
+) error {
+	tx, err := pg.Pool.BeginTx(ctx, opts)
 	if err != nil {
 		return errors.Join(ErrFailedToBeginTx, err)
 	}